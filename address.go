@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/metadata"
+)
+
+// vietnamese phone number: 0 or +84 followed by 9 digits, the first one not 0
+var phoneRegexp = regexp.MustCompile(`^(0|\+84)[1-9][0-9]{8}$`)
+
+// validateAddress trims the fields of an address, normalizes the phone number
+// and checks them against the limits of the address tables.
+func validateAddress(name, phone, detail string) (repository.CreateAddressParams, error) {
+	name = strings.TrimSpace(name)
+	detail = strings.TrimSpace(detail)
+	phone = strings.NewReplacer(" ", "", ".", "", "-", "").Replace(phone)
+
+	switch {
+	case name == "":
+		return repository.CreateAddressParams{}, errors.New("Vui lòng nhập tên người nhận")
+	case utf8.RuneCountInString(name) > 256:
+		return repository.CreateAddressParams{}, errors.New("Tên người nhận không được quá 256 ký tự")
+	case phone == "":
+		return repository.CreateAddressParams{}, errors.New("Vui lòng nhập số điện thoại")
+	case !phoneRegexp.MatchString(phone):
+		return repository.CreateAddressParams{}, errors.New("Số điện thoại không hợp lệ")
+	case detail == "":
+		return repository.CreateAddressParams{}, errors.New("Vui lòng nhập địa chỉ")
+	case utf8.RuneCountInString(detail) > 64:
+		return repository.CreateAddressParams{}, errors.New("Địa chỉ không được quá 64 ký tự")
+	}
+
+	return repository.CreateAddressParams{
+		Name:   name,
+		Phone:  phone,
+		Detail: detail,
+	}, nil
+}
+
+func (srv orderService) CreateCustomerAddress(ctx context.Context, req *pb.CreateCustomerAddressRequest) (*pb.CustomerAddress, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	customerID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	addr, err := validateAddress(req.GetName(), req.GetPhone(), req.GetDetail())
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := srv.createCustomerAddress(ctx, customerID, addr, req.GetIsDefault())
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Thêm địa chỉ không thành công")
+	}
+
+	return toPbCustomerAddress(address), nil
+}
+
+func (srv orderService) UpdateCustomerAddress(ctx context.Context, req *pb.UpdateCustomerAddressRequest) (*pb.CustomerAddress, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	customerID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	addr, err := validateAddress(req.GetName(), req.GetPhone(), req.GetDetail())
+	if err != nil {
+		return nil, err
+	}
+
+	var address repository.CustomerAddress
+	err = srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		// only one default address per customer
+		if req.GetIsDefault() {
+			if err := qtx.ClearDefaultCustomerAddress(ctx, customerID); err != nil {
+				return err
+			}
+		}
+		address, err = qtx.UpdateCustomerAddress(ctx, repository.UpdateCustomerAddressParams{
+			ID:         req.GetAddressId(),
+			CustomerID: customerID,
+			Name:       addr.Name,
+			Phone:      addr.Phone,
+			Detail:     addr.Detail,
+			IsDefault:  req.GetIsDefault(),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("Không tìm thấy địa chỉ")
+		}
+		if err != nil {
+			log.Println(err)
+			return errors.New("Cập nhật địa chỉ không thành công")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toPbCustomerAddress(address), nil
+}
+
+func (srv orderService) DeleteCustomerAddress(ctx context.Context, req *pb.DeleteCustomerAddressRequest) (*pb.DeleteCustomerAddressResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	customerID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	// orders keep their own copy of the address, deleting it from the book is safe
+	_, err = srv.orderStore.DeleteCustomerAddress(ctx, repository.DeleteCustomerAddressParams{
+		ID:         req.GetAddressId(),
+		CustomerID: customerID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("Không tìm thấy địa chỉ")
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Xóa địa chỉ không thành công")
+	}
+
+	return &pb.DeleteCustomerAddressResponse{
+		Message: "Xóa địa chỉ thành công",
+	}, nil
+}
+
+func (srv orderService) ListCustomerAddress(ctx context.Context, _ *empty.Empty) (*pb.ListCustomerAddressResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	customerID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	listAddress, err := srv.orderStore.GetCustomerAddressByCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*pb.CustomerAddress, 0, len(listAddress))
+	for _, address := range listAddress {
+		result = append(result, toPbCustomerAddress(address))
+	}
+
+	return &pb.ListCustomerAddressResponse{
+		ListAddress: result,
+	}, nil
+}
+
+// createCustomerAddress saves an address in the customer's book, the first
+// address of a customer always becomes the default one.
+func (srv orderService) createCustomerAddress(ctx context.Context, customerID int64, addr repository.CreateAddressParams, isDefault bool) (repository.CustomerAddress, error) {
+	var address repository.CustomerAddress
+	err := srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		n, err := qtx.CountCustomerAddress(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			isDefault = true
+		}
+		if isDefault {
+			if err := qtx.ClearDefaultCustomerAddress(ctx, customerID); err != nil {
+				return err
+			}
+		}
+		address, err = qtx.CreateCustomerAddress(ctx, repository.CreateCustomerAddressParams{
+			CustomerID: customerID,
+			Name:       addr.Name,
+			Phone:      addr.Phone,
+			Detail:     addr.Detail,
+			IsDefault:  isDefault,
+		})
+		return err
+	})
+	return address, err
+}
+
+func toPbCustomerAddress(address repository.CustomerAddress) *pb.CustomerAddress {
+	return &pb.CustomerAddress{
+		AddressId: address.ID,
+		Name:      address.Name,
+		Phone:     address.Phone,
+		Detail:    address.Detail,
+		IsDefault: address.IsDefault,
+	}
+}
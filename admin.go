@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// the actions of admins written to order_audit
+const (
+	auditActionCancel    = "cancel"
+	auditActionSetStatus = "set_status"
+	auditActionHold      = "hold"
+	auditActionRelease   = "release"
+	// the status changes recorded by the order_record_status trigger
+	auditActionStatus = "status"
+)
+
+// forceCancelOrder cancels a waiting or handled order for an admin in q's
+// transaction, even a held one, gives its payment and coupon back and writes
+// the audit. The caller calls finishCancel once it is committed.
+func (srv orderService) forceCancelOrder(ctx context.Context, q repository.Querier, adminID int64, id int64, reason string, action string) (repository.Order, error) {
+	// locked first, so the audit has the status the order is cancelled from
+	order, err := q.GetOrderByIDForUpdate(ctx, id)
+	if err != nil {
+		log.Println(err)
+		return order, errors.New("Không tìm thấy đơn hàng")
+	}
+	cancelled, err := q.ForceCancelOrder(ctx, repository.ForceCancelOrderParams{
+		ID:           order.ID,
+		CancelReason: reason,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return cancelled, errors.New("Hủy đơn hàng không thành công, đơn hàng đã được giao hoặc đã bị hủy")
+	}
+	if err != nil {
+		log.Println(err)
+		return cancelled, errors.New("Hủy đơn hàng không thành công")
+	}
+	if err := checkOrderCancellable(ctx, q, cancelled); err != nil {
+		return cancelled, err
+	}
+	_, err = q.CreateOrderAudit(ctx, repository.CreateOrderAuditParams{
+		OrderID:   cancelled.ID,
+		AdminID:   adminID,
+		Action:    action,
+		Reason:    reason,
+		OldStatus: order.Status,
+		NewStatus: cancelled.Status,
+	})
+	if err != nil {
+		log.Println(err)
+		return cancelled, errors.New("Hủy đơn hàng không thành công")
+	}
+
+	if err := srv.givePaymentBack(ctx, q, cancelled); err != nil {
+		log.Println(err)
+		return cancelled, errors.New("Hủy đơn hàng không thành công, không thể hoàn tiền")
+	}
+	if err := releaseOrderCoupon(ctx, q, cancelled); err != nil {
+		log.Println(err)
+		return cancelled, errors.New("Hủy đơn hàng không thành công")
+	}
+	return cancelled, nil
+}
+
+// adminID returns the id of the caller, who must be an admin.
+func (srv orderService) adminID(ctx context.Context) (int64, error) {
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return 0, err
+	}
+	if claims.GetUserRole() != pb.UserRole_admin {
+		return 0, errors.New("Unauthorization")
+	}
+	return strconv.ParseInt(claims.GetId(), 10, 64)
+}
+
+// AdminCancelOrder cancels a waiting or handled order whoever it belongs to,
+// even when it is held, the inventory and the payment are given back like in
+// DeleteOrder. An order with a shipment or a return can't be cancelled.
+func (srv orderService) AdminCancelOrder(ctx context.Context, req *pb.AdminOrderActionRequest) (*pb.Order, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	adminID, err := srv.adminID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reason := strings.TrimSpace(req.GetReason())
+	if reason == "" {
+		return nil, errors.New("Vui lòng nhập lý do")
+	}
+
+	var order repository.Order
+	err = srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		order, err = srv.forceCancelOrder(ctx, qtx, adminID, req.GetOrderId(), reason, auditActionCancel)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	srv.finishCancel(ctx, order)
+
+	return srv.getPbOrder(ctx, order), nil
+}
+
+// AdminHoldOrder puts a waiting or handled order on hold, its supplier can't
+// handle or ship it, its customer can't cancel it and it isn't cancelled when
+// it gets stale, until an admin releases it.
+func (srv orderService) AdminHoldOrder(ctx context.Context, req *pb.AdminOrderActionRequest) (*pb.Order, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	adminID, err := srv.adminID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reason := strings.TrimSpace(req.GetReason())
+	if reason == "" {
+		return nil, errors.New("Vui lòng nhập lý do")
+	}
+
+	var order repository.Order
+	err = srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		order, err = qtx.HoldOrder(ctx, repository.HoldOrderParams{
+			ID:         req.GetOrderId(),
+			HoldReason: reason,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("Tạm giữ đơn hàng không thành công, đơn hàng đã được gửi đi, đã kết thúc hoặc đang bị tạm giữ")
+		}
+		if err != nil {
+			log.Println(err)
+			return errors.New("Tạm giữ đơn hàng không thành công")
+		}
+		_, err = qtx.CreateOrderAudit(ctx, repository.CreateOrderAuditParams{
+			OrderID: order.ID,
+			AdminID: adminID,
+			Action:  auditActionHold,
+			Reason:  reason,
+		})
+		if err != nil {
+			log.Println(err)
+			return errors.New("Tạm giữ đơn hàng không thành công")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return srv.getPbOrder(ctx, order), nil
+}
+
+func (srv orderService) AdminReleaseOrder(ctx context.Context, req *pb.AdminOrderActionRequest) (*pb.Order, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	adminID, err := srv.adminID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reason := strings.TrimSpace(req.GetReason())
+	if reason == "" {
+		return nil, errors.New("Vui lòng nhập lý do")
+	}
+
+	var order repository.Order
+	err = srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		order, err = qtx.ReleaseOrder(ctx, req.GetOrderId())
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("Đơn hàng không bị tạm giữ")
+		}
+		if err != nil {
+			log.Println(err)
+			return errors.New("Bỏ tạm giữ đơn hàng không thành công")
+		}
+		_, err = qtx.CreateOrderAudit(ctx, repository.CreateOrderAuditParams{
+			OrderID: order.ID,
+			AdminID: adminID,
+			Action:  auditActionRelease,
+			Reason:  reason,
+		})
+		if err != nil {
+			log.Println(err)
+			return errors.New("Bỏ tạm giữ đơn hàng không thành công")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return srv.getPbOrder(ctx, order), nil
+}
+
+// GetOrderHistory returns every status the order went through and every
+// action of an admin on it, oldest first.
+func (srv orderService) GetOrderHistory(ctx context.Context, req *pb.GetOrderHistoryRequest) (*pb.GetOrderHistoryResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	if _, err := srv.adminID(ctx); err != nil {
+		return nil, err
+	}
+
+	order, err := srv.orderStore.GetOrderByID(ctx, req.GetOrderId())
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không tìm thấy đơn hàng")
+	}
+	listStatus, err := srv.orderStore.GetOrderStatusHistoryByOrder(ctx, order.ID)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy lịch sử đơn hàng")
+	}
+	listAudit, err := srv.orderStore.GetOrderAuditByOrder(ctx, order.ID)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy lịch sử đơn hàng")
+	}
+
+	listEvent := make([]*pb.OrderEvent, 0, len(listStatus)+len(listAudit))
+	for _, status := range listStatus {
+		listEvent = append(listEvent, &pb.OrderEvent{
+			CreatedAt: timestamppb.New(status.CreatedAt),
+			Action:    auditActionStatus,
+			OldStatus: toPbOrderStatus(status.OldStatus),
+			NewStatus: toPbOrderStatus(status.NewStatus),
+		})
+	}
+	for _, audit := range listAudit {
+		listEvent = append(listEvent, &pb.OrderEvent{
+			CreatedAt: timestamppb.New(audit.CreatedAt),
+			Action:    audit.Action,
+			AdminId:   audit.AdminID,
+			OldStatus: toPbOrderStatus(audit.OldStatus),
+			NewStatus: toPbOrderStatus(audit.NewStatus),
+			Reason:    audit.Reason,
+		})
+	}
+	// a status change and the admin action causing it have the time of their
+	// transaction, the status comes first
+	sort.SliceStable(listEvent, func(i, j int) bool {
+		return listEvent[i].GetCreatedAt().AsTime().Before(listEvent[j].GetCreatedAt().AsTime())
+	})
+
+	return &pb.GetOrderHistoryResponse{
+		Order:     srv.getPbOrder(ctx, order),
+		ListEvent: listEvent,
+	}, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+// paidOrder is a waiting order of product 1 with an authorized payment and
+// a coupon.
+func paidOrder(t *testing.T, srv orderService, store OrderStore) repository.Order {
+	t.Helper()
+	ctx := context.Background()
+	paymentID, err := srv.payment.Authorize(ctx, 1, 190000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coupon, err := store.CreateCoupon(ctx, repository.CreateCouponParams{
+		Code:       "GIAM10K",
+		Type:       repository.CouponTypeEnumFixed,
+		Value:      10000,
+		UsageLimit: 10,
+		StartsAt:   time.Now().Add(-time.Hour),
+		CreatedBy:  3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.RedeemCoupon(ctx, coupon.ID); err != nil {
+		t.Fatal(err)
+	}
+	redemption, err := store.CreateCouponRedemption(ctx, repository.CreateCouponRedemptionParams{
+		CouponID:   coupon.ID,
+		CustomerID: 1,
+		Discount:   10000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testOrder(t, store, func(arg *repository.CreateOrderParams) {
+		arg.ProductID = 1
+		arg.Discount = 10000
+		arg.CouponRedemptionID = sql.NullInt64{Int64: redemption.ID, Valid: true}
+		arg.PaymentStatus = repository.PaymentStatusEnumAuthorized
+		arg.PaymentID = paymentID
+	})
+}
+
+func adminSetStatus(srv orderService, order repository.Order, status pb.OrderStatus) error {
+	_, err := srv.UpdateOrder(userContext("admin"), &pb.UpdateOrderStatusRequest{
+		OrderId: order.ID,
+		Status:  status,
+		Reason:  "sửa trạng thái",
+	})
+	return err
+}
+
+func TestUpdateOrderCancel(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+		order := paidOrder(t, srv, store)
+		before := inventoryOf(t, srv, 1)
+
+		if err := adminSetStatus(srv, order, pb.OrderStatus_cancel); err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := store.GetOrderByID(ctx, order.ID)
+		if err != nil || !hasStatus(found.Status, repository.OrderStatusEnumCancel) {
+			t.Fatalf("the order is %+v, %v", found, err)
+		}
+		if found.PaymentStatus != repository.PaymentStatusEnumUnpaid {
+			t.Errorf("the authorized payment is %s, want it voided", found.PaymentStatus)
+		}
+		if got := inventoryOf(t, srv, 1) - before; got != int64(order.Quantity) {
+			t.Errorf("inventory came back %d, want %d", got, order.Quantity)
+		}
+		coupon, err := store.GetCouponByCode(ctx, "GIAM10K")
+		if err != nil || coupon.UsedCount != 0 {
+			t.Errorf("the coupon is %+v, %v, want it released", coupon, err)
+		}
+		listAudit, err := store.GetOrderAuditByOrder(ctx, order.ID)
+		if err != nil || len(listAudit) != 1 || listAudit[0].Action != auditActionSetStatus ||
+			!hasStatus(listAudit[0].OldStatus, repository.OrderStatusEnumWaiting) {
+			t.Errorf("the audit is %+v, %v", listAudit, err)
+		}
+
+		if err := adminSetStatus(srv, order, pb.OrderStatus_waiting); err == nil {
+			t.Fatal("a cancelled order is waiting again")
+		}
+	})
+}
+
+func TestUpdateOrderHandle(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+		order := paidOrder(t, srv, store)
+
+		if err := adminSetStatus(srv, order, pb.OrderStatus_handled); err != nil {
+			t.Fatal(err)
+		}
+		found, err := store.GetOrderByID(ctx, order.ID)
+		if err != nil || !hasStatus(found.Status, repository.OrderStatusEnumHandled) || !found.HandledAt.Valid {
+			t.Fatalf("the order is %+v, %v", found, err)
+		}
+		if found.PaymentStatus != repository.PaymentStatusEnumCaptured {
+			t.Errorf("the payment is %s, want it captured", found.PaymentStatus)
+		}
+
+		if err := adminSetStatus(srv, order, pb.OrderStatus_delivered); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.GetInvoiceByOrder(ctx, order.ID); err != nil {
+			t.Errorf("the delivered order has no invoice: %v", err)
+		}
+	})
+}
+
+func TestUpdateOrderTransitions(t *testing.T) {
+	for _, tc := range []struct {
+		from repository.NullOrderStatusEnum
+		to   repository.OrderStatusEnum
+		want bool
+	}{
+		{orderStatus(repository.OrderStatusEnumWaiting), repository.OrderStatusEnumHandled, true},
+		{orderStatus(repository.OrderStatusEnumWaiting), repository.OrderStatusEnumShipped, false},
+		{orderStatus(repository.OrderStatusEnumWaiting), repository.OrderStatusEnumDelivered, false},
+		{orderStatus(repository.OrderStatusEnumHandled), repository.OrderStatusEnumWaiting, false},
+		{orderStatus(repository.OrderStatusEnumHandled), repository.OrderStatusEnumShipped, true},
+		{orderStatus(repository.OrderStatusEnumHandled), repository.OrderStatusEnumDelivered, true},
+		{orderStatus(repository.OrderStatusEnumShipped), repository.OrderStatusEnumHandled, false},
+		{orderStatus(repository.OrderStatusEnumShipped), repository.OrderStatusEnumDelivered, true},
+		{orderStatus(repository.OrderStatusEnumDelivered), repository.OrderStatusEnumShipped, false},
+		{orderStatus(repository.OrderStatusEnumCancel), repository.OrderStatusEnumWaiting, false},
+		{orderStatus(repository.OrderStatusEnumCancel), repository.OrderStatusEnumHandled, false},
+		{repository.NullOrderStatusEnum{}, repository.OrderStatusEnumWaiting, false},
+	} {
+		if got := canSetOrderStatus(tc.from, tc.to); got != tc.want {
+			t.Errorf("canSetOrderStatus(%v, %s) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestHeldOrder(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+		hold := func(order repository.Order) error {
+			_, err := srv.AdminHoldOrder(userContext("admin"), &pb.AdminOrderActionRequest{OrderId: order.ID, Reason: "kiểm tra"})
+			return err
+		}
+		ship := func(order repository.Order) (*pb.Shipment, error) {
+			return srv.CreateShipment(userContext("supplier"), &pb.CreateShipmentRequest{
+				Carrier:  "ECOM",
+				ListItem: []*pb.ShipmentItem{{OrderId: order.ID, Quantity: 1}},
+			})
+		}
+
+		// a shipped order can't be held
+		shipped := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.ProductID = 1; arg.Quantity = 1 })
+		if _, err := store.HandleOrder(ctx, shipped.ID); err != nil {
+			t.Fatal(err)
+		}
+		shipment, err := ship(shipped)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.UpdateShipmentTracking(userContext("supplier"), &pb.UpdateShipmentTrackingRequest{ShipmentId: shipment.GetShipmentId()}); err != nil {
+			t.Fatal(err)
+		}
+		if err := hold(shipped); err == nil {
+			t.Fatal("a shipped order was held")
+		}
+
+		// a held order gets no new shipment, and its pending shipment isn't sent
+		order := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.ProductID = 1 })
+		if _, err := store.HandleOrder(ctx, order.ID); err != nil {
+			t.Fatal(err)
+		}
+		pending, err := ship(order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := hold(order); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ship(order); err == nil {
+			t.Fatal("a held order was given a shipment")
+		}
+		if _, err := srv.UpdateShipmentTracking(userContext("supplier"), &pb.UpdateShipmentTrackingRequest{ShipmentId: pending.GetShipmentId()}); err == nil {
+			t.Fatal("the shipment of a held order was sent")
+		}
+		if err := adminSetStatus(srv, order, pb.OrderStatus_shipped); err == nil {
+			t.Fatal("a held order was set shipped")
+		}
+
+		if _, err := srv.AdminReleaseOrder(userContext("admin"), &pb.AdminOrderActionRequest{OrderId: order.ID, Reason: "xong"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.UpdateShipmentTracking(userContext("supplier"), &pb.UpdateShipmentTrackingRequest{ShipmentId: pending.GetShipmentId()}); err != nil {
+			t.Fatalf("the shipment of a released order: %v", err)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+func TestNewCarrier(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		dev     bool
+		fake    bool
+		wantErr bool
+	}{
+		{"", true, true, false},
+		{"fake", true, true, false},
+		{"fake", false, true, false},
+		// no carrier, the suppliers enter the tracking numbers
+		{"", false, false, false},
+		{"ghn", false, false, true},
+		{"ghn", true, false, true},
+	} {
+		carrier, err := newCarrier(tc.name, tc.dev)
+		if _, fake := carrier.(*fakeCarrier); fake != tc.fake || (err != nil) != tc.wantErr {
+			t.Errorf("newCarrier(%q, %v) = %T, %v", tc.name, tc.dev, carrier, err)
+		}
+		if !tc.fake && carrier != nil {
+			t.Errorf("newCarrier(%q, %v) = %T, want no carrier", tc.name, tc.dev, carrier)
+		}
+	}
+}
+
+// storeCarrier reads the shipment back from the store while it books it, it
+// can only see a shipment which is committed.
+type storeCarrier struct {
+	store OrderStore
+	err   error
+}
+
+func (c storeCarrier) CreateShipment(ctx context.Context, shipment repository.Shipment, _ []repository.ShipmentItem) (string, error) {
+	if _, err := c.store.GetShipmentByID(ctx, shipment.ID); err != nil {
+		return "", err
+	}
+	if c.err != nil {
+		return "", c.err
+	}
+	return "GHN123", nil
+}
+
+func TestCreateShipmentCarrier(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+		ship := func(carrier CarrierAdapter) *pb.Shipment {
+			t.Helper()
+			srv.carrier = carrier
+			order := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.ProductID = 1 })
+			if _, err := store.HandleOrder(ctx, order.ID); err != nil {
+				t.Fatal(err)
+			}
+			done := make(chan struct{})
+			var shipment *pb.Shipment
+			var err error
+			go func() {
+				defer close(done)
+				shipment, err = srv.CreateShipment(userContext("supplier"), &pb.CreateShipmentRequest{
+					Carrier:  "GHN",
+					ListItem: []*pb.ShipmentItem{{OrderId: order.ID, Quantity: 1}},
+				})
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("the carrier was called inside the transaction of the shipment")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			return shipment
+		}
+
+		if shipment := ship(storeCarrier{store: store}); shipment.GetTrackingNumber() != "GHN123" {
+			t.Errorf("the booked shipment has tracking number %q", shipment.GetTrackingNumber())
+		}
+
+		// a failed booking keeps the shipment, without a tracking number
+		shipment := ship(storeCarrier{store: store, err: errors.New("carrier down")})
+		if shipment.GetTrackingNumber() != "" {
+			t.Errorf("the shipment of a failed booking has tracking number %q", shipment.GetTrackingNumber())
+		}
+		if _, err := store.GetShipmentByID(ctx, shipment.GetShipmentId()); err != nil {
+			t.Errorf("the shipment of a failed booking is lost: %v", err)
+		}
+
+		if shipment := ship(nil); shipment.GetTrackingNumber() != "" {
+			t.Errorf("a shipment without carrier has tracking number %q", shipment.GetTrackingNumber())
+		}
+	})
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	errInvalidOrderLine   = errors.New("đơn hàng có sản phẩm không hợp lệ")
+	errNotEnoughInventory = errors.New("Sản phẩm trong kho không đủ")
+	errPriceChanged       = errors.New("giá sản phẩm đã thay đổi")
+)
+
+// PreviewOrder runs the checks of CreateOrder without reserving or writing
+// anything, so the customer can see availability and totals before paying.
+func (srv orderService) PreviewOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.PreviewOrderResponse, error) {
+	ctx, span := tracer.Start(ctx, "OrderService.Preview")
+	defer span.End()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	customerID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	if len(req.GetListOrder()) == 0 {
+		return nil, errors.New("Vui lòng chọn sản phẩm cần mua")
+	}
+
+	response := &pb.PreviewOrderResponse{
+		CanCheckout: true,
+	}
+
+	// the address may not be filled in yet, only a given one is checked
+	if req.GetAddressId() != 0 || req.Addr != nil {
+		if _, err := srv.resolveOrderAddress(ctx, customerID, req); err != nil {
+			response.CanCheckout = false
+			response.ListWarning = append(response.ListWarning, err.Error())
+		}
+	}
+
+	listLine := newOrderLines(req.GetListOrder())
+	err = srv.resolveOrderLines(ctx, customerID, req.GetListOrder(), listLine)
+	if err != nil && !errors.Is(err, errInvalidOrderLine) {
+		return nil, err
+	}
+
+	for i, v := range req.GetListOrder() {
+		line := listLine[i]
+		preview := &pb.PreviewOrderLine{
+			Line:          line,
+			ExpectedPrice: v.GetExpectedPrice(),
+		}
+		response.ListLine = append(response.ListLine, preview)
+
+		if line.GetError() != "" {
+			response.CanCheckout = false
+			response.ListWarning = append(response.ListWarning, fmt.Sprintf("Sản phẩm %d: %s", line.GetProductId(), line.GetError()))
+			continue
+		}
+
+		inventory, err := srv.checkInventory(ctx, v.GetProductId(), v.GetOrderQuantity())
+		preview.Inventory = inventory
+		preview.Available = err == nil
+		if err != nil {
+			line.Error = err.Error()
+			response.CanCheckout = false
+			response.ListWarning = append(response.ListWarning, fmt.Sprintf("Sản phẩm %d: %s", line.GetProductId(), line.GetError()))
+		}
+
+		if srv.priceChanged(v.GetExpectedPrice(), line.GetProductPrice()) {
+			preview.PriceChanged = true
+			response.ListWarning = append(response.ListWarning, fmt.Sprintf("Giá sản phẩm %d đã thay đổi từ %d thành %d", line.GetProductId(), v.GetExpectedPrice(), line.GetProductPrice()))
+		}
+
+	}
+
+	if req.GetCouponCode() != "" {
+		_, _, err := srv.applyCoupon(ctx, customerID, req.GetCouponCode(), listLine)
+		if err != nil {
+			response.CanCheckout = false
+			response.ListWarning = append(response.ListWarning, err.Error())
+		}
+	}
+
+	totals := checkoutTotals(listLine, srv.shippingFee)
+	response.TotalQuantity = totals.quantity
+	response.Subtotal = totals.subtotal
+	response.Discount = totals.discount
+	response.ShippingFee = totals.shippingFee
+	response.GrandTotal = totals.grandTotal
+
+	return response, nil
+}
+
+type orderTotals struct {
+	quantity    int32
+	subtotal    int64
+	discount    int64
+	shippingFee int64
+	grandTotal  int64
+}
+
+// checkoutTotals charges the shipping fee on the lines of a checkout and adds
+// them up, PreviewOrder and CreateOrder both price a checkout with it. Every
+// supplier ships its own parcel, its first line carries the fee. The lines
+// resolveOrderLines couldn't resolve have no supplier and count for nothing.
+func checkoutTotals(listLine []*pb.OrderLineResult, shippingFee int64) orderTotals {
+	var totals orderTotals
+	listSupplier := make(map[int64]bool)
+	for _, line := range listLine {
+		if line.GetSupplierId() == 0 {
+			continue
+		}
+		line.ShippingFee = 0
+		if !listSupplier[line.GetSupplierId()] {
+			listSupplier[line.GetSupplierId()] = true
+			line.ShippingFee = shippingFee
+		}
+		totals.quantity += line.GetOrderQuantity()
+		totals.subtotal += line.GetTotalPrice()
+		totals.discount += line.GetDiscount()
+		totals.shippingFee += line.GetShippingFee()
+	}
+	totals.grandTotal = totals.subtotal - totals.discount + totals.shippingFee
+	return totals
+}
+
+// resolveOrderAddress returns the snapshot of the address an order is
+// delivered to, either a saved address of the customer or the one typed at
+// checkout.
+func (srv orderService) resolveOrderAddress(ctx context.Context, customerID int64, req *pb.CreateOrderRequest) (repository.CreateAddressParams, error) {
+	var addr repository.CreateAddressParams
+	if req.GetAddressId() != 0 {
+		saved, err := srv.orderStore.GetCustomerAddressByID(ctx, req.GetAddressId())
+		if err != nil || saved.CustomerID != customerID {
+			return addr, errors.New("Địa chỉ không hợp lệ")
+		}
+		addr = repository.CreateAddressParams{
+			Name:   saved.Name,
+			Phone:  saved.Phone,
+			Detail: saved.Detail,
+		}
+	} else {
+		if req.Addr == nil {
+			return addr, errors.New("Địa chỉ không hợp lệ")
+		}
+		var err error
+		addr, err = validateAddress(req.Addr.GetName(), req.Addr.GetPhone(), req.Addr.GetDetail())
+		if err != nil {
+			return addr, err
+		}
+	}
+	addr.CustomerID = sql.NullInt64{Int64: customerID, Valid: true}
+	return addr, nil
+}
+
+// checkInventory returns the stock of a product and errNotEnoughInventory when
+// it can't cover quantity.
+func (srv orderService) checkInventory(ctx context.Context, productID int64, quantity int32) (int64, error) {
+	inventory, err := srv.productClient.GetListProductInventory(ctx, &pb.GetInventoryRequest{
+		ProductId: productID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if inventory.GetCount() < int64(quantity) {
+		return inventory.GetCount(), errNotEnoughInventory
+	}
+	return inventory.GetCount(), nil
+}
+
+// saveCheckout writes the address snapshot, the coupon use and the orders of
+// a checkout in a single transaction, either all of them are saved or none.
+// The line results get the ids and numbers of the new orders.
+func (srv orderService) saveCheckout(ctx context.Context, customerID int64, addr repository.CreateAddressParams, listLine []*pb.OrderLineResult, listPaymentID []string, coupon repository.Coupon, discount int64) (repository.Address, error) {
+	// read committed is enough, the usage limits of the coupon are guarded by
+	// its row lock and the orders are new rows
+	var address repository.Address
+	var listOrder []repository.Order
+	err := srv.orderStore.ExecTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted}, func(qtx repository.Querier) error {
+		var redemptionID sql.NullInt64
+		if coupon.ID != 0 {
+			redemption, err := redeemCoupon(ctx, qtx, customerID, coupon, discount)
+			if err != nil {
+				return err
+			}
+			redemptionID = sql.NullInt64{Int64: redemption.ID, Valid: true}
+		}
+
+		var err error
+		address, err = qtx.CreateAddress(ctx, addr)
+		if err != nil {
+			log.Println(err)
+			return errors.New("Địa chỉ không hợp lệ")
+		}
+
+		listOrder = make([]repository.Order, 0, len(listLine))
+		for i, line := range listLine {
+			paymentStatus := repository.PaymentStatusEnumUnpaid
+			if listPaymentID[i] != "" {
+				paymentStatus = repository.PaymentStatusEnumAuthorized
+			}
+			orderNumber, err := takeOrderNumber(ctx, qtx)
+			if err != nil {
+				log.Println(err)
+				return errors.New("Tạo đơn hàng không thành công")
+			}
+			order, err := qtx.CreateOrder(ctx, repository.CreateOrderParams{
+				CustomerID:         customerID,
+				SupplierID:         line.GetSupplierId(),
+				ProductID:          line.GetProductId(),
+				Quantity:           line.GetOrderQuantity(),
+				AddressID:          address.ID,
+				Price:              line.GetProductPrice(),
+				Discount:           line.GetDiscount(),
+				CouponRedemptionID: redemptionID,
+				PaymentStatus:      paymentStatus,
+				PaymentID:          listPaymentID[i],
+				OrderNumber:        orderNumber,
+				ProductName:        line.GetProductName(),
+				ShippingFee:        line.GetShippingFee(),
+			})
+			if err != nil {
+				log.Println(err)
+				return errors.New("Tạo đơn hàng không thành công")
+			}
+			listOrder = append(listOrder, order)
+		}
+		return nil
+	})
+	if err != nil {
+		return repository.Address{}, err
+	}
+
+	for i, order := range listOrder {
+		listLine[i].OrderId = order.ID
+		listLine[i].OrderNumber = order.OrderNumber
+		listLine[i].Status = toPbOrderStatus(order.Status)
+	}
+	return address, nil
+}
+
+// priceChanged reports whether the price of a product moved away from the one
+// the customer saw by more than the configured tolerance. A zero expected
+// price means the client did not send one.
+func (srv orderService) priceChanged(expected, price int64) bool {
+	if expected == 0 {
+		return false
+	}
+	diff := price - expected
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff > srv.priceTolerance
+}
+
+// checkPriceChanged compares the resolved lines against the prices the
+// customer saw in the cart and returns errPriceChanged with the list of
+// changed prices, so the checkout can be confirmed again.
+func (srv orderService) checkPriceChanged(listOrder []*pb.CreateOrderRequestOrder, listLine []*pb.OrderLineResult) (*pb.PriceChanged, error) {
+	changed := &pb.PriceChanged{}
+	for i, v := range listOrder {
+		line := listLine[i]
+		if !srv.priceChanged(v.GetExpectedPrice(), line.GetProductPrice()) {
+			continue
+		}
+		line.Error = "Giá sản phẩm đã thay đổi"
+		changed.ListLine = append(changed.ListLine, &pb.PriceChangedLine{
+			Index:         line.GetIndex(),
+			ProductId:     line.GetProductId(),
+			ExpectedPrice: v.GetExpectedPrice(),
+			Price:         line.GetProductPrice(),
+		})
+	}
+	if len(changed.ListLine) > 0 {
+		return changed, errPriceChanged
+	}
+	return changed, nil
+}
+
+// newOrderLines creates the result of every line of a checkout, before
+// anything is checked.
+func newOrderLines(listOrder []*pb.CreateOrderRequestOrder) []*pb.OrderLineResult {
+	listLine := make([]*pb.OrderLineResult, len(listOrder))
+	for i, v := range listOrder {
+		listLine[i] = &pb.OrderLineResult{
+			Index:         int32(i),
+			ProductId:     v.GetProductId(),
+			OrderQuantity: v.GetOrderQuantity(),
+		}
+	}
+	return listLine
+}
+
+// resolveOrderLines checks every line of a checkout against product-service
+// and fills in what must not be taken from the client: the supplier, the
+// product name and the unit price. Every invalid line gets its Error set and
+// errInvalidOrderLine is returned, so all the problems can be reported at
+// once.
+func (srv orderService) resolveOrderLines(ctx context.Context, customerID int64, listOrder []*pb.CreateOrderRequestOrder, listLine []*pb.OrderLineResult) error {
+	listID := make([]int64, 0, len(listOrder))
+	for _, v := range listOrder {
+		listID = append(listID, v.GetProductId())
+	}
+	resp, err := srv.productClient.GetListProductByIDs(ctx, &pb.GetListProductByIDsRequest{
+		ListId: listID,
+	})
+	if err != nil {
+		log.Println("can't get list product: ", err)
+		return errors.New("không thể lấy thông tin sản phẩm")
+	}
+	m := make(map[int64]*pb.Product)
+	for _, v := range resp.GetListProduct() {
+		m[v.ProductId] = v
+	}
+
+	valid := true
+	for i, v := range listOrder {
+		line := listLine[i]
+		product, ok := m[v.GetProductId()]
+		switch {
+		case v.GetOrderQuantity() <= 0:
+			line.Error = "Số lượng sản phẩm không hợp lệ"
+		case v.GetCustomerId() != 0 && v.GetCustomerId() != customerID:
+			line.Error = "Khách hàng không hợp lệ"
+		case !ok:
+			line.Error = "Sản phẩm không tồn tại"
+		case v.GetSupplierId() != 0 && v.GetSupplierId() != product.GetSupplierId():
+			line.Error = "Cửa hàng không hợp lệ"
+		default:
+			line.SupplierId = product.GetSupplierId()
+			line.ProductName = product.GetName()
+			line.ProductPrice = product.GetPrice()
+			line.TotalPrice = product.GetPrice() * int64(v.GetOrderQuantity())
+			continue
+		}
+		valid = false
+	}
+	if !valid {
+		return errInvalidOrderLine
+	}
+	return nil
+}
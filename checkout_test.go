@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+)
+
+func TestCheckoutTotals(t *testing.T) {
+	listLine := []*pb.OrderLineResult{
+		{SupplierId: 2, OrderQuantity: 2, TotalPrice: 300000, Discount: 20000},
+		{SupplierId: 3, OrderQuantity: 1, TotalPrice: 80000},
+		// unresolved, it has no supplier
+		{OrderQuantity: 5, TotalPrice: 0, Error: "Sản phẩm không tồn tại"},
+		{SupplierId: 2, OrderQuantity: 1, TotalPrice: 350000, Discount: 10000},
+	}
+	totals := checkoutTotals(listLine, 30000)
+	want := orderTotals{
+		quantity:    4,
+		subtotal:    730000,
+		discount:    30000,
+		shippingFee: 60000,
+		grandTotal:  760000,
+	}
+	if totals != want {
+		t.Fatalf("checkoutTotals = %+v, want %+v", totals, want)
+	}
+	for i, fee := range []int64{30000, 30000, 0, 0} {
+		if listLine[i].GetShippingFee() != fee {
+			t.Errorf("line %d has shipping fee %d, want %d", i, listLine[i].GetShippingFee(), fee)
+		}
+	}
+
+	// the fees are the same when the lines are priced again
+	if again := checkoutTotals(listLine, 30000); again != want {
+		t.Errorf("checkoutTotals again = %+v, want %+v", again, want)
+	}
+}
+
+func TestCheckoutChargesPreviewTotal(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+		srv.shippingFee = 30000
+		req := &pb.CreateOrderRequest{
+			Addr: &pb.CreateOrderRequestAddress{Name: "Nguyễn Văn A", Phone: "0912345678", Detail: "1 Lê Lợi, Quận 1"},
+			ListOrder: []*pb.CreateOrderRequestOrder{
+				{ProductId: 1, OrderQuantity: 2},
+				{ProductId: 2, OrderQuantity: 1},
+			},
+		}
+
+		preview, err := srv.PreviewOrder(userContext("customer"), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !preview.GetCanCheckout() || preview.GetShippingFee() != 30000 || preview.GetGrandTotal() != 680000 {
+			t.Fatalf("the preview is %+v", preview)
+		}
+
+		created, err := srv.CreateOrder(userContext("customer"), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.GetTotalShippingFee() != preview.GetShippingFee() || created.GetGrandTotal() != preview.GetGrandTotal() {
+			t.Fatalf("the checkout charged %d shipping and %d in total, the preview showed %d and %d",
+				created.GetTotalShippingFee(), created.GetGrandTotal(), preview.GetShippingFee(), preview.GetGrandTotal())
+		}
+
+		// the orders keep the fee and the payments cover it
+		payment := srv.payment.(*fakePaymentProvider)
+		var charged, authorized int64
+		for _, id := range created.GetListOrderId() {
+			order, err := store.GetOrderByID(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			charged += orderAmount(order)
+			payment.mu.Lock()
+			authorized += payment.payments[order.PaymentID].amount
+			payment.mu.Unlock()
+		}
+		if charged != preview.GetGrandTotal() || authorized != preview.GetGrandTotal() {
+			t.Errorf("the orders charge %d and %d is authorized, want %d", charged, authorized, preview.GetGrandTotal())
+		}
+	})
+}
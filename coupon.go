@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var errInvalidCoupon = errors.New("Mã giảm giá không hợp lệ")
+
+func (srv orderService) CreateCoupon(ctx context.Context, req *pb.CreateCouponRequest) (*pb.Coupon, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	// a supplier can only discount its own products, an admin can create
+	// coupons for the whole shop or for any supplier
+	supplierID := req.GetSupplierId()
+	switch claims.GetUserRole() {
+	case pb.UserRole_admin:
+	case pb.UserRole_supplier:
+		if supplierID != 0 && supplierID != userID {
+			return nil, errors.New("Tạo mã giảm giá không thành công, unauthorization")
+		}
+		supplierID = userID
+	default:
+		return nil, errors.New("Tạo mã giảm giá không thành công, unauthorization")
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.GetCode()))
+	switch {
+	case code == "":
+		return nil, errors.New("Vui lòng nhập mã giảm giá")
+	case utf8.RuneCountInString(code) > 64:
+		return nil, errors.New("Mã giảm giá không được quá 64 ký tự")
+	case req.GetValue() <= 0:
+		return nil, errors.New("Giá trị giảm giá không hợp lệ")
+	case req.GetType() == pb.CouponType_coupon_percentage && req.GetValue() > 100:
+		return nil, errors.New("Phần trăm giảm giá không được quá 100")
+	case req.GetMaxDiscount() < 0 || req.GetMinOrderValue() < 0:
+		return nil, errors.New("Giá trị giảm giá không hợp lệ")
+	case req.GetUsageLimit() < 0 || req.GetUsageLimitPerCustomer() < 0:
+		return nil, errors.New("Giới hạn sử dụng không hợp lệ")
+	}
+
+	startsAt := time.Now()
+	if req.StartsAt != nil {
+		startsAt = req.GetStartsAt().AsTime()
+	}
+	var endsAt sql.NullTime
+	if req.EndsAt != nil {
+		endsAt = sql.NullTime{Time: req.GetEndsAt().AsTime(), Valid: true}
+		if !endsAt.Time.After(startsAt) {
+			return nil, errors.New("Thời gian kết thúc phải sau thời gian bắt đầu")
+		}
+	}
+
+	if req.GetProductId() != 0 {
+		product, err := srv.productClient.GetProduct(ctx, &pb.GetProductRequest{
+			ProductId: req.GetProductId(),
+		})
+		if err != nil {
+			log.Println(err)
+			return nil, errors.New("Sản phẩm không tồn tại")
+		}
+		if supplierID != 0 && product.GetSupplierId() != supplierID {
+			return nil, errors.New("Sản phẩm không thuộc cửa hàng")
+		}
+	}
+
+	if _, err := srv.orderStore.GetCouponByCode(ctx, code); err == nil {
+		return nil, errors.New("Mã giảm giá đã tồn tại")
+	}
+
+	coupon, err := srv.orderStore.CreateCoupon(ctx, repository.CreateCouponParams{
+		Code:                  code,
+		Type:                  repository.CouponTypeEnum(strings.TrimPrefix(req.GetType().String(), "coupon_")),
+		Value:                 req.GetValue(),
+		MaxDiscount:           req.GetMaxDiscount(),
+		MinOrderValue:         req.GetMinOrderValue(),
+		SupplierID:            supplierID,
+		ProductID:             req.GetProductId(),
+		UsageLimit:            req.GetUsageLimit(),
+		UsageLimitPerCustomer: req.GetUsageLimitPerCustomer(),
+		StartsAt:              startsAt,
+		EndsAt:                endsAt,
+		CreatedBy:             userID,
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Tạo mã giảm giá không thành công")
+	}
+
+	return toPbCoupon(coupon), nil
+}
+
+func (srv orderService) SetCouponActive(ctx context.Context, req *pb.SetCouponActiveRequest) (*pb.Coupon, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	coupon, err := srv.orderStore.GetCouponByID(ctx, req.GetCouponId())
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không tìm thấy mã giảm giá")
+	}
+	if !(claims.GetUserRole() == pb.UserRole_admin || (claims.GetUserRole() == pb.UserRole_supplier && coupon.SupplierID == userID)) {
+		return nil, errors.New("Cập nhật mã giảm giá không thành công, unauthorization")
+	}
+
+	coupon, err = srv.orderStore.SetCouponActive(ctx, repository.SetCouponActiveParams{
+		ID:     coupon.ID,
+		Active: req.GetActive(),
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Cập nhật mã giảm giá không thành công")
+	}
+
+	return toPbCoupon(coupon), nil
+}
+
+func (srv orderService) ListCoupon(ctx context.Context, _ *empty.Empty) (*pb.ListCouponResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	var listCoupon []repository.Coupon
+	switch claims.GetUserRole() {
+	case pb.UserRole_admin:
+		listCoupon, err = srv.orderStore.GetAllCoupon(ctx)
+	case pb.UserRole_supplier:
+		listCoupon, err = srv.orderStore.GetCouponBySupplier(ctx, userID)
+	default:
+		return nil, errors.New("Unauthorization")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*pb.Coupon, 0, len(listCoupon))
+	for _, coupon := range listCoupon {
+		result = append(result, toPbCoupon(coupon))
+	}
+
+	return &pb.ListCouponResponse{
+		ListCoupon: result,
+	}, nil
+}
+
+// applyCoupon checks a coupon code against the valid lines of a checkout and
+// spreads the discount over the lines it covers, in proportion to their
+// total price. Nothing is redeemed, see redeemCoupon.
+func (srv orderService) applyCoupon(ctx context.Context, customerID int64, code string, listLine []*pb.OrderLineResult) (repository.Coupon, int64, error) {
+	coupon, err := srv.orderStore.GetCouponByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return coupon, 0, errInvalidCoupon
+	}
+
+	now := time.Now()
+	switch {
+	case !coupon.Active:
+		return coupon, 0, errInvalidCoupon
+	case now.Before(coupon.StartsAt):
+		return coupon, 0, fmt.Errorf("%w, mã chưa đến thời gian sử dụng", errInvalidCoupon)
+	case coupon.EndsAt.Valid && !now.Before(coupon.EndsAt.Time):
+		return coupon, 0, fmt.Errorf("%w, mã đã hết hạn", errInvalidCoupon)
+	case coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit:
+		return coupon, 0, fmt.Errorf("%w, mã đã hết lượt sử dụng", errInvalidCoupon)
+	}
+	if coupon.UsageLimitPerCustomer > 0 {
+		n, err := srv.orderStore.CountCouponRedemptionByCustomer(ctx, repository.CountCouponRedemptionByCustomerParams{
+			CouponID:   coupon.ID,
+			CustomerID: customerID,
+		})
+		if err != nil {
+			return coupon, 0, err
+		}
+		if n >= int64(coupon.UsageLimitPerCustomer) {
+			return coupon, 0, fmt.Errorf("%w, bạn đã dùng hết lượt của mã này", errInvalidCoupon)
+		}
+	}
+
+	var listEligible []*pb.OrderLineResult
+	var eligibleTotal int64
+	for _, line := range listLine {
+		if line.GetError() != "" {
+			continue
+		}
+		if coupon.SupplierID != 0 && line.GetSupplierId() != coupon.SupplierID {
+			continue
+		}
+		if coupon.ProductID != 0 && line.GetProductId() != coupon.ProductID {
+			continue
+		}
+		listEligible = append(listEligible, line)
+		eligibleTotal += line.GetTotalPrice()
+	}
+	if len(listEligible) == 0 {
+		return coupon, 0, fmt.Errorf("%w, mã không áp dụng cho sản phẩm trong đơn hàng", errInvalidCoupon)
+	}
+	if eligibleTotal < coupon.MinOrderValue {
+		return coupon, 0, fmt.Errorf("%w, đơn hàng chưa đạt giá trị tối thiểu %d", errInvalidCoupon, coupon.MinOrderValue)
+	}
+
+	var discount int64
+	switch coupon.Type {
+	case repository.CouponTypeEnumPercentage:
+		discount = eligibleTotal * coupon.Value / 100
+	case repository.CouponTypeEnumFixed:
+		discount = coupon.Value
+	}
+	if coupon.MaxDiscount > 0 && discount > coupon.MaxDiscount {
+		discount = coupon.MaxDiscount
+	}
+	if discount > eligibleTotal {
+		discount = eligibleTotal
+	}
+
+	// the last line takes the rounding remainder
+	rest := discount
+	for i, line := range listEligible {
+		share := discount * line.GetTotalPrice() / eligibleTotal
+		if i == len(listEligible)-1 {
+			share = rest
+		}
+		line.Discount = share
+		rest -= share
+	}
+
+	return coupon, discount, nil
+}
+
+// redeemCoupon counts one use of a coupon inside the transaction of q. The
+// usage limits are checked again under the row lock of the coupon, so
+// concurrent checkouts can't go past them.
+func redeemCoupon(ctx context.Context, q repository.Querier, customerID int64, coupon repository.Coupon, discount int64) (repository.CouponRedemption, error) {
+	coupon, err := q.RedeemCoupon(ctx, coupon.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.CouponRedemption{}, fmt.Errorf("%w, mã đã hết lượt sử dụng", errInvalidCoupon)
+	}
+	if err != nil {
+		return repository.CouponRedemption{}, err
+	}
+	if coupon.UsageLimitPerCustomer > 0 {
+		n, err := q.CountCouponRedemptionByCustomer(ctx, repository.CountCouponRedemptionByCustomerParams{
+			CouponID:   coupon.ID,
+			CustomerID: customerID,
+		})
+		if err != nil {
+			return repository.CouponRedemption{}, err
+		}
+		if n >= int64(coupon.UsageLimitPerCustomer) {
+			return repository.CouponRedemption{}, fmt.Errorf("%w, bạn đã dùng hết lượt của mã này", errInvalidCoupon)
+		}
+	}
+	return q.CreateCouponRedemption(ctx, repository.CreateCouponRedemptionParams{
+		CouponID:   coupon.ID,
+		CustomerID: customerID,
+		Discount:   discount,
+	})
+}
+
+// releaseOrderCoupon gives back the use of the coupon of a cancelled order
+// once every order of the checkout that redeemed it has been cancelled. It
+// runs in the transaction of the cancel, the redemption is locked first so
+// the last two orders of a checkout cancelled at the same time can't both
+// count the other one as active. Releasing twice is a no-op.
+func releaseOrderCoupon(ctx context.Context, q repository.Querier, order repository.Order) error {
+	if !order.CouponRedemptionID.Valid {
+		return nil
+	}
+	redemption, err := q.GetCouponRedemptionForUpdate(ctx, order.CouponRedemptionID.Int64)
+	if err != nil {
+		return err
+	}
+	if redemption.Released {
+		return nil
+	}
+	n, err := q.CountActiveOrderByCouponRedemption(ctx, order.CouponRedemptionID)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	redemption, err = q.ReleaseCouponRedemption(ctx, redemption.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return q.UnredeemCoupon(ctx, redemption.CouponID)
+}
+
+func toPbCoupon(coupon repository.Coupon) *pb.Coupon {
+	result := &pb.Coupon{
+		CouponId:              coupon.ID,
+		Code:                  coupon.Code,
+		Type:                  pb.CouponType(pb.CouponType_value["coupon_"+string(coupon.Type)]),
+		Value:                 coupon.Value,
+		MaxDiscount:           coupon.MaxDiscount,
+		MinOrderValue:         coupon.MinOrderValue,
+		SupplierId:            coupon.SupplierID,
+		ProductId:             coupon.ProductID,
+		UsageLimit:            coupon.UsageLimit,
+		UsageLimitPerCustomer: coupon.UsageLimitPerCustomer,
+		UsedCount:             coupon.UsedCount,
+		StartsAt:              timestamppb.New(coupon.StartsAt),
+		Active:                coupon.Active,
+	}
+	if coupon.EndsAt.Valid {
+		result.EndsAt = timestamppb.New(coupon.EndsAt.Time)
+	}
+	return result
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// the days of the summaries, the migration 000014_create_dashboard_summary
+	// cuts them in the same time zone
+	dashboardTimeZone          = "Asia/Ho_Chi_Minh"
+	defaultDashboardDays       = 30
+	defaultSlowSupplierLimit   = 10
+	maxSlowSupplierLimit       = 100
+	checkoutOutcomeMaxDuration = 5 * time.Second
+)
+
+// the stages of the checkout saga, a checkout failing for another reason
+// than the ones below is counted at the stage it stopped
+const (
+	checkoutStageResolveLines     = "resolve_lines"
+	checkoutStageApplyCoupon      = "apply_coupon"
+	checkoutStageCheckInventory   = "check_inventory"
+	checkoutStageUpdateInventory  = "update_inventory"
+	checkoutStageAuthorizePayment = "authorize_payment"
+	checkoutStageSaveOrders       = "save_orders"
+)
+
+// checkoutFailureReason is the reason a checkout failed with err at stage.
+func checkoutFailureReason(err error, stage string) string {
+	switch {
+	case errors.Is(err, errInvalidOrderLine):
+		return "invalid_line"
+	case errors.Is(err, errInvalidCoupon):
+		return "invalid_coupon"
+	case errors.Is(err, errPriceChanged):
+		return "price_changed"
+	case errors.Is(err, errNotEnoughInventory):
+		return "out_of_stock"
+	}
+	return stage
+}
+
+// recordCheckoutOutcome keeps the outcome of a checkout for the dashboard,
+// failureReason is empty when the orders were placed. It has its own
+// context, the outcome of a checkout cancelled by the customer is saved too.
+// The checkout is over, an outcome that can't be saved is only logged.
+func (srv orderService) recordCheckoutOutcome(customerID int64, failureReason string, lineCount int) {
+	ctx, cancel := context.WithTimeout(context.Background(), checkoutOutcomeMaxDuration)
+	defer cancel()
+
+	err := srv.orderStore.CreateCheckoutOutcome(ctx, repository.CreateCheckoutOutcomeParams{
+		CustomerID:    customerID,
+		FailureReason: failureReason,
+		LineCount:     int32(lineCount),
+	})
+	if err != nil {
+		log.Println("can't save checkout outcome: ", err)
+	}
+}
+
+// runDashboardRefresher refreshes the summaries of the dashboard every
+// interval, until ctx is done. Every replica can run it, only one of them
+// refreshes at a time.
+func (srv orderService) runDashboardRefresher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := srv.refreshDashboard(ctx); err != nil {
+			log.Println("can't refresh dashboard: ", err)
+		}
+	}
+}
+
+// refreshDashboard refreshes the summaries in one transaction, so they all
+// show the same moment. It does nothing while another replica refreshes them.
+func (srv orderService) refreshDashboard(ctx context.Context) error {
+	return srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		locked, err := qtx.TryLockDashboardRefresh(ctx)
+		if err != nil || !locked {
+			return err
+		}
+		return qtx.RefreshDashboardSummary(ctx)
+	})
+}
+
+// dashboardDays returns the first day and the day after the last day of the
+// summaries between from and to, by default the last 30 days. The days start
+// at midnight in loc.
+func dashboardDays(from, to *timestamppb.Timestamp, loc *time.Location) (time.Time, time.Time, error) {
+	createdTo := time.Now()
+	if to != nil {
+		createdTo = to.AsTime()
+	}
+	createdFrom := createdTo.AddDate(0, 0, -defaultDashboardDays)
+	if from != nil {
+		createdFrom = from.AsTime()
+	}
+	if !createdFrom.Before(createdTo) {
+		return time.Time{}, time.Time{}, errors.New("Khoảng thời gian báo cáo không hợp lệ")
+	}
+
+	// a day started before created_to is in the range
+	createdFrom = createdFrom.In(loc)
+	createdTo = createdTo.Add(-time.Nanosecond).In(loc)
+	dayFrom := time.Date(createdFrom.Year(), createdFrom.Month(), createdFrom.Day(), 0, 0, 0, 0, loc)
+	dayTo := time.Date(createdTo.Year(), createdTo.Month(), createdTo.Day()+1, 0, 0, 0, 0, loc)
+	return dayFrom, dayTo, nil
+}
+
+// sqlDate is the date of t for a date parameter, without the offset of its
+// time zone the driver would send.
+func sqlDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// GetGmvReport returns the GMV and the orders of the marketplace per day,
+// week or month and how many of them are still waiting, were handled or
+// were cancelled. The numbers are as of the last refresh of the summaries.
+func (srv orderService) GetGmvReport(ctx context.Context, req *pb.GetGmvReportRequest) (*pb.GetGmvReportResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	if _, err := srv.adminID(ctx); err != nil {
+		return nil, err
+	}
+
+	interval, err := reportInterval(req.GetInterval())
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Khoảng thời gian báo cáo không hợp lệ")
+	}
+	loc, err := time.LoadLocation(dashboardTimeZone)
+	if err != nil {
+		return nil, err
+	}
+	dayFrom, dayTo, err := dashboardDays(req.GetCreatedFrom(), req.GetCreatedTo(), loc)
+	if err != nil {
+		return nil, err
+	}
+	listStart := reportBuckets(dayFrom, dayTo, interval, loc)
+	if len(listStart) > maxReportBuckets {
+		return nil, errors.New("Khoảng thời gian báo cáo quá dài")
+	}
+
+	listRow, err := srv.orderStore.GetGmvSeries(ctx, repository.GetGmvSeriesParams{
+		Bucket:  interval,
+		DayFrom: sqlDate(dayFrom),
+		DayTo:   sqlDate(dayTo),
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy báo cáo GMV")
+	}
+	funnel, err := srv.orderStore.GetOrderFunnel(ctx, repository.GetOrderFunnelParams{
+		DayFrom: sqlDate(dayFrom),
+		DayTo:   sqlDate(dayTo),
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy báo cáo GMV")
+	}
+	refreshedAt, err := srv.orderStore.GetDashboardRefreshedAt(ctx)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy báo cáo GMV")
+	}
+
+	m := make(map[int64]repository.GetGmvSeriesRow, len(listRow))
+	for _, row := range listRow {
+		m[row.BucketStart.Unix()] = row
+	}
+	var gmv int64
+	listBucket := make([]*pb.GmvBucket, 0, len(listStart))
+	for _, start := range listStart {
+		row := m[start.Unix()]
+		gmv += row.Gmv
+		listBucket = append(listBucket, &pb.GmvBucket{
+			Start:          timestamppb.New(start),
+			OrderCount:     row.OrderCount,
+			CancelledCount: row.CancelledCount,
+			UnitCount:      row.UnitCount,
+			Gmv:            row.Gmv,
+		})
+	}
+
+	pbFunnel := &pb.OrderFunnel{
+		OrderCount:     funnel.OrderCount,
+		WaitingCount:   funnel.WaitingCount,
+		HandledCount:   funnel.HandledCount,
+		CancelledCount: funnel.CancelledCount,
+	}
+	if funnel.OrderCount > 0 {
+		pbFunnel.WaitingRate = float64(funnel.WaitingCount) / float64(funnel.OrderCount)
+		pbFunnel.HandledRate = float64(funnel.HandledCount) / float64(funnel.OrderCount)
+		pbFunnel.CancelledRate = float64(funnel.CancelledCount) / float64(funnel.OrderCount)
+	}
+
+	return &pb.GetGmvReportResponse{
+		ListBucket:  listBucket,
+		Funnel:      pbFunnel,
+		Gmv:         gmv,
+		RefreshedAt: timestamppb.New(refreshedAt),
+	}, nil
+}
+
+// GetSlowestSuppliers returns the suppliers taking the longest to handle the
+// orders of the last 30 days.
+func (srv orderService) GetSlowestSuppliers(ctx context.Context, req *pb.GetSlowestSuppliersRequest) (*pb.GetSlowestSuppliersResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	if _, err := srv.adminID(ctx); err != nil {
+		return nil, err
+	}
+
+	limit := int32(defaultSlowSupplierLimit)
+	if req.GetLimit() > 0 {
+		limit = req.GetLimit()
+	}
+	if limit > maxSlowSupplierLimit {
+		limit = maxSlowSupplierLimit
+	}
+
+	listSupplier, err := srv.orderStore.GetSlowestSuppliers(ctx, limit)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy danh sách cửa hàng")
+	}
+	refreshedAt, err := srv.orderStore.GetDashboardRefreshedAt(ctx)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy danh sách cửa hàng")
+	}
+
+	result := make([]*pb.SupplierHandling, 0, len(listSupplier))
+	for _, supplier := range listSupplier {
+		result = append(result, &pb.SupplierHandling{
+			SupplierId:             supplier.SupplierID,
+			OrderCount:             supplier.OrderCount,
+			HandledCount:           supplier.HandledCount,
+			WaitingCount:           supplier.WaitingCount,
+			AverageHandlingSeconds: int64(supplier.AverageHandlingSeconds),
+			OldestWaitingAt:        toPbNullTime(supplier.OldestWaitingAt),
+		})
+	}
+
+	return &pb.GetSlowestSuppliersResponse{
+		ListSupplier: result,
+		RefreshedAt:  timestamppb.New(refreshedAt),
+	}, nil
+}
+
+// GetCheckoutFailures returns why checkouts failed, with the share of all
+// the checkouts of every reason.
+func (srv orderService) GetCheckoutFailures(ctx context.Context, req *pb.GetCheckoutFailuresRequest) (*pb.GetCheckoutFailuresResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	if _, err := srv.adminID(ctx); err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(dashboardTimeZone)
+	if err != nil {
+		return nil, err
+	}
+	dayFrom, dayTo, err := dashboardDays(req.GetCreatedFrom(), req.GetCreatedTo(), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	listRow, err := srv.orderStore.GetCheckoutFailures(ctx, repository.GetCheckoutFailuresParams{
+		DayFrom: sqlDate(dayFrom),
+		DayTo:   sqlDate(dayTo),
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy báo cáo thanh toán")
+	}
+	refreshedAt, err := srv.orderStore.GetDashboardRefreshedAt(ctx)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy báo cáo thanh toán")
+	}
+
+	response := &pb.GetCheckoutFailuresResponse{
+		ListFailure: []*pb.CheckoutFailure{},
+		RefreshedAt: timestamppb.New(refreshedAt),
+	}
+	for _, row := range listRow {
+		response.CheckoutCount += row.CheckoutCount
+		// the placed checkouts have no reason
+		if row.FailureReason != "" {
+			response.FailedCount += row.CheckoutCount
+		}
+	}
+	for _, row := range listRow {
+		if row.FailureReason == "" {
+			continue
+		}
+		response.ListFailure = append(response.ListFailure, &pb.CheckoutFailure{
+			Reason:        row.FailureReason,
+			CheckoutCount: row.CheckoutCount,
+			Rate:          float64(row.CheckoutCount) / float64(response.CheckoutCount),
+		})
+	}
+	if response.CheckoutCount > 0 {
+		response.FailureRate = float64(response.FailedCount) / float64(response.CheckoutCount)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+// refreshCountingStore counts the refreshes of the dashboard and can act as
+// if another replica held the refresh lock.
+type refreshCountingStore struct {
+	OrderStore
+	lockedElsewhere bool
+	refreshed       int
+}
+
+type refreshCountingQuerier struct {
+	repository.Querier
+	store *refreshCountingStore
+}
+
+func (s *refreshCountingStore) ExecTx(ctx context.Context, opts *sql.TxOptions, fn func(q repository.Querier) error) error {
+	return s.OrderStore.ExecTx(ctx, opts, func(q repository.Querier) error {
+		return fn(refreshCountingQuerier{Querier: q, store: s})
+	})
+}
+
+func (q refreshCountingQuerier) TryLockDashboardRefresh(ctx context.Context) (bool, error) {
+	if q.store.lockedElsewhere {
+		return false, nil
+	}
+	return q.Querier.TryLockDashboardRefresh(ctx)
+}
+
+func (q refreshCountingQuerier) RefreshDashboardSummary(ctx context.Context) error {
+	q.store.refreshed++
+	return q.Querier.RefreshDashboardSummary(ctx)
+}
+
+func TestRefreshDashboardLock(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		counting := &refreshCountingStore{OrderStore: store}
+		srv := newTestService(t, counting)
+
+		counting.lockedElsewhere = true
+		if err := srv.refreshDashboard(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if counting.refreshed != 0 {
+			t.Fatal("the dashboard was refreshed while another replica held the lock")
+		}
+
+		counting.lockedElsewhere = false
+		if err := srv.refreshDashboard(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if counting.refreshed != 1 {
+			t.Fatalf("the dashboard was refreshed %d times, want once", counting.refreshed)
+		}
+	})
+}
+
+// TestTryLockDashboardRefresh checks the advisory lock itself: it is held by
+// one transaction at a time and let go when the transaction ends. The memory
+// store runs its transactions one at a time and needs no lock.
+func TestTryLockDashboardRefresh(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		if _, ok := store.(*postgresOrderStore); !ok {
+			t.Skip("only Postgres has the advisory lock")
+		}
+		ctx := context.Background()
+
+		err := store.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+			locked, err := qtx.TryLockDashboardRefresh(ctx)
+			if err != nil || !locked {
+				t.Fatalf("TryLockDashboardRefresh: %v, %v", locked, err)
+			}
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				err := store.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+					locked, err := qtx.TryLockDashboardRefresh(ctx)
+					if err != nil || locked {
+						t.Errorf("TryLockDashboardRefresh while it is held: %v, %v", locked, err)
+					}
+					return err
+				})
+				if err != nil {
+					t.Error(err)
+				}
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Error("TryLockDashboardRefresh waited for the lock")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = store.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+			locked, err := qtx.TryLockDashboardRefresh(ctx)
+			if err != nil || !locked {
+				t.Errorf("TryLockDashboardRefresh after the transaction: %v, %v", locked, err)
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,16 @@
+UPDATE "order"
+SET "status" = 'handled'
+WHERE "status" IN ('shipped', 'delivered');
+
+ALTER TYPE order_status_enum RENAME TO order_status_enum_old;
+
+CREATE TYPE order_status_enum AS ENUM ('waiting', 'handled', 'cancel');
+
+ALTER TABLE "order" ALTER COLUMN "status" DROP DEFAULT;
+
+ALTER TABLE "order"
+ALTER COLUMN "status" TYPE order_status_enum USING "status"::text::order_status_enum;
+
+ALTER TABLE "order" ALTER COLUMN "status" SET DEFAULT 'waiting';
+
+DROP TYPE order_status_enum_old;
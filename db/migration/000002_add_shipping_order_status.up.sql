@@ -0,0 +1,3 @@
+ALTER TYPE order_status_enum ADD VALUE 'shipped';
+
+ALTER TYPE order_status_enum ADD VALUE 'delivered';
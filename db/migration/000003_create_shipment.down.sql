@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "shipment_item";
+
+DROP TABLE IF EXISTS "shipment";
+
+DROP TYPE IF EXISTS shipment_status_enum;
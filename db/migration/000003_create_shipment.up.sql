@@ -0,0 +1,29 @@
+CREATE TYPE shipment_status_enum AS ENUM ('pending', 'shipped', 'delivered');
+
+CREATE TABLE "shipment" (
+    "id" serial8 PRIMARY KEY,
+    "supplier_id" bigint NOT NULL,
+    "carrier" varchar(64) NOT NULL,
+    "tracking_number" varchar(128) NOT NULL DEFAULT '',
+    "status" shipment_status_enum NOT NULL DEFAULT 'pending',
+    "shipped_at" timestamptz,
+    "delivered_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "shipment_item" (
+    "id" serial8 PRIMARY KEY,
+    "shipment_id" bigint NOT NULL,
+    "order_id" bigint NOT NULL,
+    "quantity" integer NOT NULL CHECK ("quantity" > 0)
+);
+
+ALTER TABLE "shipment_item"
+ADD
+    FOREIGN KEY ("shipment_id") REFERENCES "shipment" ("id");
+
+ALTER TABLE "shipment_item"
+ADD
+    FOREIGN KEY ("order_id") REFERENCES "order" ("id");
+
+CREATE INDEX ON "shipment_item" ("order_id");
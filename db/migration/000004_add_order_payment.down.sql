@@ -0,0 +1,7 @@
+ALTER TABLE "order" DROP COLUMN IF EXISTS "payment_id";
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS "payment_status";
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS "price";
+
+DROP TYPE IF EXISTS payment_status_enum;
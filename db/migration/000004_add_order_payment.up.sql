@@ -0,0 +1,9 @@
+CREATE TYPE payment_status_enum AS ENUM ('unpaid', 'authorized', 'captured', 'refunded', 'failed');
+
+ALTER TABLE "order" ADD COLUMN "price" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "order"
+ADD
+    COLUMN "payment_status" payment_status_enum NOT NULL DEFAULT 'unpaid';
+
+ALTER TABLE "order" ADD COLUMN "payment_id" varchar(128) NOT NULL DEFAULT '';
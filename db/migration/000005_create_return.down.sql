@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "refund";
+
+DROP TABLE IF EXISTS "return_item";
+
+DROP TABLE IF EXISTS "return_request";
+
+DROP TYPE IF EXISTS return_status_enum;
@@ -0,0 +1,52 @@
+CREATE TYPE return_status_enum AS ENUM ('requested', 'approved', 'rejected', 'received', 'refunded');
+
+CREATE TABLE "return_request" (
+    "id" serial8 PRIMARY KEY,
+    "customer_id" bigint NOT NULL,
+    "supplier_id" bigint NOT NULL,
+    "reason" varchar(512) NOT NULL,
+    "status" return_status_enum NOT NULL DEFAULT 'requested',
+    "note" varchar(512) NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "return_item" (
+    "id" serial8 PRIMARY KEY,
+    "return_id" bigint NOT NULL,
+    "order_id" bigint NOT NULL,
+    "quantity" integer NOT NULL CHECK ("quantity" > 0)
+);
+
+CREATE TABLE "refund" (
+    "id" serial8 PRIMARY KEY,
+    "return_id" bigint NOT NULL,
+    "order_id" bigint NOT NULL,
+    "amount" bigint NOT NULL,
+    "payment_id" varchar(128) NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "return_item"
+ADD
+    FOREIGN KEY ("return_id") REFERENCES "return_request" ("id");
+
+ALTER TABLE "return_item"
+ADD
+    FOREIGN KEY ("order_id") REFERENCES "order" ("id");
+
+ALTER TABLE "refund"
+ADD
+    FOREIGN KEY ("return_id") REFERENCES "return_request" ("id");
+
+ALTER TABLE "refund"
+ADD
+    FOREIGN KEY ("order_id") REFERENCES "order" ("id");
+
+CREATE INDEX ON "return_request" ("customer_id");
+
+CREATE INDEX ON "return_request" ("supplier_id");
+
+CREATE INDEX ON "return_item" ("order_id");
+
+CREATE INDEX ON "refund" ("order_id");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "customer_address";
+
+ALTER TABLE "address" DROP COLUMN IF EXISTS "customer_id";
@@ -0,0 +1,23 @@
+ALTER TABLE "address" ADD COLUMN "customer_id" bigint;
+
+UPDATE "address"
+SET "customer_id" = "order"."customer_id"
+FROM "order"
+WHERE "order"."address_id" = "address"."id";
+
+CREATE INDEX ON "address" ("customer_id");
+
+CREATE TABLE "customer_address" (
+    "id" serial8 PRIMARY KEY,
+    "customer_id" bigint NOT NULL,
+    "name" varchar(256) NOT NULL,
+    "phone" varchar(64) NOT NULL,
+    "detail" varchar(64) NOT NULL,
+    "is_default" boolean NOT NULL DEFAULT false,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "customer_address" ("customer_id");
+
+CREATE UNIQUE INDEX ON "customer_address" ("customer_id") WHERE "is_default";
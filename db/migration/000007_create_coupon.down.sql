@@ -0,0 +1,9 @@
+ALTER TABLE "order" DROP COLUMN IF EXISTS "coupon_redemption_id";
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS "discount";
+
+DROP TABLE IF EXISTS "coupon_redemption";
+
+DROP TABLE IF EXISTS "coupon";
+
+DROP TYPE IF EXISTS coupon_type_enum;
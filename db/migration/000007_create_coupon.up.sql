@@ -0,0 +1,43 @@
+CREATE TYPE coupon_type_enum AS ENUM ('percentage', 'fixed');
+
+CREATE TABLE "coupon" (
+    "id" serial8 PRIMARY KEY,
+    "code" varchar(64) NOT NULL UNIQUE,
+    "type" coupon_type_enum NOT NULL,
+    "value" bigint NOT NULL CHECK ("value" > 0),
+    "max_discount" bigint NOT NULL DEFAULT 0,
+    "min_order_value" bigint NOT NULL DEFAULT 0,
+    "supplier_id" bigint NOT NULL DEFAULT 0,
+    "product_id" bigint NOT NULL DEFAULT 0,
+    "usage_limit" integer NOT NULL DEFAULT 0,
+    "usage_limit_per_customer" integer NOT NULL DEFAULT 0,
+    "used_count" integer NOT NULL DEFAULT 0,
+    "starts_at" timestamptz NOT NULL DEFAULT (now()),
+    "ends_at" timestamptz,
+    "active" boolean NOT NULL DEFAULT true,
+    "created_by" bigint NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "coupon_redemption" (
+    "id" serial8 PRIMARY KEY,
+    "coupon_id" bigint NOT NULL,
+    "customer_id" bigint NOT NULL,
+    "discount" bigint NOT NULL,
+    "released" boolean NOT NULL DEFAULT false,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "coupon_redemption"
+ADD
+    FOREIGN KEY ("coupon_id") REFERENCES "coupon" ("id");
+
+CREATE INDEX ON "coupon_redemption" ("coupon_id", "customer_id");
+
+ALTER TABLE "order" ADD COLUMN "discount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "order" ADD COLUMN "coupon_redemption_id" bigint;
+
+ALTER TABLE "order"
+ADD
+    FOREIGN KEY ("coupon_redemption_id") REFERENCES "coupon_redemption" ("id");
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "order_created_at_idx";
+
+DROP TABLE IF EXISTS "supplier_order_setting";
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS "cancel_reason";
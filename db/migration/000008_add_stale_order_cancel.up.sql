@@ -0,0 +1,9 @@
+ALTER TABLE "order" ADD COLUMN "cancel_reason" varchar(128) NOT NULL DEFAULT '';
+
+CREATE TABLE "supplier_order_setting" (
+    "supplier_id" bigint PRIMARY KEY,
+    "handle_deadline_hours" integer NOT NULL CHECK ("handle_deadline_hours" > 0),
+    "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "order" ("created_at") WHERE "status" = 'waiting';
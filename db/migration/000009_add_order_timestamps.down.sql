@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS "order_set_timestamps" ON "order";
+
+DROP FUNCTION IF EXISTS order_set_timestamps();
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS "cancelled_at";
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS "handled_at";
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS "updated_at";
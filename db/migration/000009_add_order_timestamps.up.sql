@@ -0,0 +1,27 @@
+ALTER TABLE "order" ADD COLUMN "updated_at" timestamptz NOT NULL DEFAULT (now());
+
+ALTER TABLE "order" ADD COLUMN "handled_at" timestamptz;
+
+ALTER TABLE "order" ADD COLUMN "cancelled_at" timestamptz;
+
+UPDATE "order" SET "updated_at" = "created_at";
+
+-- every update of an order goes through the trigger, the queries don't have
+-- to remember the timestamps
+CREATE FUNCTION order_set_timestamps() RETURNS trigger AS $$
+BEGIN
+    NEW."updated_at" = now();
+    IF NEW."status" IS DISTINCT FROM OLD."status" THEN
+        IF NEW."status" = 'handled' THEN
+            NEW."handled_at" = now();
+        ELSIF NEW."status" = 'cancel' THEN
+            NEW."cancelled_at" = now();
+        END IF;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "order_set_timestamps"
+BEFORE UPDATE ON "order"
+FOR EACH ROW EXECUTE FUNCTION order_set_timestamps();
@@ -0,0 +1,37 @@
+ALTER TABLE "order" ADD COLUMN "order_number" varchar(32);
+
+CREATE UNIQUE INDEX ON "order" ("order_number");
+
+-- the orders created before get a number built like newOrderNumber in
+-- order_number.go, the backfill is not a change of the order
+ALTER TABLE "order" DISABLE TRIGGER "order_set_timestamps";
+
+DO $$
+DECLARE
+    alphabet text := '0123456789ABCDEFGHJKMNPQRSTVWXYZ';
+    r record;
+    body text;
+    total int;
+    number text;
+BEGIN
+    FOR r IN SELECT "id", "created_at" FROM "order" ORDER BY "id" LOOP
+        LOOP
+            body := to_char(r."created_at", 'YYYYMMDD');
+            FOR i IN 1..4 LOOP
+                body := body || substr(alphabet, floor(random() * 32)::int + 1, 1);
+            END LOOP;
+            total := 0;
+            FOR i IN 1..length(body) LOOP
+                total := total + i * (strpos(alphabet, substr(body, i, 1)) - 1);
+            END LOOP;
+            number := 'ORD-' || substr(body, 1, 8) || '-' || substr(body, 9) || substr(alphabet, total % 31 + 1, 1);
+            EXIT WHEN NOT EXISTS (SELECT 1 FROM "order" WHERE "order_number" = number);
+        END LOOP;
+        UPDATE "order" SET "order_number" = number WHERE "id" = r."id";
+    END LOOP;
+END;
+$$;
+
+ALTER TABLE "order" ENABLE TRIGGER "order_set_timestamps";
+
+ALTER TABLE "order" ALTER COLUMN "order_number" SET NOT NULL;
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS "order_search_index" ON "order";
+
+DROP FUNCTION IF EXISTS order_search_index();
+
+DROP TABLE IF EXISTS "order_search";
+
+DROP FUNCTION IF EXISTS order_search_normalize(text);
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS "product_name";
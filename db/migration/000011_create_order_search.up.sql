@@ -0,0 +1,42 @@
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+ALTER TABLE "order" ADD COLUMN "product_name" varchar(256) NOT NULL DEFAULT '';
+
+-- unaccent() depends on the search path so it isn't immutable, the search
+-- documents and the queries go through this one
+CREATE FUNCTION order_search_normalize(text) RETURNS text AS $$
+    SELECT lower(public.unaccent('public.unaccent'::regdictionary, $1))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+CREATE TABLE "order_search" (
+    "order_id" bigint PRIMARY KEY REFERENCES "order" ("id"),
+    "document" tsvector NOT NULL
+);
+
+CREATE INDEX ON "order_search" USING gin ("document");
+
+-- the address and the product name are snapshots taken at checkout, the
+-- document only changes when the order points to other ones
+CREATE FUNCTION order_search_index() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO "order_search" ("order_id", "document")
+    SELECT NEW."id", to_tsvector('simple', order_search_normalize(
+        concat_ws(' ', "address"."name", "address"."phone", "address"."detail", NEW."product_name")
+    ))
+    FROM "address"
+    WHERE "address"."id" = NEW."address_id"
+    ON CONFLICT ("order_id") DO UPDATE SET "document" = EXCLUDED."document";
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "order_search_index"
+AFTER INSERT OR UPDATE OF "address_id", "product_name" ON "order"
+FOR EACH ROW EXECUTE FUNCTION order_search_index();
+
+INSERT INTO "order_search" ("order_id", "document")
+SELECT "order"."id", to_tsvector('simple', order_search_normalize(
+    concat_ws(' ', "address"."name", "address"."phone", "address"."detail", "order"."product_name")
+))
+FROM "order"
+JOIN "address" ON "address"."id" = "order"."address_id";
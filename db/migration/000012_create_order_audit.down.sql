@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS "order_audit";
+
+DROP TRIGGER IF EXISTS "order_record_status" ON "order";
+
+DROP FUNCTION IF EXISTS order_record_status();
+
+DROP TABLE IF EXISTS "order_status_history";
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS "hold_reason";
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS "held_at";
@@ -0,0 +1,53 @@
+ALTER TABLE "order" ADD COLUMN "held_at" timestamptz;
+
+ALTER TABLE "order" ADD COLUMN "hold_reason" varchar(256) NOT NULL DEFAULT '';
+
+-- every status an order goes through, whoever changes it
+CREATE TABLE "order_status_history" (
+    "id" bigserial PRIMARY KEY,
+    "order_id" bigint NOT NULL REFERENCES "order" ("id"),
+    "old_status" order_status_enum,
+    "new_status" order_status_enum,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "order_status_history" ("order_id");
+
+CREATE FUNCTION order_record_status() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO "order_status_history" ("order_id", "new_status") VALUES (NEW."id", NEW."status");
+    ELSIF NEW."status" IS DISTINCT FROM OLD."status" THEN
+        INSERT INTO "order_status_history" ("order_id", "old_status", "new_status") VALUES (NEW."id", OLD."status", NEW."status");
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "order_record_status"
+AFTER INSERT OR UPDATE OF "status" ON "order"
+FOR EACH ROW EXECUTE FUNCTION order_record_status();
+
+-- what is known of the orders created before
+INSERT INTO "order_status_history" ("order_id", "new_status", "created_at")
+SELECT "id", 'waiting', "created_at" FROM "order";
+
+INSERT INTO "order_status_history" ("order_id", "old_status", "new_status", "created_at")
+SELECT "id", 'waiting', 'handled', "handled_at" FROM "order" WHERE "handled_at" IS NOT NULL;
+
+INSERT INTO "order_status_history" ("order_id", "new_status", "created_at")
+SELECT "id", 'cancel', "cancelled_at" FROM "order" WHERE "cancelled_at" IS NOT NULL;
+
+-- the actions of admins on orders, with their reason
+CREATE TABLE "order_audit" (
+    "id" bigserial PRIMARY KEY,
+    "order_id" bigint NOT NULL REFERENCES "order" ("id"),
+    "admin_id" bigint NOT NULL,
+    "action" varchar(32) NOT NULL,
+    "reason" text NOT NULL,
+    "old_status" order_status_enum,
+    "new_status" order_status_enum,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "order_audit" ("order_id");
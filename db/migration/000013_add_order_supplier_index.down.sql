@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "order_supplier_id_created_at_idx";
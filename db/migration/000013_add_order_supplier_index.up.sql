@@ -0,0 +1,2 @@
+-- the sales reports of a supplier read its orders by creation time
+CREATE INDEX ON "order" ("supplier_id", "created_at");
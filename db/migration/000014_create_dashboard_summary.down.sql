@@ -0,0 +1,11 @@
+DROP FUNCTION IF EXISTS refresh_dashboard_summary();
+
+DROP TABLE IF EXISTS "dashboard_refresh";
+
+DROP MATERIALIZED VIEW IF EXISTS "checkout_outcome_daily_summary";
+
+DROP MATERIALIZED VIEW IF EXISTS "supplier_handling_summary";
+
+DROP MATERIALIZED VIEW IF EXISTS "order_daily_summary";
+
+DROP TABLE IF EXISTS "checkout_outcome";
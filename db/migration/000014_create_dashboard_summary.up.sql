@@ -0,0 +1,68 @@
+-- the outcome of every checkout saga, the failure reason is empty when the
+-- orders were placed
+CREATE TABLE "checkout_outcome" (
+    "id" bigserial PRIMARY KEY,
+    "customer_id" bigint NOT NULL,
+    "failure_reason" varchar(32) NOT NULL DEFAULT '',
+    "line_count" int NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "checkout_outcome" ("created_at");
+
+-- the summaries of the admin dashboard, refreshed by a background job. The
+-- days are the days of Vietnam, where the marketplace operates
+CREATE MATERIALIZED VIEW "order_daily_summary" AS
+SELECT ("created_at" AT TIME ZONE 'Asia/Ho_Chi_Minh')::date AS "day",
+    count(*)::bigint AS "order_count",
+    count(*) FILTER (WHERE "status" = 'waiting')::bigint AS "waiting_count",
+    count(*) FILTER (WHERE "handled_at" IS NOT NULL)::bigint AS "handled_count",
+    count(*) FILTER (WHERE "status" = 'cancel')::bigint AS "cancelled_count",
+    coalesce(sum("quantity") FILTER (WHERE "status" <> 'cancel'), 0)::bigint AS "unit_count",
+    coalesce(sum("price" * "quantity" - "discount") FILTER (WHERE "status" <> 'cancel'), 0)::bigint AS "gmv"
+FROM "order"
+GROUP BY 1;
+
+-- REFRESH CONCURRENTLY needs a unique index, the dashboard stays readable
+-- while it runs
+CREATE UNIQUE INDEX ON "order_daily_summary" ("day");
+
+-- how the suppliers handled the orders of the last 30 days
+CREATE MATERIALIZED VIEW "supplier_handling_summary" AS
+SELECT "supplier_id",
+    count(*)::bigint AS "order_count",
+    count(*) FILTER (WHERE "handled_at" IS NOT NULL)::bigint AS "handled_count",
+    count(*) FILTER (WHERE "status" = 'waiting')::bigint AS "waiting_count",
+    coalesce(extract(epoch FROM avg("handled_at" - "created_at")), 0)::float8 AS "average_handling_seconds",
+    min("created_at") FILTER (WHERE "status" = 'waiting')::timestamptz AS "oldest_waiting_at"
+FROM "order"
+WHERE "created_at" >= now() - interval '30 days'
+GROUP BY "supplier_id";
+
+CREATE UNIQUE INDEX ON "supplier_handling_summary" ("supplier_id");
+
+CREATE MATERIALIZED VIEW "checkout_outcome_daily_summary" AS
+SELECT ("created_at" AT TIME ZONE 'Asia/Ho_Chi_Minh')::date AS "day",
+    "failure_reason",
+    count(*)::bigint AS "checkout_count"
+FROM "checkout_outcome"
+GROUP BY 1, 2;
+
+CREATE UNIQUE INDEX ON "checkout_outcome_daily_summary" ("day", "failure_reason");
+
+-- when the summaries were last refreshed
+CREATE TABLE "dashboard_refresh" (
+    "id" int PRIMARY KEY CHECK ("id" = 1),
+    "refreshed_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+INSERT INTO "dashboard_refresh" ("id") VALUES (1);
+
+CREATE FUNCTION refresh_dashboard_summary() RETURNS void AS $$
+BEGIN
+    REFRESH MATERIALIZED VIEW CONCURRENTLY "order_daily_summary";
+    REFRESH MATERIALIZED VIEW CONCURRENTLY "supplier_handling_summary";
+    REFRESH MATERIALIZED VIEW CONCURRENTLY "checkout_outcome_daily_summary";
+    UPDATE "dashboard_refresh" SET "refreshed_at" = now() WHERE "id" = 1;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "order_supplier_id_id_idx";
+
+DROP INDEX IF EXISTS "order_customer_id_id_idx";
@@ -0,0 +1,4 @@
+-- the exports read the orders of a customer or a supplier by id
+CREATE INDEX ON "order" ("customer_id", "id");
+
+CREATE INDEX ON "order" ("supplier_id", "id");
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "invoice_line";
+
+DROP TABLE IF EXISTS "invoice";
+
+DROP TABLE IF EXISTS "invoice_sequence";
+
+DROP TABLE IF EXISTS "supplier_invoice_setting";
@@ -0,0 +1,55 @@
+-- what a supplier prints as the seller of its invoices
+CREATE TABLE "supplier_invoice_setting" (
+    "supplier_id" bigint PRIMARY KEY,
+    "legal_name" varchar(256) NOT NULL,
+    "tax_code" varchar(32) NOT NULL,
+    "address" varchar(512) NOT NULL,
+    "vat_percent" integer NOT NULL DEFAULT 10 CHECK ("vat_percent" >= 0 AND "vat_percent" <= 100),
+    "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- the last invoice number of a supplier in a year, it is taken in the
+-- transaction writing the invoice so a rollback leaves no gap
+CREATE TABLE "invoice_sequence" (
+    "supplier_id" bigint NOT NULL,
+    "year" integer NOT NULL,
+    "last_number" integer NOT NULL,
+    PRIMARY KEY ("supplier_id", "year")
+);
+
+-- the seller and the buyer are snapshots of the day of the invoice
+CREATE TABLE "invoice" (
+    "id" bigserial PRIMARY KEY,
+    "order_id" bigint NOT NULL UNIQUE REFERENCES "order" ("id"),
+    "supplier_id" bigint NOT NULL,
+    "customer_id" bigint NOT NULL,
+    "year" integer NOT NULL,
+    "number" integer NOT NULL,
+    "issued_at" timestamptz NOT NULL DEFAULT (now()),
+    "seller_name" varchar(256) NOT NULL,
+    "seller_tax_code" varchar(32) NOT NULL,
+    "seller_address" varchar(512) NOT NULL,
+    "buyer_name" varchar(256) NOT NULL,
+    "buyer_phone" varchar(64) NOT NULL,
+    "buyer_address" varchar(512) NOT NULL,
+    "subtotal" bigint NOT NULL,
+    "tax" bigint NOT NULL,
+    "total" bigint NOT NULL,
+    UNIQUE ("supplier_id", "year", "number")
+);
+
+CREATE INDEX ON "invoice" ("customer_id");
+
+-- the amounts include the tax, like the prices of the marketplace
+CREATE TABLE "invoice_line" (
+    "id" bigserial PRIMARY KEY,
+    "invoice_id" bigint NOT NULL REFERENCES "invoice" ("id"),
+    "description" varchar(256) NOT NULL,
+    "quantity" integer NOT NULL,
+    "unit_price" bigint NOT NULL,
+    "amount" bigint NOT NULL,
+    "vat_percent" integer NOT NULL,
+    "tax" bigint NOT NULL
+);
+
+CREATE INDEX ON "invoice_line" ("invoice_id");
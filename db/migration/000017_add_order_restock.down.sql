@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "order_restock_retry_at_idx";
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS "restock_retry_at";
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS "restock_pending";
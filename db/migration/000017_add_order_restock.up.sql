@@ -0,0 +1,7 @@
+-- a cancelled order gives its inventory back once the cancel is committed,
+-- the orders still owing it are retried after "restock_retry_at"
+ALTER TABLE "order" ADD COLUMN "restock_pending" boolean NOT NULL DEFAULT false;
+
+ALTER TABLE "order" ADD COLUMN "restock_retry_at" timestamptz;
+
+CREATE INDEX ON "order" ("restock_retry_at") WHERE "restock_pending";
@@ -0,0 +1,3 @@
+-- a restock that keeps failing waits longer every time and is given up after
+-- a number of attempts, so it can't hold back the others
+ALTER TABLE "order" ADD COLUMN "restock_attempts" int NOT NULL DEFAULT 0;
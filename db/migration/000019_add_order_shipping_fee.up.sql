@@ -0,0 +1,3 @@
+-- every supplier ships its own parcel, the fee is charged on the first order
+-- of the supplier in a checkout
+ALTER TABLE "order" ADD COLUMN "shipping_fee" bigint NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS "refund_retry_at_idx";
+
+ALTER TABLE "refund" DROP COLUMN IF EXISTS "retry_at";
+
+ALTER TABLE "refund" DROP COLUMN IF EXISTS "attempts";
+
+ALTER TABLE "refund" DROP COLUMN IF EXISTS "status";
+
+DROP TYPE IF EXISTS refund_status_enum;
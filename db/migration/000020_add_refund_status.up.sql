@@ -0,0 +1,14 @@
+-- a refund stays pending until the payment provider confirms it, the refunds
+-- still pending are retried after "retry_at". The refunds made before were
+-- sent to the provider already
+CREATE TYPE refund_status_enum AS ENUM ('pending', 'refunded', 'manual');
+
+ALTER TABLE "refund" ADD COLUMN "status" refund_status_enum NOT NULL DEFAULT 'refunded';
+
+ALTER TABLE "refund" ALTER COLUMN "status" SET DEFAULT 'pending';
+
+ALTER TABLE "refund" ADD COLUMN "attempts" int NOT NULL DEFAULT 0;
+
+ALTER TABLE "refund" ADD COLUMN "retry_at" timestamptz;
+
+CREATE INDEX ON "refund" ("retry_at") WHERE "status" = 'pending';
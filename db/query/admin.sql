@@ -0,0 +1,36 @@
+-- name: ForceCancelOrder :one
+-- an admin can cancel a held order, shipped goods come back through a return
+UPDATE "order"
+SET "status" = 'cancel', "cancel_reason" = $2, "held_at" = NULL, "hold_reason" = '', "restock_pending" = true
+WHERE "id" = $1 AND "status" IN ('waiting', 'handled')
+RETURNING *;
+
+-- name: HoldOrder :one
+-- goods on their way can't be held, a held order gets no new shipment
+UPDATE "order"
+SET "held_at" = now(), "hold_reason" = $2
+WHERE "id" = $1 AND "held_at" IS NULL AND "status" IN ('waiting', 'handled')
+RETURNING *;
+
+-- name: ReleaseOrder :one
+UPDATE "order"
+SET "held_at" = NULL, "hold_reason" = ''
+WHERE "id" = $1 AND "held_at" IS NOT NULL
+RETURNING *;
+
+-- name: CreateOrderAudit :one
+INSERT INTO "order_audit" (
+    "order_id", "admin_id", "action", "reason", "old_status", "new_status"
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetOrderAuditByOrder :many
+SELECT * FROM "order_audit"
+WHERE "order_id" = $1
+ORDER BY "id";
+
+-- name: GetOrderStatusHistoryByOrder :many
+SELECT * FROM "order_status_history"
+WHERE "order_id" = $1
+ORDER BY "id";
@@ -0,0 +1,67 @@
+-- name: CreateCoupon :one
+INSERT INTO "coupon" (
+    "code", "type", "value", "max_discount", "min_order_value", "supplier_id", "product_id",
+    "usage_limit", "usage_limit_per_customer", "starts_at", "ends_at", "created_by"
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+) RETURNING *;
+
+-- name: GetCouponByID :one
+SELECT * FROM "coupon"
+WHERE "id" = $1 LIMIT 1;
+
+-- name: GetCouponByCode :one
+SELECT * FROM "coupon"
+WHERE "code" = $1 LIMIT 1;
+
+-- name: GetAllCoupon :many
+SELECT * FROM "coupon"
+ORDER BY "id" DESC;
+
+-- name: GetCouponBySupplier :many
+SELECT * FROM "coupon"
+WHERE "supplier_id" = $1
+ORDER BY "id" DESC;
+
+-- name: SetCouponActive :one
+UPDATE "coupon"
+SET "active" = $2
+WHERE "id" = $1
+RETURNING *;
+
+-- name: RedeemCoupon :one
+UPDATE "coupon"
+SET "used_count" = "used_count" + 1
+WHERE "id" = $1 AND "active" AND ("usage_limit" = 0 OR "used_count" < "usage_limit")
+RETURNING *;
+
+-- name: CountCouponRedemptionByCustomer :one
+SELECT COUNT(*) FROM "coupon_redemption"
+WHERE "coupon_id" = $1 AND "customer_id" = $2 AND NOT "released";
+
+-- name: CreateCouponRedemption :one
+INSERT INTO "coupon_redemption" (
+    "coupon_id", "customer_id", "discount"
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetCouponRedemptionForUpdate :one
+SELECT * FROM "coupon_redemption"
+WHERE "id" = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ReleaseCouponRedemption :one
+UPDATE "coupon_redemption"
+SET "released" = true
+WHERE "id" = $1 AND NOT "released"
+RETURNING *;
+
+-- name: UnredeemCoupon :exec
+UPDATE "coupon"
+SET "used_count" = "used_count" - 1
+WHERE "id" = $1 AND "used_count" > 0;
+
+-- name: CountActiveOrderByCouponRedemption :one
+SELECT COUNT(*) FROM "order"
+WHERE "coupon_redemption_id" = $1 AND "status" <> 'cancel';
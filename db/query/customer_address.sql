@@ -0,0 +1,35 @@
+-- name: CreateCustomerAddress :one
+INSERT INTO "customer_address" (
+    "customer_id", "name", "phone", "detail", "is_default"
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: UpdateCustomerAddress :one
+UPDATE "customer_address"
+SET "name" = $3, "phone" = $4, "detail" = $5, "is_default" = $6, "updated_at" = now()
+WHERE "id" = $1 AND "customer_id" = $2
+RETURNING *;
+
+-- name: DeleteCustomerAddress :one
+DELETE FROM "customer_address"
+WHERE "id" = $1 AND "customer_id" = $2
+RETURNING *;
+
+-- name: GetCustomerAddressByID :one
+SELECT * FROM "customer_address"
+WHERE "id" = $1 LIMIT 1;
+
+-- name: GetCustomerAddressByCustomer :many
+SELECT * FROM "customer_address"
+WHERE "customer_id" = $1
+ORDER BY "is_default" DESC, "id" DESC;
+
+-- name: CountCustomerAddress :one
+SELECT COUNT(*) FROM "customer_address"
+WHERE "customer_id" = $1;
+
+-- name: ClearDefaultCustomerAddress :exec
+UPDATE "customer_address"
+SET "is_default" = false, "updated_at" = now()
+WHERE "customer_id" = $1 AND "is_default";
@@ -0,0 +1,48 @@
+-- name: CreateCheckoutOutcome :exec
+INSERT INTO "checkout_outcome" (
+    "customer_id", "failure_reason", "line_count"
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: TryLockDashboardRefresh :one
+-- only one replica refreshes the summaries, the others skip their turn
+SELECT pg_try_advisory_xact_lock(hashtext('dashboard_refresh'))::boolean AS "locked";
+
+-- name: RefreshDashboardSummary :exec
+SELECT refresh_dashboard_summary();
+
+-- name: GetDashboardRefreshedAt :one
+SELECT "refreshed_at" FROM "dashboard_refresh" WHERE "id" = 1;
+
+-- name: GetGmvSeries :many
+SELECT (date_trunc(sqlc.arg(bucket)::text, "day"::timestamp) AT TIME ZONE 'Asia/Ho_Chi_Minh')::timestamptz AS "bucket_start",
+    sum("order_count")::bigint AS "order_count",
+    sum("cancelled_count")::bigint AS "cancelled_count",
+    sum("unit_count")::bigint AS "unit_count",
+    sum("gmv")::bigint AS "gmv"
+FROM "order_daily_summary"
+WHERE "day" >= sqlc.arg(day_from)::date AND "day" < sqlc.arg(day_to)::date
+GROUP BY 1
+ORDER BY 1;
+
+-- name: GetOrderFunnel :one
+SELECT coalesce(sum("order_count"), 0)::bigint AS "order_count",
+    coalesce(sum("waiting_count"), 0)::bigint AS "waiting_count",
+    coalesce(sum("handled_count"), 0)::bigint AS "handled_count",
+    coalesce(sum("cancelled_count"), 0)::bigint AS "cancelled_count"
+FROM "order_daily_summary"
+WHERE "day" >= sqlc.arg(day_from)::date AND "day" < sqlc.arg(day_to)::date;
+
+-- name: GetSlowestSuppliers :many
+SELECT * FROM "supplier_handling_summary"
+WHERE "handled_count" > 0
+ORDER BY "average_handling_seconds" DESC, "supplier_id"
+LIMIT $1;
+
+-- name: GetCheckoutFailures :many
+SELECT "failure_reason", sum("checkout_count")::bigint AS "checkout_count"
+FROM "checkout_outcome_daily_summary"
+WHERE "day" >= sqlc.arg(day_from)::date AND "day" < sqlc.arg(day_to)::date
+GROUP BY "failure_reason"
+ORDER BY 2 DESC, 1;
@@ -0,0 +1,18 @@
+-- name: GetOrdersForExport :many
+-- the orders are read in batches of row_limit after the last id, the
+-- export never holds more than one batch
+SELECT "order"."id", "order"."order_number", "order"."created_at", "order"."updated_at",
+    "order"."handled_at", "order"."cancelled_at", "order"."status", "order"."payment_status",
+    "order"."customer_id", "order"."supplier_id", "order"."product_id", "order"."product_name",
+    "order"."price", "order"."quantity", "order"."discount", "order"."shipping_fee", "order"."cancel_reason",
+    "address"."name" AS "address_name", "address"."phone" AS "address_phone", "address"."detail" AS "address_detail"
+FROM "order"
+JOIN "address" ON "address"."id" = "order"."address_id"
+WHERE "order"."id" > sqlc.arg(after_id)
+    AND (sqlc.narg(customer_id)::bigint IS NULL OR "order"."customer_id" = sqlc.narg(customer_id)::bigint)
+    AND (sqlc.narg(supplier_id)::bigint IS NULL OR "order"."supplier_id" = sqlc.narg(supplier_id)::bigint)
+    AND (cardinality(sqlc.arg(list_status)::text[]) = 0 OR "order"."status"::text = ANY(sqlc.arg(list_status)::text[]))
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR "order"."created_at" >= sqlc.narg(created_from)::timestamptz)
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR "order"."created_at" < sqlc.narg(created_to)::timestamptz)
+ORDER BY "order"."id"
+LIMIT sqlc.arg(row_limit);
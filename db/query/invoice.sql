@@ -0,0 +1,59 @@
+-- name: UpsertSupplierInvoiceSetting :one
+INSERT INTO "supplier_invoice_setting" (
+    "supplier_id", "legal_name", "tax_code", "address", "vat_percent"
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT ("supplier_id") DO UPDATE
+SET "legal_name" = EXCLUDED."legal_name",
+    "tax_code" = EXCLUDED."tax_code",
+    "address" = EXCLUDED."address",
+    "vat_percent" = EXCLUDED."vat_percent",
+    "updated_at" = now()
+RETURNING *;
+
+-- name: GetSupplierInvoiceSetting :one
+SELECT * FROM "supplier_invoice_setting"
+WHERE "supplier_id" = $1;
+
+-- name: NextInvoiceNumber :one
+-- the row stays locked until the invoice is committed, the invoices of a
+-- supplier in a year are numbered one at a time
+INSERT INTO "invoice_sequence" (
+    "supplier_id", "year", "last_number"
+) VALUES (
+    $1, $2, 1
+)
+ON CONFLICT ("supplier_id", "year") DO UPDATE
+SET "last_number" = "invoice_sequence"."last_number" + 1
+RETURNING "last_number";
+
+-- name: CreateInvoice :one
+INSERT INTO "invoice" (
+    "order_id", "supplier_id", "customer_id", "year", "number", "issued_at",
+    "seller_name", "seller_tax_code", "seller_address",
+    "buyer_name", "buyer_phone", "buyer_address",
+    "subtotal", "tax", "total"
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+) RETURNING *;
+
+-- name: CreateInvoiceLine :one
+INSERT INTO "invoice_line" (
+    "invoice_id", "description", "quantity", "unit_price", "amount", "vat_percent", "tax"
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetInvoiceByID :one
+SELECT * FROM "invoice"
+WHERE "id" = $1;
+
+-- name: GetInvoiceByOrder :one
+SELECT * FROM "invoice"
+WHERE "order_id" = $1;
+
+-- name: GetInvoiceLineByInvoice :many
+SELECT * FROM "invoice_line"
+WHERE "invoice_id" = $1
+ORDER BY "id";
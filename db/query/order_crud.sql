@@ -1,14 +1,16 @@
 -- name: CreateOrder :one
 INSERT INTO "order" (
-    "customer_id", "supplier_id", "product_id", "quantity", "address_id"
+    "customer_id", "supplier_id", "product_id", "quantity", "address_id", "price", "discount", "coupon_redemption_id",
+    "payment_status", "payment_id", "order_number", "product_name", "shipping_fee"
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 ) RETURNING *;
 
--- name: UpdateOrderStatus :exec
+-- name: UpdateOrderStatus :one
 UPDATE "order"
-SET "status" = $1
-WHERE "id" = $2;
+SET "status" = sqlc.arg(new_status)
+WHERE "id" = sqlc.arg(id) AND "status" = sqlc.arg(old_status)
+RETURNING *;
 
 
 -- name: GetWaitingOrderBySupplier :many
@@ -25,11 +27,11 @@ WHERE "product_id" = $1;
 
 -- name: CountOrderHandledByProductId :many
 SELECT "quantity" from "order"
-WHERE "product_id" = $1 AND "status" = 'handled';
+WHERE "product_id" = $1 AND "status" IN ('handled', 'shipped', 'delivered');
 
 -- name: GetHandledOrderByCustomer :many
 SELECT * FROM "order"
-WHERE "customer_id" = $1 AND "status" = 'handled';
+WHERE "customer_id" = $1 AND "status" IN ('handled', 'shipped', 'delivered');
 
 -- name: GetCancelOrderByCustomer :many
 SELECT * FROM "order"
@@ -41,43 +43,68 @@ WHERE "supplier_id" = $1 AND "status" = 'cancel';
 
 -- name: GetHandledOrderBySupplier :many
 SELECT * FROM "order"
-WHERE "supplier_id" = $1 AND "status" = 'handled';
+WHERE "supplier_id" = $1 AND "status" IN ('handled', 'shipped', 'delivered');
 
 -- name: GetAddressById :one
 SELECT * FROM "address"
 WHERE "id" = $1
 LIMIT 1;
 
--- name: DeleteOrder :exec
+-- name: DeleteOrder :one
 UPDATE "order"
-SET "status" = 'cancel'
-WHERE "id" = $1;
+SET "status" = 'cancel', "restock_pending" = true
+WHERE "id" = $1 AND "status" IN ('waiting', 'handled') AND "held_at" IS NULL
+RETURNING *;
+
+-- name: GetOrderByAddressId :many
+SELECT * FROM "order"
+WHERE "address_id" = $1;
 
 -- name: GetOrderByID :one
 SELECT * FROM "order"
 WHERE "id" = $1 LIMIT 1;
 
--- name: HandleOrder :exec
+-- name: GetOrderByNumber :one
+SELECT * FROM "order"
+WHERE "order_number" = $1 LIMIT 1;
+
+-- name: GetOrderNumberByIDs :many
+SELECT "id", "order_number" FROM "order"
+WHERE "id" = ANY(sqlc.arg(ids)::bigint[]);
+
+-- name: GetOrderByIDForUpdate :one
+SELECT * FROM "order"
+WHERE "id" = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: HandleOrder :one
 UPDATE "order"
 SET "status" = 'handled'
-WHERE "id" = $1;
+WHERE "id" = $1 AND "status" = 'waiting' AND "held_at" IS NULL
+RETURNING *;
 
--- name: CancelOrder :exec
+-- name: CancelOrder :one
 UPDATE "order"
-SET "status" = 'cancel'
-WHERE "id" = $1;
+SET "status" = 'cancel', "restock_pending" = true
+WHERE "id" = $1 AND "status" = 'waiting' AND "held_at" IS NULL
+RETURNING *;
 
 -- name: CheckOrderIsHandled :one
 SELECT COUNT(*) FROM "order"
-WHERE "product_id" = $1 AND "customer_id" = $2 AND "status" = 'handled';
+WHERE "product_id" = $1 AND "customer_id" = $2 AND "status" IN ('handled', 'shipped', 'delivered');
 
 -- name: CreateAddress :one
 INSERT INTO "address" (
-    "name", "phone", "detail"
+    "name", "phone", "detail", "customer_id"
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: DeleteAddress :exec
 DELETE FROM "address"
-WHERE "id" = $1;
\ No newline at end of file
+WHERE "id" = $1;
+
+-- name: UpdateOrderPayment :exec
+UPDATE "order"
+SET "payment_status" = $2, "payment_id" = $3
+WHERE "id" = $1;
@@ -0,0 +1,37 @@
+-- name: ClaimOrderRestock :one
+-- the claim keeps the restock of the order until its next retry, another
+-- replica tries it again when the claimer died before FinishOrderRestock.
+-- Every attempt doubles the wait, up to 64 times retry_after_seconds
+UPDATE "order"
+SET "restock_attempts" = "restock_attempts" + 1,
+    "restock_retry_at" = now() + make_interval(secs => sqlc.arg(retry_after_seconds)::int * power(2, LEAST("restock_attempts", 6)))
+WHERE "id" = sqlc.arg(id) AND "restock_pending" AND ("restock_retry_at" IS NULL OR "restock_retry_at" <= now())
+    AND "restock_attempts" < sqlc.arg(max_attempts)::int
+RETURNING *;
+
+-- name: ClaimDueOrderRestock :many
+UPDATE "order"
+SET "restock_attempts" = "restock_attempts" + 1,
+    "restock_retry_at" = now() + make_interval(secs => sqlc.arg(retry_after_seconds)::int * power(2, LEAST("restock_attempts", 6)))
+WHERE "id" IN (
+    SELECT "id" FROM "order"
+    WHERE "restock_pending" AND ("restock_retry_at" IS NULL OR "restock_retry_at" <= now())
+        AND "restock_attempts" < sqlc.arg(max_attempts)::int
+    ORDER BY "restock_retry_at" NULLS FIRST, "id"
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: FinishOrderRestock :exec
+UPDATE "order"
+SET "restock_pending" = false, "restock_retry_at" = NULL
+WHERE "id" = $1;
+
+-- name: RetryOrderRestock :exec
+-- puts back a restock finished before product-service failed it, it is
+-- tried again at the retry time of its claim
+UPDATE "order"
+SET "restock_pending" = true, "restock_retry_at" = $2
+WHERE "id" = $1;
+
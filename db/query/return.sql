@@ -0,0 +1,90 @@
+-- name: CreateReturnRequest :one
+INSERT INTO "return_request" (
+    "customer_id", "supplier_id", "reason"
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: CreateReturnItem :one
+INSERT INTO "return_item" (
+    "return_id", "order_id", "quantity"
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetReturnRequestByID :one
+SELECT * FROM "return_request"
+WHERE "id" = $1 LIMIT 1;
+
+-- name: GetReturnItemByReturn :many
+SELECT * FROM "return_item"
+WHERE "return_id" = $1
+ORDER BY "id";
+
+-- name: GetReturnRequestByCustomer :many
+SELECT * FROM "return_request"
+WHERE "customer_id" = $1
+ORDER BY "id" DESC;
+
+-- name: GetReturnRequestBySupplier :many
+SELECT * FROM "return_request"
+WHERE "supplier_id" = $1
+ORDER BY "id" DESC;
+
+-- name: UpdateReturnRequestStatus :one
+UPDATE "return_request"
+SET "status" = sqlc.arg(new_status), "note" = sqlc.arg(note), "updated_at" = now()
+WHERE "id" = sqlc.arg(id) AND "status" = sqlc.arg(old_status)
+RETURNING *;
+
+-- name: GetReturnedQuantityByOrder :one
+SELECT COALESCE(SUM("return_item"."quantity"), 0)::int FROM "return_item"
+JOIN "return_request" ON "return_request"."id" = "return_item"."return_id"
+WHERE "return_item"."order_id" = $1 AND "return_request"."status" <> 'rejected';
+
+-- name: CreateRefund :one
+INSERT INTO "refund" (
+    "return_id", "order_id", "amount", "payment_id", "status"
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetRefundByReturn :many
+SELECT * FROM "refund"
+WHERE "return_id" = $1
+ORDER BY "id";
+
+-- name: GetRefundedAmountByOrder :one
+-- only the refunds the payment provider confirmed
+SELECT COALESCE(SUM("amount"), 0)::bigint FROM "refund"
+WHERE "order_id" = $1 AND "status" = 'refunded';
+
+-- name: ClaimRefund :one
+-- the claim keeps the refund until its next retry, another replica tries it
+-- again when the claimer died before FinishRefund. Every attempt doubles the
+-- wait, up to 64 times retry_after_seconds
+UPDATE "refund"
+SET "attempts" = "attempts" + 1,
+    "retry_at" = now() + make_interval(secs => sqlc.arg(retry_after_seconds)::int * power(2, LEAST("attempts", 6)))
+WHERE "id" = sqlc.arg(id) AND "status" = 'pending' AND ("retry_at" IS NULL OR "retry_at" <= now())
+    AND "attempts" < sqlc.arg(max_attempts)::int
+RETURNING *;
+
+-- name: ClaimDueRefund :many
+UPDATE "refund"
+SET "attempts" = "attempts" + 1,
+    "retry_at" = now() + make_interval(secs => sqlc.arg(retry_after_seconds)::int * power(2, LEAST("attempts", 6)))
+WHERE "id" IN (
+    SELECT "id" FROM "refund"
+    WHERE "status" = 'pending' AND ("retry_at" IS NULL OR "retry_at" <= now())
+        AND "attempts" < sqlc.arg(max_attempts)::int
+    ORDER BY "retry_at" NULLS FIRST, "id"
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: FinishRefund :exec
+UPDATE "refund"
+SET "status" = 'refunded', "retry_at" = NULL
+WHERE "id" = $1 AND "status" = 'pending';
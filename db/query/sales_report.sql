@@ -0,0 +1,38 @@
+-- name: GetSupplierSalesSeries :many
+-- the sales are the orders handled, shipped or delivered, before the refunds
+-- of returns, bucketed by the day, week or month of their creation in the
+-- time zone of the supplier
+SELECT (date_trunc(sqlc.arg(bucket)::text, "created_at" AT TIME ZONE sqlc.arg(time_zone)::text) AT TIME ZONE sqlc.arg(time_zone)::text)::timestamptz AS "bucket_start",
+    count(*)::bigint AS "order_count",
+    coalesce(sum("quantity"), 0)::bigint AS "unit_count",
+    coalesce(sum("price" * "quantity" - "discount"), 0)::bigint AS "revenue"
+FROM "order"
+WHERE "supplier_id" = sqlc.arg(supplier_id)
+    AND "status" IN ('handled', 'shipped', 'delivered')
+    AND "created_at" >= sqlc.arg(created_from) AND "created_at" < sqlc.arg(created_to)
+GROUP BY 1
+ORDER BY 1;
+
+-- name: GetSupplierTopProducts :many
+SELECT "product_id",
+    max("product_name")::text AS "product_name",
+    count(*)::bigint AS "order_count",
+    coalesce(sum("quantity"), 0)::bigint AS "unit_count",
+    coalesce(sum("price" * "quantity" - "discount"), 0)::bigint AS "revenue"
+FROM "order"
+WHERE "supplier_id" = sqlc.arg(supplier_id)
+    AND "status" IN ('handled', 'shipped', 'delivered')
+    AND "created_at" >= sqlc.arg(created_from) AND "created_at" < sqlc.arg(created_to)
+GROUP BY "product_id"
+ORDER BY "revenue" DESC, "unit_count" DESC, "product_id"
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetSupplierSalesSummary :one
+SELECT count(*)::bigint AS "order_count",
+    count(*) FILTER (WHERE "status" = 'cancel')::bigint AS "cancelled_count",
+    coalesce(sum("quantity") FILTER (WHERE "status" IN ('handled', 'shipped', 'delivered')), 0)::bigint AS "unit_count",
+    coalesce(sum("price" * "quantity" - "discount") FILTER (WHERE "status" IN ('handled', 'shipped', 'delivered')), 0)::bigint AS "revenue",
+    coalesce(extract(epoch FROM avg("handled_at" - "created_at")), 0)::float8 AS "average_handling_seconds"
+FROM "order"
+WHERE "supplier_id" = sqlc.arg(supplier_id)
+    AND "created_at" >= sqlc.arg(created_from) AND "created_at" < sqlc.arg(created_to);
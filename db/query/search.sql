@@ -0,0 +1,12 @@
+-- name: SearchOrders :many
+SELECT "order".* FROM "order"
+JOIN "order_search" ON "order_search"."order_id" = "order"."id"
+WHERE (sqlc.narg(query)::text IS NULL OR "order_search"."document" @@ to_tsquery('simple', order_search_normalize(sqlc.narg(query)::text)))
+    AND (sqlc.narg(order_number)::text IS NULL OR "order"."order_number" = sqlc.narg(order_number)::text)
+    AND (sqlc.narg(supplier_id)::bigint IS NULL OR "order"."supplier_id" = sqlc.narg(supplier_id)::bigint)
+    AND (sqlc.narg(customer_id)::bigint IS NULL OR "order"."customer_id" = sqlc.narg(customer_id)::bigint)
+    AND (cardinality(sqlc.arg(list_status)::text[]) = 0 OR "order"."status"::text = ANY(sqlc.arg(list_status)::text[]))
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR "order"."created_at" >= sqlc.narg(created_from)::timestamptz)
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR "order"."created_at" < sqlc.narg(created_to)::timestamptz)
+ORDER BY "order"."created_at" DESC, "order"."id" DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
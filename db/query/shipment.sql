@@ -0,0 +1,56 @@
+-- name: CreateShipment :one
+INSERT INTO "shipment" (
+    "supplier_id", "carrier", "tracking_number"
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: CreateShipmentItem :one
+INSERT INTO "shipment_item" (
+    "shipment_id", "order_id", "quantity"
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetShipmentByID :one
+SELECT * FROM "shipment"
+WHERE "id" = $1 LIMIT 1;
+
+-- name: GetShipmentItemByShipment :many
+SELECT * FROM "shipment_item"
+WHERE "shipment_id" = $1;
+
+-- name: GetShipmentByOrder :many
+SELECT DISTINCT "shipment".* FROM "shipment"
+JOIN "shipment_item" ON "shipment_item"."shipment_id" = "shipment"."id"
+WHERE "shipment_item"."order_id" = $1
+ORDER BY "shipment"."id";
+
+-- name: SetShipmentTrackingNumber :one
+-- the carrier is booked after the shipment is saved, a tracking number the
+-- supplier entered meanwhile is kept
+UPDATE "shipment"
+SET "tracking_number" = $2
+WHERE "id" = $1 AND "tracking_number" = ''
+RETURNING *;
+
+-- name: ShipShipment :one
+UPDATE "shipment"
+SET "carrier" = $2, "tracking_number" = $3, "status" = 'shipped', "shipped_at" = COALESCE("shipped_at", now())
+WHERE "id" = $1 AND "status" IN ('pending', 'shipped')
+RETURNING *;
+
+-- name: DeliverShipment :one
+UPDATE "shipment"
+SET "status" = 'delivered', "delivered_at" = now()
+WHERE "id" = $1 AND "status" = 'shipped'
+RETURNING *;
+
+-- name: GetShipmentQuantityByOrder :one
+SELECT
+    COALESCE(SUM("shipment_item"."quantity"), 0)::int AS allocated,
+    COALESCE(SUM("shipment_item"."quantity") FILTER (WHERE "shipment"."status" IN ('shipped', 'delivered')), 0)::int AS shipped,
+    COALESCE(SUM("shipment_item"."quantity") FILTER (WHERE "shipment"."status" = 'delivered'), 0)::int AS delivered
+FROM "shipment_item"
+JOIN "shipment" ON "shipment"."id" = "shipment_item"."shipment_id"
+WHERE "shipment_item"."order_id" = $1;
@@ -0,0 +1,27 @@
+-- name: LockStaleWaitingOrder :many
+SELECT "order".* FROM "order"
+LEFT JOIN "supplier_order_setting" ON "supplier_order_setting"."supplier_id" = "order"."supplier_id"
+WHERE "order"."status" = 'waiting' AND "order"."held_at" IS NULL AND "order"."id" > sqlc.arg(after_id)
+AND "order"."created_at" < now() - make_interval(hours => COALESCE("supplier_order_setting"."handle_deadline_hours", sqlc.arg(default_deadline_hours)::int))
+ORDER BY "order"."id"
+LIMIT sqlc.arg(batch_size)
+FOR UPDATE OF "order" SKIP LOCKED;
+
+-- name: CancelWaitingOrder :one
+UPDATE "order"
+SET "status" = 'cancel', "cancel_reason" = $2, "restock_pending" = true
+WHERE "id" = $1 AND "status" = 'waiting'
+RETURNING *;
+
+-- name: UpsertSupplierOrderSetting :one
+INSERT INTO "supplier_order_setting" (
+    "supplier_id", "handle_deadline_hours"
+) VALUES (
+    $1, $2
+) ON CONFLICT ("supplier_id") DO UPDATE
+SET "handle_deadline_hours" = EXCLUDED."handle_deadline_hours", "updated_at" = now()
+RETURNING *;
+
+-- name: GetSupplierOrderSetting :one
+SELECT * FROM "supplier_order_setting"
+WHERE "supplier_id" = $1 LIMIT 1;
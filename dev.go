@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// devSeed is what the fake services of --dev mode start with. Every user logs
+// in with its token, sent like the real access token in the authorization
+// metadata.
+type devSeed struct {
+	Users    []devUser    `json:"users"`
+	Products []devProduct `json:"products"`
+}
+
+type devUser struct {
+	ID       int64  `json:"id"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+	Role     string `json:"role"`
+}
+
+type devProduct struct {
+	ID         int64  `json:"id"`
+	SupplierID int64  `json:"supplier_id"`
+	Name       string `json:"name"`
+	Price      int64  `json:"price"`
+	Thumbnail  string `json:"thumbnail"`
+	Inventory  int64  `json:"inventory"`
+}
+
+var defaultDevSeed = devSeed{
+	Users: []devUser{
+		{ID: 1, Email: "customer@dev.local", Password: "dev", Token: "customer", Role: "customer"},
+		{ID: 2, Email: "supplier@dev.local", Password: "dev", Token: "supplier", Role: "supplier"},
+		{ID: 3, Email: "admin@dev.local", Password: "dev", Token: "admin", Role: "admin"},
+	},
+	Products: []devProduct{
+		{ID: 1, SupplierID: 2, Name: "Áo thun", Price: 150000, Inventory: 100},
+		{ID: 2, SupplierID: 2, Name: "Quần jean", Price: 350000, Inventory: 50},
+		{ID: 3, SupplierID: 2, Name: "Mũ lưỡi trai", Price: 80000, Inventory: 5},
+	},
+}
+
+// loadDevSeed reads a seed from a json file, the default seed is used when
+// path is empty.
+func loadDevSeed(path string) (devSeed, error) {
+	if path == "" {
+		return defaultDevSeed, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return devSeed{}, err
+	}
+	var seed devSeed
+	if err := json.Unmarshal(data, &seed); err != nil {
+		return devSeed{}, err
+	}
+	return seed, nil
+}
+
+// startDevServices serves fake auth, product and cart services in process
+// over bufconn and returns a connection to them.
+func startDevServices(seed devSeed) (*grpc.ClientConn, func(), error) {
+	auth := newFakeAuthServer(seed.Users)
+	product := newFakeProductServer(seed.Products)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterAuthServiceServer(server, auth)
+	pb.RegisterProductServiceServer(server, product)
+	pb.RegisterCartServiceServer(server, newFakeCartServer(auth, product))
+	go server.Serve(listener)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		server.Stop()
+		return nil, nil, err
+	}
+
+	return conn, func() {
+		conn.Close()
+		server.Stop()
+	}, nil
+}
+
+type fakeAuthServer struct {
+	pb.UnimplementedAuthServiceServer
+	byToken map[string]devUser
+	byEmail map[string]devUser
+}
+
+func newFakeAuthServer(users []devUser) *fakeAuthServer {
+	srv := &fakeAuthServer{
+		byToken: make(map[string]devUser),
+		byEmail: make(map[string]devUser),
+	}
+	for _, user := range users {
+		srv.byToken[user.Token] = user
+		srv.byEmail[user.Email] = user
+	}
+	return srv
+}
+
+// user finds the caller from the authorization metadata, with or without the
+// Bearer prefix.
+func (srv *fakeAuthServer) user(ctx context.Context) (devUser, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		token := strings.TrimSpace(strings.TrimPrefix(value, "Bearer "))
+		if user, ok := srv.byToken[token]; ok {
+			return user, nil
+		}
+	}
+	return devUser{}, status.Error(codes.Unauthenticated, "unauthenticated")
+}
+
+func (srv *fakeAuthServer) claims(ctx context.Context, roles ...pb.UserRole) (*pb.UserClaimsResponse, error) {
+	user, err := srv.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	role := pb.UserRole(pb.UserRole_value[user.Role])
+	if len(roles) > 0 {
+		allowed := false
+		for _, r := range roles {
+			allowed = allowed || r == role
+		}
+		if !allowed {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
+	}
+	return &pb.UserClaimsResponse{
+		Id:       strconv.FormatInt(user.ID, 10),
+		UserRole: role,
+	}, nil
+}
+
+func (srv *fakeAuthServer) Ping(context.Context, *empty.Empty) (*pb.Pong, error) {
+	return &pb.Pong{Message: "pong"}, nil
+}
+
+func (srv *fakeAuthServer) Login(_ context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	user, ok := srv.byEmail[req.GetEmail()]
+	if !ok || user.Password != req.GetPassword() {
+		return nil, status.Error(codes.Unauthenticated, "wrong email or password")
+	}
+	return &pb.LoginResponse{
+		AccessToken:  user.Token,
+		RefreshToken: user.Token,
+		Message:      "ok",
+	}, nil
+}
+
+func (srv *fakeAuthServer) GetUserClaims(ctx context.Context, _ *empty.Empty) (*pb.UserClaimsResponse, error) {
+	return srv.claims(ctx)
+}
+
+func (srv *fakeAuthServer) CustomerAuthorization(ctx context.Context, _ *empty.Empty) (*pb.UserClaimsResponse, error) {
+	return srv.claims(ctx, pb.UserRole_customer)
+}
+
+func (srv *fakeAuthServer) SupplierAuthorization(ctx context.Context, _ *empty.Empty) (*pb.UserClaimsResponse, error) {
+	return srv.claims(ctx, pb.UserRole_supplier)
+}
+
+func (srv *fakeAuthServer) AdminAuthorization(ctx context.Context, _ *empty.Empty) (*pb.UserClaimsResponse, error) {
+	return srv.claims(ctx, pb.UserRole_admin)
+}
+
+type fakeProductServer struct {
+	pb.UnimplementedProductServiceServer
+	mu       sync.Mutex
+	products map[int64]*pb.Product
+	// inventory is kept apart from the product, like in product-service
+	inventory map[int64]int64
+}
+
+func newFakeProductServer(products []devProduct) *fakeProductServer {
+	srv := &fakeProductServer{
+		products:  make(map[int64]*pb.Product),
+		inventory: make(map[int64]int64),
+	}
+	for _, product := range products {
+		srv.products[product.ID] = &pb.Product{
+			ProductId:  product.ID,
+			SupplierId: product.SupplierID,
+			Name:       product.Name,
+			Price:      product.Price,
+			Thumbnail:  product.Thumbnail,
+		}
+		srv.inventory[product.ID] = product.Inventory
+	}
+	return srv
+}
+
+func (srv *fakeProductServer) get(productID int64) (*pb.Product, error) {
+	product, ok := srv.products[productID]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "product %d not found", productID)
+	}
+	result := proto.Clone(product).(*pb.Product)
+	result.Inventory = int32(srv.inventory[productID])
+	return result, nil
+}
+
+func (srv *fakeProductServer) Ping(context.Context, *empty.Empty) (*pb.Pong, error) {
+	return &pb.Pong{Message: "pong"}, nil
+}
+
+func (srv *fakeProductServer) GetProduct(_ context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.get(req.GetProductId())
+}
+
+func (srv *fakeProductServer) GetListProductByIDs(_ context.Context, req *pb.GetListProductByIDsRequest) (*pb.GetListProductResponse, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	result := &pb.GetListProductResponse{}
+	for _, id := range req.GetListId() {
+		if product, err := srv.get(id); err == nil {
+			result.ListProduct = append(result.ListProduct, product)
+		}
+	}
+	return result, nil
+}
+
+func (srv *fakeProductServer) GetProductBySupplier(_ context.Context, req *pb.GetProductBySupplierRequest) (*pb.GetListProductResponse, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	result := &pb.GetListProductResponse{}
+	for id, product := range srv.products {
+		if product.GetSupplierId() == req.GetSupplierId() {
+			product, _ := srv.get(id)
+			result.ListProduct = append(result.ListProduct, product)
+		}
+	}
+	return result, nil
+}
+
+func (srv *fakeProductServer) GetListProductInventory(_ context.Context, req *pb.GetInventoryRequest) (*pb.GetInventoryResponse, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if _, ok := srv.products[req.GetProductId()]; !ok {
+		return nil, status.Errorf(codes.NotFound, "product %d not found", req.GetProductId())
+	}
+	return &pb.GetInventoryResponse{Count: srv.inventory[req.GetProductId()]}, nil
+}
+
+func (srv *fakeProductServer) DescInventory(_ context.Context, req *pb.DescInventoryRequest) (*pb.DescInventoryResponse, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if _, ok := srv.products[req.GetProductId()]; !ok {
+		return nil, status.Errorf(codes.NotFound, "product %d not found", req.GetProductId())
+	}
+	if srv.inventory[req.GetProductId()] < int64(req.GetCount()) {
+		return nil, status.Error(codes.FailedPrecondition, "Sản phẩm trong kho không đủ")
+	}
+	srv.inventory[req.GetProductId()] -= int64(req.GetCount())
+	return &pb.DescInventoryResponse{Message: "ok"}, nil
+}
+
+func (srv *fakeProductServer) IncInventory(_ context.Context, req *pb.IncInventoryRequest) (*pb.IncInventoryResponse, error) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if _, ok := srv.products[req.GetProductId()]; !ok {
+		return nil, status.Errorf(codes.NotFound, "product %d not found", req.GetProductId())
+	}
+	srv.inventory[req.GetProductId()] += int64(req.GetCount())
+	return &pb.IncInventoryResponse{Message: "ok"}, nil
+}
+
+type fakeCartItem struct {
+	id         int64
+	customerID int64
+	productID  int64
+	quantity   int32
+}
+
+type fakeCartServer struct {
+	pb.UnimplementedCartServiceServer
+	auth    *fakeAuthServer
+	product *fakeProductServer
+	mu      sync.Mutex
+	next    int64
+	items   map[int64]fakeCartItem
+}
+
+func newFakeCartServer(auth *fakeAuthServer, product *fakeProductServer) *fakeCartServer {
+	return &fakeCartServer{
+		auth:    auth,
+		product: product,
+		items:   make(map[int64]fakeCartItem),
+	}
+}
+
+func (srv *fakeCartServer) Ping(context.Context, *empty.Empty) (*pb.Pong, error) {
+	return &pb.Pong{Message: "pong"}, nil
+}
+
+func (srv *fakeCartServer) CreateCart(ctx context.Context, req *pb.CreateCartRequest) (*pb.CreateCartResponse, error) {
+	user, err := srv.auth.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := srv.product.GetProduct(ctx, &pb.GetProductRequest{ProductId: req.GetProductId()}); err != nil {
+		return nil, err
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.next++
+	srv.items[srv.next] = fakeCartItem{
+		id:         srv.next,
+		customerID: user.ID,
+		productID:  req.GetProductId(),
+		quantity:   req.GetQuantity(),
+	}
+	return &pb.CreateCartResponse{Message: "ok"}, nil
+}
+
+func (srv *fakeCartServer) DeleteCart(ctx context.Context, req *pb.DeleteCartRequest) (*pb.DeleteCartResponse, error) {
+	user, err := srv.auth.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	item, ok := srv.items[req.GetCartId()]
+	if !ok || item.customerID != user.ID {
+		return nil, status.Errorf(codes.NotFound, "cart %d not found", req.GetCartId())
+	}
+	delete(srv.items, item.id)
+	return &pb.DeleteCartResponse{Message: "ok"}, nil
+}
+
+func (srv *fakeCartServer) GetCartByCustomer(ctx context.Context, _ *pb.GetCartByCustomerRequest) (*pb.GetCartByCustomerResponse, error) {
+	user, err := srv.auth.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	result := &pb.GetCartByCustomerResponse{}
+	for _, item := range srv.items {
+		if item.customerID != user.ID {
+			continue
+		}
+		product, err := srv.product.GetProduct(ctx, &pb.GetProductRequest{ProductId: item.productID})
+		if err != nil {
+			continue
+		}
+		result.ListCart = append(result.ListCart, &pb.GetCartByCustomerResponse_Cart{
+			Id:       item.id,
+			Product:  product,
+			Quantity: item.quantity,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/orderstatus"
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	exportBatchSize = 500
+	exportChunkSize = 32 * 1024
+)
+
+// exportTimeout bounds an export, the transaction of its snapshot is not held
+// open by a client that reads slowly or not at all.
+var exportTimeout = 10 * time.Minute
+
+// the columns of a CSV export, in the order of exportOrder.record
+var exportHeader = []string{
+	"order_id", "order_number", "created_at", "updated_at", "handled_at", "cancelled_at",
+	"status", "payment_status", "customer_id", "supplier_id", "product_id", "product_name",
+	"price", "quantity", "discount", "shipping_fee", "total", "cancel_reason",
+	"address_name", "address_phone", "address_detail",
+}
+
+// exportOrder is an order as it is exported, the product is the snapshot of
+// the checkout.
+type exportOrder struct {
+	OrderID       int64      `json:"order_id"`
+	OrderNumber   string     `json:"order_number"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	HandledAt     *time.Time `json:"handled_at"`
+	CancelledAt   *time.Time `json:"cancelled_at"`
+	Status        string     `json:"status"`
+	PaymentStatus string     `json:"payment_status"`
+	CustomerID    int64      `json:"customer_id"`
+	SupplierID    int64      `json:"supplier_id"`
+	ProductID     int64      `json:"product_id"`
+	ProductName   string     `json:"product_name"`
+	Price         int64      `json:"price"`
+	Quantity      int32      `json:"quantity"`
+	Discount      int64      `json:"discount"`
+	ShippingFee   int64      `json:"shipping_fee"`
+	Total         int64      `json:"total"`
+	CancelReason  string     `json:"cancel_reason"`
+	AddressName   string     `json:"address_name"`
+	AddressPhone  string     `json:"address_phone"`
+	AddressDetail string     `json:"address_detail"`
+}
+
+// newExportOrder converts a row, the times are those of Vietnam with their
+// offset.
+func newExportOrder(row repository.GetOrdersForExportRow, loc *time.Location) exportOrder {
+	nullTime := func(t sql.NullTime) *time.Time {
+		if !t.Valid {
+			return nil
+		}
+		local := t.Time.In(loc)
+		return &local
+	}
+	var status string
+	if row.Status.Valid {
+		status = string(row.Status.OrderStatusEnum)
+	}
+	return exportOrder{
+		OrderID:       row.ID,
+		OrderNumber:   row.OrderNumber,
+		CreatedAt:     row.CreatedAt.In(loc),
+		UpdatedAt:     row.UpdatedAt.In(loc),
+		HandledAt:     nullTime(row.HandledAt),
+		CancelledAt:   nullTime(row.CancelledAt),
+		Status:        status,
+		PaymentStatus: string(row.PaymentStatus),
+		CustomerID:    row.CustomerID,
+		SupplierID:    row.SupplierID,
+		ProductID:     row.ProductID,
+		ProductName:   row.ProductName,
+		Price:         row.Price,
+		Quantity:      row.Quantity,
+		Discount:      row.Discount,
+		ShippingFee:   row.ShippingFee,
+		Total:         row.Price*int64(row.Quantity) - row.Discount + row.ShippingFee,
+		CancelReason:  row.CancelReason,
+		AddressName:   row.AddressName,
+		AddressPhone:  row.AddressPhone,
+		AddressDetail: row.AddressDetail,
+	}
+}
+
+func (order exportOrder) record() []string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	return []string{
+		strconv.FormatInt(order.OrderID, 10),
+		order.OrderNumber,
+		formatTime(&order.CreatedAt),
+		formatTime(&order.UpdatedAt),
+		formatTime(order.HandledAt),
+		formatTime(order.CancelledAt),
+		order.Status,
+		order.PaymentStatus,
+		strconv.FormatInt(order.CustomerID, 10),
+		strconv.FormatInt(order.SupplierID, 10),
+		strconv.FormatInt(order.ProductID, 10),
+		order.ProductName,
+		strconv.FormatInt(order.Price, 10),
+		strconv.FormatInt(int64(order.Quantity), 10),
+		strconv.FormatInt(order.Discount, 10),
+		strconv.FormatInt(order.ShippingFee, 10),
+		strconv.FormatInt(order.Total, 10),
+		order.CancelReason,
+		order.AddressName,
+		order.AddressPhone,
+		order.AddressDetail,
+	}
+}
+
+// exportWriter collects what is written into chunks of about exportChunkSize
+// bytes sent on the stream.
+type exportWriter struct {
+	stream pb.OrderService_ExportOrdersServer
+	buf    bytes.Buffer
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+// flush sends the collected bytes once there are enough of them, or all of
+// them with force.
+func (w *exportWriter) flush(force bool) error {
+	if w.buf.Len() == 0 || (!force && w.buf.Len() < exportChunkSize) {
+		return nil
+	}
+	// Send marshals the chunk before returning, the buffer can be reused
+	err := w.stream.Send(&pb.ExportOrdersChunk{Data: w.buf.Bytes()})
+	w.buf.Reset()
+	return err
+}
+
+// ExportOrders streams the orders of the calling customer or supplier as CSV
+// or NDJSON. The orders are read in batches by id, a large supplier is never
+// held in memory, and all the batches see the orders as they were when the
+// export started. An export still running after exportTimeout is stopped.
+func (srv orderService) ExportOrders(req *pb.ExportOrdersRequest, stream pb.OrderService_ExportOrdersServer) error {
+	ctx := stream.Context()
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return err
+	}
+	userID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	arg := repository.GetOrdersForExportParams{
+		ListStatus: []string{},
+		RowLimit:   exportBatchSize,
+	}
+	switch claims.GetUserRole() {
+	case pb.UserRole_customer:
+		arg.CustomerID = sql.NullInt64{Int64: userID, Valid: true}
+	case pb.UserRole_supplier:
+		arg.SupplierID = sql.NullInt64{Int64: userID, Valid: true}
+	default:
+		return errors.New("Unauthorization")
+	}
+
+	for _, pbStatus := range req.GetListStatus() {
+		status, err := orderstatus.FromPb(pbStatus)
+		if err != nil {
+			log.Println(err)
+			return errors.New("Trạng thái đơn hàng không hợp lệ")
+		}
+		arg.ListStatus = append(arg.ListStatus, string(status))
+	}
+	if req.GetCreatedFrom() != nil {
+		arg.CreatedFrom = sql.NullTime{Time: req.GetCreatedFrom().AsTime(), Valid: true}
+	}
+	if req.GetCreatedTo() != nil {
+		arg.CreatedTo = sql.NullTime{Time: req.GetCreatedTo().AsTime(), Valid: true}
+	}
+
+	format := req.GetFormat()
+	if format != pb.ExportFormat_export_csv && format != pb.ExportFormat_export_ndjson {
+		return errors.New("Định dạng xuất không hợp lệ")
+	}
+	loc, err := time.LoadLocation(defaultReportTimeZone)
+	if err != nil {
+		return err
+	}
+
+	w := &exportWriter{stream: stream}
+	csvWriter := csv.NewWriter(w)
+	jsonEncoder := json.NewEncoder(w)
+	if format == pb.ExportFormat_export_csv {
+		// the byte order mark makes spreadsheets read the Vietnamese as UTF-8
+		w.buf.WriteString("\ufeff")
+		if err := csvWriter.Write(exportHeader); err != nil {
+			return err
+		}
+	}
+
+	// the batches are read in one read-only repeatable read transaction, the
+	// export is a single snapshot even when orders change while it is sent.
+	// The transaction is rolled back at the deadline even while a send waits
+	// for the client
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	return srv.orderStore.ExecTx(ctx, opts, func(qtx repository.Querier) error {
+		for {
+			if err := ctx.Err(); err != nil {
+				log.Println("export stopped: ", err)
+				return errors.New("Xuất đơn hàng không thành công, quá thời gian cho phép")
+			}
+			listRow, err := qtx.GetOrdersForExport(ctx, arg)
+			if err != nil {
+				log.Println(err)
+				return errors.New("Xuất đơn hàng không thành công")
+			}
+			for _, row := range listRow {
+				order := newExportOrder(row, loc)
+				if format == pb.ExportFormat_export_csv {
+					err = csvWriter.Write(order.record())
+				} else {
+					err = jsonEncoder.Encode(order)
+				}
+				if err != nil {
+					return err
+				}
+				csvWriter.Flush()
+				if err := csvWriter.Error(); err != nil {
+					return err
+				}
+				if err := w.flush(false); err != nil {
+					return err
+				}
+			}
+
+			if len(listRow) < exportBatchSize {
+				csvWriter.Flush()
+				if err := csvWriter.Error(); err != nil {
+					return err
+				}
+				return w.flush(true)
+			}
+			arg.AfterID = listRow[len(listRow)-1].ID
+		}
+	})
+}
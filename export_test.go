@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"google.golang.org/grpc"
+)
+
+// exportStream collects the chunks of an export, onSend is called after
+// every chunk.
+type exportStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	data   bytes.Buffer
+	onSend func()
+}
+
+func (s *exportStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *exportStream) Send(chunk *pb.ExportOrdersChunk) error {
+	s.data.Write(chunk.GetData())
+	if s.onSend != nil {
+		s.onSend()
+	}
+	return nil
+}
+
+func TestExportOrdersSnapshot(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+		// more than a batch, so the last order is read after the first chunk
+		// is sent
+		var last repository.Order
+		for i := 0; i < exportBatchSize+10; i++ {
+			last = testOrder(t, store, nil)
+		}
+
+		// the last order is cancelled while the export is being sent, a store
+		// that locks the orders for the export makes the cancel wait
+		done := make(chan error, 1)
+		stream := &exportStream{ctx: userContext("customer")}
+		stream.onSend = func() {
+			stream.onSend = nil
+			go func() {
+				_, err := store.CancelOrder(ctx, last.ID)
+				done <- err
+			}()
+			select {
+			case err := <-done:
+				done <- err
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+		err := srv.ExportOrders(&pb.ExportOrdersRequest{Format: pb.ExportFormat_export_ndjson}, stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+
+		var n int
+		scanner := bufio.NewScanner(&stream.data)
+		for scanner.Scan() {
+			var order exportOrder
+			if err := json.Unmarshal(scanner.Bytes(), &order); err != nil {
+				t.Fatal(err)
+			}
+			n++
+			if order.OrderID == last.ID && order.Status != string(repository.OrderStatusEnumWaiting) {
+				t.Errorf("the last order is exported %s, want it as it was when the export started", order.Status)
+			}
+		}
+		if n != exportBatchSize+10 {
+			t.Errorf("exported %d orders, want %d", n, exportBatchSize+10)
+		}
+	})
+}
+
+func TestExportOrdersTimeout(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		srv := newTestService(t, store)
+		for i := 0; i < exportBatchSize+10; i++ {
+			testOrder(t, store, nil)
+		}
+		defer func(timeout time.Duration) { exportTimeout = timeout }(exportTimeout)
+		exportTimeout = 50 * time.Millisecond
+
+		// the client reads the first chunk after the deadline
+		stream := &exportStream{ctx: userContext("customer")}
+		stream.onSend = func() {
+			stream.onSend = nil
+			time.Sleep(100 * time.Millisecond)
+		}
+		err := srv.ExportOrders(&pb.ExportOrdersRequest{Format: pb.ExportFormat_export_ndjson}, stream)
+		if err == nil {
+			t.Fatal("the export ran past its deadline")
+		}
+	})
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// invoices are dated, and their year counted, in Vietnam
+	invoiceTimeZone = "Asia/Ho_Chi_Minh"
+	// the VAT of a supplier which hasn't set its own
+	defaultVatPercent = 10
+)
+
+func (srv orderService) SetSupplierInvoiceSetting(ctx context.Context, req *pb.SupplierInvoiceSetting) (*pb.SupplierInvoiceSetting, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	supplierID := req.GetSupplierId()
+	switch claims.GetUserRole() {
+	case pb.UserRole_admin:
+		if supplierID == 0 {
+			return nil, errors.New("Vui lòng chọn cửa hàng")
+		}
+	case pb.UserRole_supplier:
+		if supplierID != 0 && supplierID != userID {
+			return nil, errors.New("Cập nhật không thành công, unauthorization")
+		}
+		supplierID = userID
+	default:
+		return nil, errors.New("Cập nhật không thành công, unauthorization")
+	}
+
+	legalName := strings.TrimSpace(req.GetLegalName())
+	taxCode := strings.TrimSpace(req.GetTaxCode())
+	address := strings.TrimSpace(req.GetAddress())
+	if legalName == "" || taxCode == "" || address == "" {
+		return nil, errors.New("Vui lòng nhập đầy đủ tên, mã số thuế và địa chỉ")
+	}
+	if req.GetVatPercent() < 0 || req.GetVatPercent() > 100 {
+		return nil, errors.New("Thuế suất không hợp lệ")
+	}
+
+	setting, err := srv.orderStore.UpsertSupplierInvoiceSetting(ctx, repository.UpsertSupplierInvoiceSettingParams{
+		SupplierID: supplierID,
+		LegalName:  legalName,
+		TaxCode:    taxCode,
+		Address:    address,
+		VatPercent: req.GetVatPercent(),
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Cập nhật không thành công")
+	}
+
+	return &pb.SupplierInvoiceSetting{
+		SupplierId: setting.SupplierID,
+		LegalName:  setting.LegalName,
+		TaxCode:    setting.TaxCode,
+		Address:    setting.Address,
+		VatPercent: setting.VatPercent,
+	}, nil
+}
+
+// issueInvoice writes the invoice of an order which was just delivered, in
+// the transaction of q. The number is taken in the same transaction, an
+// invoice rolled back gives its number back. An order has one invoice, a
+// second call does nothing.
+func issueInvoice(ctx context.Context, q repository.Querier, order repository.Order) error {
+	_, err := q.GetInvoiceByOrder(ctx, order.ID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	setting, err := q.GetSupplierInvoiceSetting(ctx, order.SupplierID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// the seller is known by its id until it sets its details
+		setting = repository.SupplierInvoiceSetting{
+			SupplierID: order.SupplierID,
+			LegalName:  fmt.Sprintf("Cửa hàng #%d", order.SupplierID),
+			VatPercent: defaultVatPercent,
+		}
+	} else if err != nil {
+		return err
+	}
+	addr, err := q.GetAddressById(ctx, order.AddressID)
+	if err != nil {
+		return err
+	}
+
+	loc, err := time.LoadLocation(invoiceTimeZone)
+	if err != nil {
+		return err
+	}
+	issuedAt := time.Now().In(loc)
+	number, err := q.NextInvoiceNumber(ctx, repository.NextInvoiceNumberParams{
+		SupplierID: order.SupplierID,
+		Year:       int32(issuedAt.Year()),
+	})
+	if err != nil {
+		return err
+	}
+
+	listLine := invoiceLines(order, setting.VatPercent)
+	var total, tax int64
+	for _, line := range listLine {
+		total += line.Amount
+		tax += line.Tax
+	}
+
+	invoice, err := q.CreateInvoice(ctx, repository.CreateInvoiceParams{
+		OrderID:       order.ID,
+		SupplierID:    order.SupplierID,
+		CustomerID:    order.CustomerID,
+		Year:          int32(issuedAt.Year()),
+		Number:        number,
+		IssuedAt:      issuedAt,
+		SellerName:    setting.LegalName,
+		SellerTaxCode: setting.TaxCode,
+		SellerAddress: setting.Address,
+		BuyerName:     addr.Name,
+		BuyerPhone:    addr.Phone,
+		BuyerAddress:  addr.Detail,
+		Subtotal:      total - tax,
+		Tax:           tax,
+		Total:         total,
+	})
+	if err != nil {
+		return err
+	}
+	for _, line := range listLine {
+		line.InvoiceID = invoice.ID
+		if _, err := q.CreateInvoiceLine(ctx, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// invoiceLines are the product of the order, its discount and its shipping
+// fee. The prices of the marketplace include the VAT, the tax of a line is
+// the share of its amount.
+func invoiceLines(order repository.Order, vatPercent int32) []repository.CreateInvoiceLineParams {
+	description := order.ProductName
+	if description == "" {
+		description = fmt.Sprintf("Sản phẩm #%d", order.ProductID)
+	}
+	amount := order.Price * int64(order.Quantity)
+	listLine := []repository.CreateInvoiceLineParams{{
+		Description: description,
+		Quantity:    order.Quantity,
+		UnitPrice:   order.Price,
+		Amount:      amount,
+		VatPercent:  vatPercent,
+		Tax:         includedTax(amount, vatPercent),
+	}}
+	if order.Discount > 0 {
+		listLine = append(listLine, repository.CreateInvoiceLineParams{
+			Description: "Giảm giá",
+			Quantity:    1,
+			UnitPrice:   -order.Discount,
+			Amount:      -order.Discount,
+			VatPercent:  vatPercent,
+			Tax:         includedTax(-order.Discount, vatPercent),
+		})
+	}
+	if order.ShippingFee > 0 {
+		listLine = append(listLine, repository.CreateInvoiceLineParams{
+			Description: "Phí vận chuyển",
+			Quantity:    1,
+			UnitPrice:   order.ShippingFee,
+			Amount:      order.ShippingFee,
+			VatPercent:  vatPercent,
+			Tax:         includedTax(order.ShippingFee, vatPercent),
+		})
+	}
+	return listLine
+}
+
+// includedTax is the VAT included in amount, rounded half away from zero to
+// the dong.
+func includedTax(amount int64, vatPercent int32) int64 {
+	if vatPercent <= 0 {
+		return 0
+	}
+	numerator := amount * int64(vatPercent) * 2
+	denominator := int64(100+vatPercent) * 2
+	if numerator < 0 {
+		return -((-numerator + denominator/2) / denominator)
+	}
+	return (numerator + denominator/2) / denominator
+}
+
+// invoiceNumber is the number printed on an invoice, it is unique for the
+// supplier.
+func invoiceNumber(invoice repository.Invoice) string {
+	return fmt.Sprintf("HD%d-%07d", invoice.Year, invoice.Number)
+}
+
+// GetInvoice returns the invoice of a delivered order, rendered as PDF or
+// HTML, to its customer, its supplier or an admin.
+func (srv orderService) GetInvoice(ctx context.Context, req *pb.GetInvoiceRequest) (*pb.GetInvoiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	invoice, err := srv.orderStore.GetInvoiceByOrder(ctx, req.GetOrderId())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("Đơn hàng chưa có hóa đơn")
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy hóa đơn")
+	}
+	if !(userID == invoice.CustomerID || userID == invoice.SupplierID || claims.GetUserRole() == pb.UserRole_admin) {
+		return nil, errors.New("Unauthorization")
+	}
+
+	pbInvoice, err := srv.getPbInvoice(ctx, invoice)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy hóa đơn")
+	}
+
+	response := &pb.GetInvoiceResponse{
+		Invoice: pbInvoice,
+	}
+	switch req.GetFormat() {
+	case pb.InvoiceFormat_invoice_pdf:
+		response.Document, err = renderInvoicePDF(pbInvoice)
+		response.ContentType = "application/pdf"
+		response.FileName = pbInvoice.GetInvoiceNumber() + ".pdf"
+	case pb.InvoiceFormat_invoice_html:
+		response.Document, err = renderInvoiceHTML(pbInvoice)
+		response.ContentType = "text/html; charset=utf-8"
+		response.FileName = pbInvoice.GetInvoiceNumber() + ".html"
+	default:
+		return nil, errors.New("Định dạng hóa đơn không hợp lệ")
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể tạo hóa đơn")
+	}
+
+	return response, nil
+}
+
+func (srv orderService) getPbInvoice(ctx context.Context, invoice repository.Invoice) (*pb.Invoice, error) {
+	listLine, err := srv.orderStore.GetInvoiceLineByInvoice(ctx, invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+	m, err := srv.orderNumbers(ctx, []int64{invoice.OrderID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &pb.Invoice{
+		InvoiceId:     invoice.ID,
+		InvoiceNumber: invoiceNumber(invoice),
+		OrderId:       invoice.OrderID,
+		OrderNumber:   m[invoice.OrderID],
+		IssuedAt:      timestamppb.New(invoice.IssuedAt),
+		SupplierId:    invoice.SupplierID,
+		CustomerId:    invoice.CustomerID,
+		SellerName:    invoice.SellerName,
+		SellerTaxCode: invoice.SellerTaxCode,
+		SellerAddress: invoice.SellerAddress,
+		BuyerName:     invoice.BuyerName,
+		BuyerPhone:    invoice.BuyerPhone,
+		BuyerAddress:  invoice.BuyerAddress,
+		Subtotal:      invoice.Subtotal,
+		Tax:           invoice.Tax,
+		Total:         invoice.Total,
+	}
+	// the tax breakdown sums the lines of every rate, in the order they appear
+	taxByPercent := make(map[int32]*pb.InvoiceTax)
+	for _, line := range listLine {
+		result.ListLine = append(result.ListLine, &pb.InvoiceLine{
+			Description: line.Description,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice,
+			Amount:      line.Amount,
+			VatPercent:  line.VatPercent,
+			Tax:         line.Tax,
+		})
+		tax, ok := taxByPercent[line.VatPercent]
+		if !ok {
+			tax = &pb.InvoiceTax{VatPercent: line.VatPercent}
+			taxByPercent[line.VatPercent] = tax
+			result.ListTax = append(result.ListTax, tax)
+		}
+		tax.TaxableAmount += line.Amount - line.Tax
+		tax.Tax += line.Tax
+	}
+	return result, nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strconv"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/go-pdf/fpdf"
+)
+
+// the PDF fonts have to cover Vietnamese, the fonts of fpdf don't
+var (
+	//go:embed fonts/DejaVuSans.ttf
+	invoiceFont []byte
+	//go:embed fonts/DejaVuSans-Bold.ttf
+	invoiceBoldFont []byte
+	//go:embed templates/invoice.html
+	invoiceHTML string
+)
+
+var invoiceTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"money": formatMoney,
+	"inc": func(i int) int {
+		return i + 1
+	},
+}).Parse(invoiceHTML))
+
+// invoiceView is what the template shows, the date as it is printed.
+type invoiceView struct {
+	*pb.Invoice
+	IssuedAt string
+}
+
+func newInvoiceView(invoice *pb.Invoice) (invoiceView, error) {
+	loc, err := time.LoadLocation(invoiceTimeZone)
+	if err != nil {
+		return invoiceView{}, err
+	}
+	return invoiceView{
+		Invoice:  invoice,
+		IssuedAt: invoice.GetIssuedAt().AsTime().In(loc).Format("02/01/2006"),
+	}, nil
+}
+
+// formatMoney writes an amount of dong the Vietnamese way, 1.250.000 đ.
+func formatMoney(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+	var buf bytes.Buffer
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			buf.WriteByte('.')
+		}
+		buf.WriteRune(digit)
+	}
+	return sign + buf.String() + " đ"
+}
+
+func renderInvoiceHTML(invoice *pb.Invoice) ([]byte, error) {
+	view, err := newInvoiceView(invoice)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := invoiceTemplate.Execute(&buf, view); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderInvoicePDF lays the invoice out on A4 like the HTML version.
+func renderInvoicePDF(invoice *pb.Invoice) ([]byte, error) {
+	view, err := newInvoiceView(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes("DejaVu", "", invoiceFont)
+	pdf.AddUTF8FontFromBytes("DejaVu", "B", invoiceBoldFont)
+	pdf.SetTitle("Hóa đơn "+invoice.GetInvoiceNumber(), true)
+	pdf.SetAuthor(invoice.GetSellerName(), true)
+	// the same invoice always gives the same document
+	pdf.SetCreationDate(invoice.GetIssuedAt().AsTime())
+	pdf.SetMargins(10, 15, 10)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	pdf.SetFont("DejaVu", "B", 16)
+	pdf.CellFormat(0, 10, "HÓA ĐƠN GIÁ TRỊ GIA TĂNG", "", 1, "C", false, 0, "")
+	pdf.SetFont("DejaVu", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Số: %s · Ngày: %s · Đơn hàng: %s", view.GetInvoiceNumber(), view.IssuedAt, view.GetOrderNumber()), "", 1, "C", false, 0, "")
+	pdf.Ln(6)
+
+	party := func(label, value string) {
+		pdf.SetFont("DejaVu", "B", 10)
+		width := pdf.GetStringWidth(label) + 2
+		pdf.CellFormat(width, 6, label, "", 0, "L", false, 0, "")
+		pdf.SetFont("DejaVu", "", 10)
+		pdf.MultiCell(0, 6, value, "", "L", false)
+	}
+	party("Đơn vị bán hàng:", invoice.GetSellerName())
+	party("Mã số thuế:", invoice.GetSellerTaxCode())
+	party("Địa chỉ:", invoice.GetSellerAddress())
+	pdf.Ln(4)
+	party("Người mua hàng:", invoice.GetBuyerName())
+	party("Điện thoại:", invoice.GetBuyerPhone())
+	party("Địa chỉ:", invoice.GetBuyerAddress())
+	pdf.Ln(6)
+
+	// the lines, the description wraps and sets the height of its row
+	widths := []float64{10, 68, 16, 28, 28, 16, 24}
+	header := []string{"STT", "Tên hàng hóa, dịch vụ", "Số lượng", "Đơn giá", "Thành tiền", "Thuế suất", "Tiền thuế"}
+	pdf.SetFont("DejaVu", "B", 9)
+	pdf.SetFillColor(238, 238, 238)
+	for i, title := range header {
+		pdf.CellFormat(widths[i], 7, title, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("DejaVu", "", 9)
+	for i, line := range invoice.GetListLine() {
+		listText := pdf.SplitText(line.GetDescription(), widths[1])
+		height := 6 * float64(len(listText))
+		if height == 0 {
+			height = 6
+		}
+		cells := []string{
+			strconv.Itoa(i + 1),
+			"",
+			strconv.Itoa(int(line.GetQuantity())),
+			formatMoney(line.GetUnitPrice()),
+			formatMoney(line.GetAmount()),
+			fmt.Sprintf("%d%%", line.GetVatPercent()),
+			formatMoney(line.GetTax()),
+		}
+		x, y := pdf.GetXY()
+		for j, cell := range cells {
+			if j == 1 {
+				pdf.MultiCell(widths[j], 6, line.GetDescription(), "", "L", false)
+				pdf.Rect(x, y, widths[j], height, "D")
+				pdf.SetXY(x+widths[j], y)
+			} else {
+				pdf.CellFormat(widths[j], height, cell, "1", 0, "R", false, 0, "")
+			}
+			x += widths[j]
+		}
+		pdf.Ln(-1)
+	}
+	pdf.Ln(6)
+
+	// the tax breakdown
+	taxWidths := []float64{30, 50, 50}
+	pdf.SetFont("DejaVu", "B", 9)
+	for i, title := range []string{"Thuế suất", "Tiền hàng chưa thuế", "Tiền thuế GTGT"} {
+		pdf.CellFormat(taxWidths[i], 7, title, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("DejaVu", "", 9)
+	for _, tax := range invoice.GetListTax() {
+		pdf.CellFormat(taxWidths[0], 6, fmt.Sprintf("%d%%", tax.GetVatPercent()), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(taxWidths[1], 6, formatMoney(tax.GetTaxableAmount()), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(taxWidths[2], 6, formatMoney(tax.GetTax()), "1", 1, "R", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	total := func(label string, amount int64) {
+		pdf.CellFormat(140, 7, label, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(50, 7, formatMoney(amount), "1", 1, "R", false, 0, "")
+	}
+	total("Cộng tiền hàng", invoice.GetSubtotal())
+	total("Tiền thuế GTGT", invoice.GetTax())
+	pdf.SetFont("DejaVu", "B", 9)
+	total("Tổng cộng thanh toán", invoice.GetTotal())
+	pdf.Ln(4)
+	pdf.SetFont("DejaVu", "", 9)
+	pdf.CellFormat(0, 6, "Giá bán đã bao gồm thuế GTGT.", "", 1, "L", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+func TestIncludedTax(t *testing.T) {
+	for _, tc := range []struct {
+		amount     int64
+		vatPercent int32
+		want       int64
+	}{
+		{110000, 10, 10000},
+		{-110000, 10, -10000},
+		{110000, 0, 0},
+		{110000, -5, 0},
+		// 30000 / 11 is 2727.27
+		{30000, 10, 2727},
+		{-30000, 10, -2727},
+		// 100 / 11 is 9.09, 160 / 11 is 14.55
+		{100, 10, 9},
+		{160, 10, 15},
+		{-160, 10, -15},
+		// the halves are rounded away from zero
+		{3, 100, 2},
+		{-3, 100, -2},
+		{1, 100, 1},
+		{-1, 100, -1},
+		{108000, 8, 8000},
+	} {
+		if got := includedTax(tc.amount, tc.vatPercent); got != tc.want {
+			t.Errorf("includedTax(%d, %d) = %d, want %d", tc.amount, tc.vatPercent, got, tc.want)
+		}
+	}
+}
+
+func TestInvoiceLines(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		order repository.Order
+		want  []repository.CreateInvoiceLineParams
+	}{
+		{
+			name:  "product only",
+			order: repository.Order{ProductID: 10, Quantity: 2, Price: 55000},
+			want: []repository.CreateInvoiceLineParams{
+				{Description: "Sản phẩm #10", Quantity: 2, UnitPrice: 55000, Amount: 110000, VatPercent: 10, Tax: 10000},
+			},
+		},
+		{
+			name:  "discount and shipping",
+			order: repository.Order{ProductName: "Áo thun", Quantity: 2, Price: 150000, Discount: 16000, ShippingFee: 30000},
+			want: []repository.CreateInvoiceLineParams{
+				{Description: "Áo thun", Quantity: 2, UnitPrice: 150000, Amount: 300000, VatPercent: 10, Tax: 27273},
+				{Description: "Giảm giá", Quantity: 1, UnitPrice: -16000, Amount: -16000, VatPercent: 10, Tax: -1455},
+				{Description: "Phí vận chuyển", Quantity: 1, UnitPrice: 30000, Amount: 30000, VatPercent: 10, Tax: 2727},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			listLine := invoiceLines(tc.order, 10)
+			if len(listLine) != len(tc.want) {
+				t.Fatalf("invoiceLines = %+v, want %+v", listLine, tc.want)
+			}
+			var total int64
+			for i, line := range listLine {
+				if line != tc.want[i] {
+					t.Errorf("line %d is %+v, want %+v", i, line, tc.want[i])
+				}
+				total += line.Amount
+			}
+			// the invoice adds up to what the customer pays
+			if total != orderAmount(tc.order) {
+				t.Errorf("the lines add up to %d, the order costs %d", total, orderAmount(tc.order))
+			}
+		})
+	}
+}
+
+func TestIssueInvoice(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		issue := func(order repository.Order) (repository.Invoice, error) {
+			var invoice repository.Invoice
+			err := store.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+				if err := issueInvoice(ctx, qtx, order); err != nil {
+					return err
+				}
+				var err error
+				invoice, err = qtx.GetInvoiceByOrder(ctx, order.ID)
+				return err
+			})
+			return invoice, err
+		}
+
+		first := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.Discount = 20000 })
+		invoice, err := issue(first)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if invoice.Number != 1 || invoice.Total != orderAmount(first) || invoice.Subtotal+invoice.Tax != invoice.Total {
+			t.Fatalf("the first invoice is %+v", invoice)
+		}
+		listLine, err := store.GetInvoiceLineByInvoice(ctx, invoice.ID)
+		if err != nil || len(listLine) != 2 || listLine[1].Amount != -20000 {
+			t.Fatalf("the lines of the first invoice are %+v, %v", listLine, err)
+		}
+
+		// an order has one invoice
+		again, err := issue(first)
+		if err != nil || again.ID != invoice.ID {
+			t.Fatalf("the second invoice of an order is %+v, %v", again, err)
+		}
+
+		// a rolled back invoice gives its number back
+		errRollback := errors.New("rollback")
+		rolledBack := testOrder(t, store, nil)
+		err = store.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+			if err := issueInvoice(ctx, qtx, rolledBack); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Fatal(err)
+		}
+		if _, err := store.GetInvoiceByOrder(ctx, rolledBack.ID); err == nil {
+			t.Fatal("the rolled back invoice was saved")
+		}
+
+		next, err := issue(testOrder(t, store, nil))
+		if err != nil || next.Number != 2 {
+			t.Fatalf("the invoice after the rollback is %+v, %v, want number 2", next, err)
+		}
+	})
+}
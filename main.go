@@ -1,16 +1,18 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"log"
 	"net"
 	"os"
 	"runtime"
+	"strconv"
 	"time"
 
 	"github.com/e-commerce-microservices/order-service/pb"
-	"github.com/e-commerce-microservices/order-service/repository"
 	"github.com/joho/godotenv"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/otel"
@@ -29,11 +31,12 @@ import (
 	_ "github.com/lib/pq"
 )
 
-func init() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal(err)
-	}
+var (
+	devMode     = flag.Bool("dev", false, "run with in-process fake auth, product and cart services, orders in memory and without jaeger or postgres")
+	devSeedPath = flag.String("dev-seed", "", "json file with the users and products of the fake services, used with --dev")
+)
+
+func setupTracing() {
 	tp, tpErr := jaegerTraceProvider()
 	if tpErr != nil {
 		log.Fatal(tpErr)
@@ -63,11 +66,65 @@ func jaegerTraceProvider() (*sdktrace.TracerProvider, error) {
 	return tp, nil
 }
 
+func postgresDSN() string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWD"), os.Getenv("DB_DBNAME"),
+	)
+}
+
+// openOrderStore opens the Postgres database of the environment and checks
+// its schema. Dev mode keeps the orders in memory and opens no database.
+func openOrderStore(dev bool) (OrderStore, func(), error) {
+	if dev {
+		log.Println("dev mode: orders are kept in memory")
+		return newMemoryOrderStore(), func() {}, nil
+	}
+
+	// init user db connection
+	pgDSN := postgresDSN()
+
+	orderDB, err := sql.Open("postgres", pgDSN)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := orderDB.Ping(); err != nil {
+		orderDB.Close()
+		return nil, nil, fmt.Errorf("can't ping to user db: %w", err)
+	}
+
+	// AUTO_MIGRATE=true applies the embedded migrations before serving
+	if err := checkSchemaVersion(pgDSN, os.Getenv("AUTO_MIGRATE") == "true"); err != nil {
+		orderDB.Close()
+		return nil, nil, fmt.Errorf("can't serve with this database schema: %w", err)
+	}
+	return newPostgresOrderStore(orderDB), func() { orderDB.Close() }, nil
+}
+
 func getListMessage() []string {
 	return make([]string, 0, 2<<20)
 }
 
 func main() {
+	flag.Parse()
+
+	// the .env file is optional in dev mode, the defaults are enough
+	if err := godotenv.Load(); err != nil && !*devMode {
+		log.Fatal(err)
+	}
+
+	// "order-service migrate ..." manages the schema and exits
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrateCommand(postgresDSN(), flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if !*devMode {
+		setupTracing()
+	}
+
 	// pprofiling
 	go func() {
 		var memory = make(chan bool, 1)
@@ -123,51 +180,100 @@ func main() {
 		}
 	}()
 
-	// init user db connection
-	pgDSN := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWD"), os.Getenv("DB_DBNAME"),
-	)
-
-	orderDB, err := sql.Open("postgres", pgDSN)
+	orderStore, closeStore, err := openOrderStore(*devMode)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer orderDB.Close()
-	if err := orderDB.Ping(); err != nil {
-		log.Fatal("can't ping to user db", err)
-	}
+	defer closeStore()
 
 	grpcServer := grpc.NewServer()
 
-	authConn, err := grpc.Dial("auth-service:8080", grpc.WithInsecure(), grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()))
-	if err != nil {
-		log.Fatal("can't dial auth service", err)
+	var authClient pb.AuthServiceClient
+	var productClient pb.ProductServiceClient
+	var cartClient pb.CartServiceClient
+	if *devMode {
+		// the fake services share one in-process server
+		seed, err := loadDevSeed(*devSeedPath)
+		if err != nil {
+			log.Fatal("can't load dev seed", err)
+		}
+		devConn, stop, err := startDevServices(seed)
+		if err != nil {
+			log.Fatal("can't start dev services", err)
+		}
+		defer stop()
+		authClient = pb.NewAuthServiceClient(devConn)
+		productClient = pb.NewProductServiceClient(devConn)
+		cartClient = pb.NewCartServiceClient(devConn)
+		log.Println("dev mode: fake auth, product and cart services")
+	} else {
+		authConn, err := grpc.Dial("auth-service:8080", grpc.WithInsecure(), grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()))
+		if err != nil {
+			log.Fatal("can't dial auth service", err)
+		}
+		authClient = pb.NewAuthServiceClient(authConn)
+
+		productConn, err := grpc.Dial("product-service:8080", grpc.WithInsecure(), grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()))
+		if err != nil {
+			log.Fatal("can't dial product service", err)
+		}
+		productClient = pb.NewProductServiceClient(productConn)
+
+		cartConn, err := grpc.Dial("cart-service:8080", grpc.WithInsecure(), grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()))
+		if err != nil {
+			log.Fatal("can't dial cart service", err)
+		}
+		cartClient = pb.NewCartServiceClient(cartConn)
 	}
-	authClient := pb.NewAuthServiceClient(authConn)
 
-	productConn, err := grpc.Dial("product-service:8080", grpc.WithInsecure(), grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()))
-	if err != nil {
-		log.Fatal("can't dial product service", err)
+	// without a payment provider every order stays unpaid (cash on delivery)
+	var paymentProvider PaymentProvider
+	if os.Getenv("PAYMENT_PROVIDER") == "fake" {
+		paymentProvider = newFakePaymentProvider(0)
 	}
-	productClient := pb.NewProductServiceClient(productConn)
 
-	cartConn, err := grpc.Dial("cart-service:8080", grpc.WithInsecure(), grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()))
+	// parcels are booked with the carrier of CARRIER, dev mode fakes it.
+	// Without a carrier the suppliers enter the tracking numbers
+	carrier, err := newCarrier(os.Getenv("CARRIER"), *devMode)
 	if err != nil {
-		log.Fatal("can't dial cart service", err)
+		log.Fatal("can't set up the carrier: ", err)
 	}
-	cartClient := pb.NewCartServiceClient(cartConn)
 
-	// init queries
-	queries := repository.New(orderDB)
+	// flat shipping fee charged per supplier parcel
+	shippingFee, _ := strconv.ParseInt(os.Getenv("SHIPPING_FEE"), 10, 64)
+	// price difference accepted between the cart and the checkout
+	priceTolerance, _ := strconv.ParseInt(os.Getenv("PRICE_CHANGE_TOLERANCE"), 10, 64)
+
 	orderService := orderService{
-		authClient:    authClient,
-		orderRepo:     *queries,
-		cartClient:    cartClient,
-		productClient: productClient,
+		authClient:     authClient,
+		orderStore:     orderStore,
+		carrier:        carrier,
+		payment:        paymentProvider,
+		shippingFee:    shippingFee,
+		priceTolerance: priceTolerance,
+		cartClient:     cartClient,
+		productClient:  productClient,
 	}
 	pb.RegisterOrderServiceServer(grpcServer, orderService)
 
+	// waiting orders the supplier doesn't handle in time give their inventory back
+	deadlineHours, err := strconv.Atoi(os.Getenv("ORDER_HANDLE_DEADLINE_HOURS"))
+	if err != nil || deadlineHours <= 0 {
+		deadlineHours = 72
+	}
+	checkInterval, err := time.ParseDuration(os.Getenv("STALE_ORDER_CHECK_INTERVAL"))
+	if err != nil || checkInterval <= 0 {
+		checkInterval = 5 * time.Minute
+	}
+	go orderService.runStaleOrderCanceller(context.Background(), checkInterval, int32(deadlineHours))
+
+	// the summaries of the admin dashboard
+	refreshInterval, err := time.ParseDuration(os.Getenv("DASHBOARD_REFRESH_INTERVAL"))
+	if err != nil || refreshInterval <= 0 {
+		refreshInterval = 15 * time.Minute
+	}
+	go orderService.runDashboardRefresher(context.Background(), refreshInterval)
+
 	listener, err := net.Listen("tcp", ":8080")
 	if err != nil {
 		log.Fatal("cannot create listener: ", err)
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestOpenOrderStoreDev(t *testing.T) {
+	// a database that can't be reached, dev mode must not try it
+	t.Setenv("DB_HOST", "127.0.0.1")
+	t.Setenv("DB_PORT", "1")
+	t.Setenv("AUTO_MIGRATE", "true")
+
+	store, closeStore, err := openOrderStore(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+	if _, ok := store.(*memoryOrderStore); !ok {
+		t.Fatalf("dev mode store is %T, want *memoryOrderStore", store)
+	}
+
+	if _, _, err := openOrderStore(false); err == nil {
+		t.Fatal("opened a database that can't be reached")
+	}
+}
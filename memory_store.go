@@ -0,0 +1,1868 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/e-commerce-microservices/order-service/repository"
+	"golang.org/x/text/unicode/norm"
+)
+
+// memoryOrderStore is an OrderStore kept in memory, for the tests and --dev.
+// It follows the SQL of db/query and the triggers of db/migration: ids are
+// sequential, a NULL status never matches a status filter, the timestamps,
+// the status history and the search documents are kept like the triggers
+// keep them, and the unique and foreign keys are enforced.
+//
+// A transaction has the store for itself until it ends, a rollback puts back
+// the copy taken when it began. The queries of a transaction must not be
+// mixed with the queries of the store, they would wait for it forever.
+type memoryOrderStore struct {
+	memoryQueries
+	mu   sync.Mutex
+	data *memoryData
+	// now is the clock of the default values and the triggers
+	now func() time.Time
+}
+
+// memoryQueries runs the queries on the data of store, the queries of a
+// transaction run under the lock the transaction holds.
+type memoryQueries struct {
+	store *memoryOrderStore
+	tx    bool
+}
+
+// memoryData are the tables, the materialized views and the sequences.
+type memoryData struct {
+	ids                         map[string]int64
+	orders                      map[int64]repository.Order
+	addresses                   map[int64]repository.Address
+	searchDocuments             map[int64][]string
+	statusHistory               map[int64]repository.OrderStatusHistory
+	audits                      map[int64]repository.OrderAudit
+	coupons                     map[int64]repository.Coupon
+	couponRedemptions           map[int64]repository.CouponRedemption
+	customerAddresses           map[int64]repository.CustomerAddress
+	checkoutOutcomes            map[int64]repository.CheckoutOutcome
+	orderDailySummary           []repository.OrderDailySummary
+	supplierHandlingSummary     []repository.SupplierHandlingSummary
+	checkoutOutcomeDailySummary []repository.CheckoutOutcomeDailySummary
+	dashboardRefreshedAt        time.Time
+	orderSettings               map[int64]repository.SupplierOrderSetting
+	invoiceSettings             map[int64]repository.SupplierInvoiceSetting
+	invoiceSequences            map[repository.NextInvoiceNumberParams]int32
+	invoices                    map[int64]repository.Invoice
+	invoiceLines                map[int64]repository.InvoiceLine
+	returnRequests              map[int64]repository.ReturnRequest
+	returnItems                 map[int64]repository.ReturnItem
+	refunds                     map[int64]repository.Refund
+	shipments                   map[int64]repository.Shipment
+	shipmentItems               map[int64]repository.ShipmentItem
+}
+
+func newMemoryOrderStore() *memoryOrderStore {
+	s := &memoryOrderStore{
+		data: &memoryData{
+			ids:                  make(map[string]int64),
+			orders:               make(map[int64]repository.Order),
+			addresses:            make(map[int64]repository.Address),
+			searchDocuments:      make(map[int64][]string),
+			statusHistory:        make(map[int64]repository.OrderStatusHistory),
+			audits:               make(map[int64]repository.OrderAudit),
+			coupons:              make(map[int64]repository.Coupon),
+			couponRedemptions:    make(map[int64]repository.CouponRedemption),
+			customerAddresses:    make(map[int64]repository.CustomerAddress),
+			checkoutOutcomes:     make(map[int64]repository.CheckoutOutcome),
+			dashboardRefreshedAt: time.Now(),
+			orderSettings:        make(map[int64]repository.SupplierOrderSetting),
+			invoiceSettings:      make(map[int64]repository.SupplierInvoiceSetting),
+			invoiceSequences:     make(map[repository.NextInvoiceNumberParams]int32),
+			invoices:             make(map[int64]repository.Invoice),
+			invoiceLines:         make(map[int64]repository.InvoiceLine),
+			returnRequests:       make(map[int64]repository.ReturnRequest),
+			returnItems:          make(map[int64]repository.ReturnItem),
+			refunds:              make(map[int64]repository.Refund),
+			shipments:            make(map[int64]repository.Shipment),
+			shipmentItems:        make(map[int64]repository.ShipmentItem),
+		},
+		now: time.Now,
+	}
+	s.memoryQueries = memoryQueries{store: s}
+	return s
+}
+
+var _ OrderStore = (*memoryOrderStore)(nil)
+
+func (s *memoryOrderStore) ExecTx(_ context.Context, _ *sql.TxOptions, fn func(q repository.Querier) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := s.data.clone()
+	if err := fn(memoryQueries{store: s, tx: true}); err != nil {
+		s.data = saved
+		return err
+	}
+	return nil
+}
+
+// lock takes the store for a query outside of a transaction, until the
+// returned func is called.
+func (q memoryQueries) lock() func() {
+	if q.tx {
+		return func() {}
+	}
+	q.store.mu.Lock()
+	return q.store.mu.Unlock
+}
+
+func (q memoryQueries) d() *memoryData {
+	return q.store.data
+}
+
+func (d *memoryData) clone() *memoryData {
+	c := *d
+	c.ids = cloneMap(d.ids)
+	c.orders = cloneMap(d.orders)
+	c.addresses = cloneMap(d.addresses)
+	c.searchDocuments = cloneMap(d.searchDocuments)
+	c.statusHistory = cloneMap(d.statusHistory)
+	c.audits = cloneMap(d.audits)
+	c.coupons = cloneMap(d.coupons)
+	c.couponRedemptions = cloneMap(d.couponRedemptions)
+	c.customerAddresses = cloneMap(d.customerAddresses)
+	c.checkoutOutcomes = cloneMap(d.checkoutOutcomes)
+	c.orderSettings = cloneMap(d.orderSettings)
+	c.invoiceSettings = cloneMap(d.invoiceSettings)
+	c.invoiceSequences = cloneMap(d.invoiceSequences)
+	c.invoices = cloneMap(d.invoices)
+	c.invoiceLines = cloneMap(d.invoiceLines)
+	c.returnRequests = cloneMap(d.returnRequests)
+	c.returnItems = cloneMap(d.returnItems)
+	c.refunds = cloneMap(d.refunds)
+	c.shipments = cloneMap(d.shipments)
+	c.shipmentItems = cloneMap(d.shipmentItems)
+	// the summaries and the documents are replaced, never changed in place
+	return &c
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// nextID is the next value of the bigserial id of table.
+func (d *memoryData) nextID(table string) int64 {
+	d.ids[table]++
+	return d.ids[table]
+}
+
+// rows returns the rows of table matching keep, by id like a primary key scan.
+func rows[T any](table map[int64]T, keep func(T) bool) []T {
+	listID := make([]int64, 0, len(table))
+	for id, row := range table {
+		if keep(row) {
+			listID = append(listID, id)
+		}
+	}
+	sort.Slice(listID, func(i, j int) bool { return listID[i] < listID[j] })
+
+	var items []T
+	for _, id := range listID {
+		items = append(items, table[id])
+	}
+	return items
+}
+
+// reverse puts rows in the order of an ORDER BY "id" DESC.
+func reverse[T any](items []T) []T {
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+	return items
+}
+
+func validTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+// the statuses matched by the "handled" queries
+func isHandledStatus(status repository.NullOrderStatusEnum) bool {
+	if !status.Valid {
+		return false
+	}
+	switch status.OrderStatusEnum {
+	case repository.OrderStatusEnumHandled, repository.OrderStatusEnumShipped, repository.OrderStatusEnumDelivered:
+		return true
+	}
+	return false
+}
+
+func hasStatus(status repository.NullOrderStatusEnum, want repository.OrderStatusEnum) bool {
+	return status.Valid && status.OrderStatusEnum == want
+}
+
+// hasAnyStatus is "status"::text = ANY(list), an empty list matches every order.
+func hasAnyStatus(status repository.NullOrderStatusEnum, list []string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, want := range list {
+		if hasStatus(status, repository.OrderStatusEnum(want)) {
+			return true
+		}
+	}
+	return false
+}
+
+func orderStatus(status repository.OrderStatusEnum) repository.NullOrderStatusEnum {
+	return repository.NullOrderStatusEnum{OrderStatusEnum: status, Valid: true}
+}
+
+// inRange is "created_at" >= from AND "created_at" < to with optional bounds.
+func inRange(t time.Time, from, to sql.NullTime) bool {
+	return (!from.Valid || !t.Before(from.Time)) && (!to.Valid || t.Before(to.Time))
+}
+
+// searchNormalize is order_search_normalize of the migration
+// 000011_create_order_search: lower case, without accents.
+func searchNormalize(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case r == 'đ' || r == 'Đ':
+			b.WriteRune('d')
+		default:
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// searchWords splits a normalized text into the words of a tsvector.
+func searchWords(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// matchSearch is document @@ query for the queries built by searchQuery,
+// every prefix must start a word of the document.
+func matchSearch(document []string, query string) bool {
+	for _, term := range strings.Split(searchNormalize(query), "&") {
+		prefix := strings.TrimSuffix(strings.TrimSpace(term), ":*")
+		found := false
+		for _, word := range document {
+			if strings.HasPrefix(word, prefix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// saveOrder writes order over old like the triggers of the order table: the
+// timestamps, the status history and the search document.
+func (q memoryQueries) saveOrder(old, order repository.Order) repository.Order {
+	d := q.d()
+	now := q.store.now()
+	order.UpdatedAt = now
+	if order.Status != old.Status {
+		switch {
+		case hasStatus(order.Status, repository.OrderStatusEnumHandled):
+			order.HandledAt = validTime(now)
+		case hasStatus(order.Status, repository.OrderStatusEnumCancel):
+			order.CancelledAt = validTime(now)
+		}
+		d.recordStatus(order.ID, old.Status, order.Status, now)
+	}
+	d.orders[order.ID] = order
+	return order
+}
+
+func (d *memoryData) recordStatus(orderID int64, oldStatus, newStatus repository.NullOrderStatusEnum, now time.Time) {
+	id := d.nextID("order_status_history")
+	d.statusHistory[id] = repository.OrderStatusHistory{
+		ID:        id,
+		OrderID:   orderID,
+		OldStatus: oldStatus,
+		NewStatus: newStatus,
+		CreatedAt: now,
+	}
+}
+
+// updateOrder applies set to the order when where accepts it as it is, like
+// a conditional UPDATE ... RETURNING.
+func (q memoryQueries) updateOrder(id int64, where func(repository.Order) bool, set func(*repository.Order)) (repository.Order, error) {
+	old, ok := q.d().orders[id]
+	if !ok || !where(old) {
+		return repository.Order{}, sql.ErrNoRows
+	}
+	order := old
+	set(&order)
+	return q.saveOrder(old, order), nil
+}
+
+func setStatus(status repository.NullOrderStatusEnum) func(*repository.Order) {
+	return func(order *repository.Order) {
+		order.Status = status
+	}
+}
+
+// cancelOrder cancels an order that still owes its inventory.
+func cancelOrder(order *repository.Order) {
+	order.Status = orderStatus(repository.OrderStatusEnumCancel)
+	order.RestockPending = true
+}
+
+func (q memoryQueries) orders(keep func(repository.Order) bool) []repository.Order {
+	return rows(q.d().orders, keep)
+}
+
+func (q memoryQueries) CreateOrder(_ context.Context, arg repository.CreateOrderParams) (repository.Order, error) {
+	defer q.lock()()
+	d := q.d()
+	address, ok := d.addresses[arg.AddressID]
+	if !ok {
+		return repository.Order{}, fmt.Errorf("memory store: address %d does not exist", arg.AddressID)
+	}
+	if arg.CouponRedemptionID.Valid {
+		if _, ok := d.couponRedemptions[arg.CouponRedemptionID.Int64]; !ok {
+			return repository.Order{}, fmt.Errorf("memory store: coupon redemption %d does not exist", arg.CouponRedemptionID.Int64)
+		}
+	}
+	for _, order := range d.orders {
+		if order.OrderNumber == arg.OrderNumber {
+			return repository.Order{}, fmt.Errorf("memory store: order number %s is taken", arg.OrderNumber)
+		}
+	}
+	now := q.store.now()
+	order := repository.Order{
+		ID:                 d.nextID("order"),
+		CustomerID:         arg.CustomerID,
+		SupplierID:         arg.SupplierID,
+		ProductID:          arg.ProductID,
+		Quantity:           arg.Quantity,
+		Status:             orderStatus(repository.OrderStatusEnumWaiting),
+		AddressID:          arg.AddressID,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+		Price:              arg.Price,
+		PaymentStatus:      arg.PaymentStatus,
+		PaymentID:          arg.PaymentID,
+		Discount:           arg.Discount,
+		CouponRedemptionID: arg.CouponRedemptionID,
+		OrderNumber:        arg.OrderNumber,
+		ProductName:        arg.ProductName,
+		ShippingFee:        arg.ShippingFee,
+	}
+	d.orders[order.ID] = order
+	d.recordStatus(order.ID, repository.NullOrderStatusEnum{}, order.Status, now)
+	d.searchDocuments[order.ID] = searchWords(searchNormalize(strings.Join([]string{
+		address.Name, address.Phone, address.Detail, order.ProductName,
+	}, " ")))
+	return order, nil
+}
+
+func (q memoryQueries) GetOrderByID(_ context.Context, id int64) (repository.Order, error) {
+	defer q.lock()()
+	order, ok := q.d().orders[id]
+	if !ok {
+		return repository.Order{}, sql.ErrNoRows
+	}
+	return order, nil
+}
+
+// GetOrderByIDForUpdate has nothing to lock, a transaction already has the
+// whole store.
+func (q memoryQueries) GetOrderByIDForUpdate(ctx context.Context, id int64) (repository.Order, error) {
+	return q.GetOrderByID(ctx, id)
+}
+
+func (q memoryQueries) GetOrderByNumber(_ context.Context, orderNumber string) (repository.Order, error) {
+	defer q.lock()()
+	listOrder := q.orders(func(order repository.Order) bool {
+		return order.OrderNumber == orderNumber
+	})
+	if len(listOrder) == 0 {
+		return repository.Order{}, sql.ErrNoRows
+	}
+	return listOrder[0], nil
+}
+
+func (q memoryQueries) GetOrderNumberByIDs(_ context.Context, ids []int64) ([]repository.GetOrderNumberByIDsRow, error) {
+	defer q.lock()()
+	m := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		m[id] = true
+	}
+	var items []repository.GetOrderNumberByIDsRow
+	for _, order := range q.orders(func(order repository.Order) bool { return m[order.ID] }) {
+		items = append(items, repository.GetOrderNumberByIDsRow{ID: order.ID, OrderNumber: order.OrderNumber})
+	}
+	return items, nil
+}
+
+func (q memoryQueries) GetOrderByAddressId(_ context.Context, addressID int64) ([]repository.Order, error) {
+	defer q.lock()()
+	return q.orders(func(order repository.Order) bool {
+		return order.AddressID == addressID
+	}), nil
+}
+
+func (q memoryQueries) GetWaitingOrderByCustomer(_ context.Context, customerID int64) ([]repository.Order, error) {
+	defer q.lock()()
+	return q.orders(func(order repository.Order) bool {
+		return order.CustomerID == customerID && hasStatus(order.Status, repository.OrderStatusEnumWaiting)
+	}), nil
+}
+
+func (q memoryQueries) GetWaitingOrderBySupplier(_ context.Context, supplierID int64) ([]repository.Order, error) {
+	defer q.lock()()
+	return q.orders(func(order repository.Order) bool {
+		return order.SupplierID == supplierID && hasStatus(order.Status, repository.OrderStatusEnumWaiting)
+	}), nil
+}
+
+func (q memoryQueries) GetHandledOrderByCustomer(_ context.Context, customerID int64) ([]repository.Order, error) {
+	defer q.lock()()
+	return q.orders(func(order repository.Order) bool {
+		return order.CustomerID == customerID && isHandledStatus(order.Status)
+	}), nil
+}
+
+func (q memoryQueries) GetHandledOrderBySupplier(_ context.Context, supplierID int64) ([]repository.Order, error) {
+	defer q.lock()()
+	return q.orders(func(order repository.Order) bool {
+		return order.SupplierID == supplierID && isHandledStatus(order.Status)
+	}), nil
+}
+
+func (q memoryQueries) GetCancelOrderByCustomer(_ context.Context, customerID int64) ([]repository.Order, error) {
+	defer q.lock()()
+	return q.orders(func(order repository.Order) bool {
+		return order.CustomerID == customerID && hasStatus(order.Status, repository.OrderStatusEnumCancel)
+	}), nil
+}
+
+func (q memoryQueries) GetCancelOrderBySupplier(_ context.Context, supplierID int64) ([]repository.Order, error) {
+	defer q.lock()()
+	return q.orders(func(order repository.Order) bool {
+		return order.SupplierID == supplierID && hasStatus(order.Status, repository.OrderStatusEnumCancel)
+	}), nil
+}
+
+func (q memoryQueries) CountOrderByProductId(_ context.Context, productID int64) (int64, error) {
+	defer q.lock()()
+	return int64(len(q.orders(func(order repository.Order) bool {
+		return order.ProductID == productID
+	}))), nil
+}
+
+func (q memoryQueries) CountOrderHandledByProductId(_ context.Context, productID int64) ([]int32, error) {
+	defer q.lock()()
+	var items []int32
+	for _, order := range q.orders(func(order repository.Order) bool {
+		return order.ProductID == productID && isHandledStatus(order.Status)
+	}) {
+		items = append(items, order.Quantity)
+	}
+	return items, nil
+}
+
+func (q memoryQueries) CheckOrderIsHandled(_ context.Context, arg repository.CheckOrderIsHandledParams) (int64, error) {
+	defer q.lock()()
+	return int64(len(q.orders(func(order repository.Order) bool {
+		return order.ProductID == arg.ProductID && order.CustomerID == arg.CustomerID && isHandledStatus(order.Status)
+	}))), nil
+}
+
+func (q memoryQueries) UpdateOrderStatus(_ context.Context, arg repository.UpdateOrderStatusParams) (repository.Order, error) {
+	defer q.lock()()
+	// NULL = NULL is not true in SQL, an order without status never matches
+	return q.updateOrder(arg.ID, func(order repository.Order) bool {
+		return arg.OldStatus.Valid && hasStatus(order.Status, arg.OldStatus.OrderStatusEnum)
+	}, setStatus(arg.NewStatus))
+}
+
+func (q memoryQueries) HandleOrder(_ context.Context, id int64) (repository.Order, error) {
+	defer q.lock()()
+	return q.updateOrder(id, func(order repository.Order) bool {
+		return hasStatus(order.Status, repository.OrderStatusEnumWaiting) && !order.HeldAt.Valid
+	}, setStatus(orderStatus(repository.OrderStatusEnumHandled)))
+}
+
+func (q memoryQueries) CancelOrder(_ context.Context, id int64) (repository.Order, error) {
+	defer q.lock()()
+	return q.updateOrder(id, func(order repository.Order) bool {
+		return hasStatus(order.Status, repository.OrderStatusEnumWaiting) && !order.HeldAt.Valid
+	}, cancelOrder)
+}
+
+func (q memoryQueries) DeleteOrder(_ context.Context, id int64) (repository.Order, error) {
+	defer q.lock()()
+	return q.updateOrder(id, func(order repository.Order) bool {
+		return (hasStatus(order.Status, repository.OrderStatusEnumWaiting) || hasStatus(order.Status, repository.OrderStatusEnumHandled)) && !order.HeldAt.Valid
+	}, cancelOrder)
+}
+
+func (q memoryQueries) UpdateOrderPayment(_ context.Context, arg repository.UpdateOrderPaymentParams) error {
+	defer q.lock()()
+	_, err := q.updateOrder(arg.ID, func(repository.Order) bool { return true }, func(order *repository.Order) {
+		order.PaymentStatus = arg.PaymentStatus
+		order.PaymentID = arg.PaymentID
+	})
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
+
+func (q memoryQueries) CreateAddress(_ context.Context, arg repository.CreateAddressParams) (repository.Address, error) {
+	defer q.lock()()
+	d := q.d()
+	address := repository.Address{
+		ID:         d.nextID("address"),
+		Name:       arg.Name,
+		Phone:      arg.Phone,
+		Detail:     arg.Detail,
+		CustomerID: arg.CustomerID,
+	}
+	d.addresses[address.ID] = address
+	return address, nil
+}
+
+func (q memoryQueries) GetAddressById(_ context.Context, id int64) (repository.Address, error) {
+	defer q.lock()()
+	address, ok := q.d().addresses[id]
+	if !ok {
+		return repository.Address{}, sql.ErrNoRows
+	}
+	return address, nil
+}
+
+func (q memoryQueries) DeleteAddress(_ context.Context, id int64) error {
+	defer q.lock()()
+	d := q.d()
+	for _, order := range d.orders {
+		if order.AddressID == id {
+			return fmt.Errorf("memory store: address %d is used by order %d", id, order.ID)
+		}
+	}
+	delete(d.addresses, id)
+	return nil
+}
+
+// admin.sql
+
+func (q memoryQueries) ForceCancelOrder(_ context.Context, arg repository.ForceCancelOrderParams) (repository.Order, error) {
+	defer q.lock()()
+	return q.updateOrder(arg.ID, func(order repository.Order) bool {
+		return hasStatus(order.Status, repository.OrderStatusEnumWaiting) || hasStatus(order.Status, repository.OrderStatusEnumHandled)
+	}, func(order *repository.Order) {
+		order.Status = orderStatus(repository.OrderStatusEnumCancel)
+		order.CancelReason = arg.CancelReason
+		order.HeldAt = sql.NullTime{}
+		order.HoldReason = ""
+		order.RestockPending = true
+	})
+}
+
+func (q memoryQueries) HoldOrder(_ context.Context, arg repository.HoldOrderParams) (repository.Order, error) {
+	defer q.lock()()
+	return q.updateOrder(arg.ID, func(order repository.Order) bool {
+		return !order.HeldAt.Valid && (hasStatus(order.Status, repository.OrderStatusEnumWaiting) ||
+			hasStatus(order.Status, repository.OrderStatusEnumHandled))
+	}, func(order *repository.Order) {
+		order.HeldAt = validTime(q.store.now())
+		order.HoldReason = arg.HoldReason
+	})
+}
+
+func (q memoryQueries) ReleaseOrder(_ context.Context, id int64) (repository.Order, error) {
+	defer q.lock()()
+	return q.updateOrder(id, func(order repository.Order) bool {
+		return order.HeldAt.Valid
+	}, func(order *repository.Order) {
+		order.HeldAt = sql.NullTime{}
+		order.HoldReason = ""
+	})
+}
+
+func (q memoryQueries) CreateOrderAudit(_ context.Context, arg repository.CreateOrderAuditParams) (repository.OrderAudit, error) {
+	defer q.lock()()
+	d := q.d()
+	if _, ok := d.orders[arg.OrderID]; !ok {
+		return repository.OrderAudit{}, fmt.Errorf("memory store: order %d does not exist", arg.OrderID)
+	}
+	audit := repository.OrderAudit{
+		ID:        d.nextID("order_audit"),
+		OrderID:   arg.OrderID,
+		AdminID:   arg.AdminID,
+		Action:    arg.Action,
+		Reason:    arg.Reason,
+		OldStatus: arg.OldStatus,
+		NewStatus: arg.NewStatus,
+		CreatedAt: q.store.now(),
+	}
+	d.audits[audit.ID] = audit
+	return audit, nil
+}
+
+func (q memoryQueries) GetOrderAuditByOrder(_ context.Context, orderID int64) ([]repository.OrderAudit, error) {
+	defer q.lock()()
+	return rows(q.d().audits, func(audit repository.OrderAudit) bool {
+		return audit.OrderID == orderID
+	}), nil
+}
+
+func (q memoryQueries) GetOrderStatusHistoryByOrder(_ context.Context, orderID int64) ([]repository.OrderStatusHistory, error) {
+	defer q.lock()()
+	return rows(q.d().statusHistory, func(history repository.OrderStatusHistory) bool {
+		return history.OrderID == orderID
+	}), nil
+}
+
+// coupon.sql
+
+func (q memoryQueries) CreateCoupon(_ context.Context, arg repository.CreateCouponParams) (repository.Coupon, error) {
+	defer q.lock()()
+	d := q.d()
+	if arg.Value <= 0 {
+		return repository.Coupon{}, fmt.Errorf("memory store: coupon value %d is not positive", arg.Value)
+	}
+	for _, coupon := range d.coupons {
+		if coupon.Code == arg.Code {
+			return repository.Coupon{}, fmt.Errorf("memory store: coupon code %s is taken", arg.Code)
+		}
+	}
+	coupon := repository.Coupon{
+		ID:                    d.nextID("coupon"),
+		Code:                  arg.Code,
+		Type:                  arg.Type,
+		Value:                 arg.Value,
+		MaxDiscount:           arg.MaxDiscount,
+		MinOrderValue:         arg.MinOrderValue,
+		SupplierID:            arg.SupplierID,
+		ProductID:             arg.ProductID,
+		UsageLimit:            arg.UsageLimit,
+		UsageLimitPerCustomer: arg.UsageLimitPerCustomer,
+		StartsAt:              arg.StartsAt,
+		EndsAt:                arg.EndsAt,
+		Active:                true,
+		CreatedBy:             arg.CreatedBy,
+		CreatedAt:             q.store.now(),
+	}
+	d.coupons[coupon.ID] = coupon
+	return coupon, nil
+}
+
+func (q memoryQueries) GetCouponByID(_ context.Context, id int64) (repository.Coupon, error) {
+	defer q.lock()()
+	coupon, ok := q.d().coupons[id]
+	if !ok {
+		return repository.Coupon{}, sql.ErrNoRows
+	}
+	return coupon, nil
+}
+
+func (q memoryQueries) GetCouponByCode(_ context.Context, code string) (repository.Coupon, error) {
+	defer q.lock()()
+	listCoupon := rows(q.d().coupons, func(coupon repository.Coupon) bool {
+		return coupon.Code == code
+	})
+	if len(listCoupon) == 0 {
+		return repository.Coupon{}, sql.ErrNoRows
+	}
+	return listCoupon[0], nil
+}
+
+func (q memoryQueries) GetAllCoupon(_ context.Context) ([]repository.Coupon, error) {
+	defer q.lock()()
+	return reverse(rows(q.d().coupons, func(repository.Coupon) bool { return true })), nil
+}
+
+func (q memoryQueries) GetCouponBySupplier(_ context.Context, supplierID int64) ([]repository.Coupon, error) {
+	defer q.lock()()
+	return reverse(rows(q.d().coupons, func(coupon repository.Coupon) bool {
+		return coupon.SupplierID == supplierID
+	})), nil
+}
+
+func (q memoryQueries) SetCouponActive(_ context.Context, arg repository.SetCouponActiveParams) (repository.Coupon, error) {
+	defer q.lock()()
+	d := q.d()
+	coupon, ok := d.coupons[arg.ID]
+	if !ok {
+		return repository.Coupon{}, sql.ErrNoRows
+	}
+	coupon.Active = arg.Active
+	d.coupons[coupon.ID] = coupon
+	return coupon, nil
+}
+
+func (q memoryQueries) RedeemCoupon(_ context.Context, id int64) (repository.Coupon, error) {
+	defer q.lock()()
+	d := q.d()
+	coupon, ok := d.coupons[id]
+	if !ok || !coupon.Active || (coupon.UsageLimit != 0 && coupon.UsedCount >= coupon.UsageLimit) {
+		return repository.Coupon{}, sql.ErrNoRows
+	}
+	coupon.UsedCount++
+	d.coupons[coupon.ID] = coupon
+	return coupon, nil
+}
+
+func (q memoryQueries) UnredeemCoupon(_ context.Context, id int64) error {
+	defer q.lock()()
+	d := q.d()
+	coupon, ok := d.coupons[id]
+	if ok && coupon.UsedCount > 0 {
+		coupon.UsedCount--
+		d.coupons[coupon.ID] = coupon
+	}
+	return nil
+}
+
+func (q memoryQueries) CountCouponRedemptionByCustomer(_ context.Context, arg repository.CountCouponRedemptionByCustomerParams) (int64, error) {
+	defer q.lock()()
+	return int64(len(rows(q.d().couponRedemptions, func(redemption repository.CouponRedemption) bool {
+		return redemption.CouponID == arg.CouponID && redemption.CustomerID == arg.CustomerID && !redemption.Released
+	}))), nil
+}
+
+func (q memoryQueries) CreateCouponRedemption(_ context.Context, arg repository.CreateCouponRedemptionParams) (repository.CouponRedemption, error) {
+	defer q.lock()()
+	d := q.d()
+	if _, ok := d.coupons[arg.CouponID]; !ok {
+		return repository.CouponRedemption{}, fmt.Errorf("memory store: coupon %d does not exist", arg.CouponID)
+	}
+	redemption := repository.CouponRedemption{
+		ID:         d.nextID("coupon_redemption"),
+		CouponID:   arg.CouponID,
+		CustomerID: arg.CustomerID,
+		Discount:   arg.Discount,
+		CreatedAt:  q.store.now(),
+	}
+	d.couponRedemptions[redemption.ID] = redemption
+	return redemption, nil
+}
+
+func (q memoryQueries) GetCouponRedemptionForUpdate(_ context.Context, id int64) (repository.CouponRedemption, error) {
+	defer q.lock()()
+	redemption, ok := q.d().couponRedemptions[id]
+	if !ok {
+		return repository.CouponRedemption{}, sql.ErrNoRows
+	}
+	return redemption, nil
+}
+
+func (q memoryQueries) ReleaseCouponRedemption(_ context.Context, id int64) (repository.CouponRedemption, error) {
+	defer q.lock()()
+	d := q.d()
+	redemption, ok := d.couponRedemptions[id]
+	if !ok || redemption.Released {
+		return repository.CouponRedemption{}, sql.ErrNoRows
+	}
+	redemption.Released = true
+	d.couponRedemptions[redemption.ID] = redemption
+	return redemption, nil
+}
+
+func (q memoryQueries) CountActiveOrderByCouponRedemption(_ context.Context, couponRedemptionID sql.NullInt64) (int64, error) {
+	defer q.lock()()
+	return int64(len(q.orders(func(order repository.Order) bool {
+		return couponRedemptionID.Valid && order.CouponRedemptionID == couponRedemptionID &&
+			order.Status.Valid && order.Status.OrderStatusEnum != repository.OrderStatusEnumCancel
+	}))), nil
+}
+
+// customer_address.sql
+
+// checkDefaultAddress enforces the unique index on the default address of
+// a customer.
+func (d *memoryData) checkDefaultAddress(address repository.CustomerAddress) error {
+	if !address.IsDefault {
+		return nil
+	}
+	for _, other := range d.customerAddresses {
+		if other.ID != address.ID && other.CustomerID == address.CustomerID && other.IsDefault {
+			return fmt.Errorf("memory store: customer %d already has a default address", address.CustomerID)
+		}
+	}
+	return nil
+}
+
+func (q memoryQueries) CreateCustomerAddress(_ context.Context, arg repository.CreateCustomerAddressParams) (repository.CustomerAddress, error) {
+	defer q.lock()()
+	d := q.d()
+	now := q.store.now()
+	address := repository.CustomerAddress{
+		CustomerID: arg.CustomerID,
+		Name:       arg.Name,
+		Phone:      arg.Phone,
+		Detail:     arg.Detail,
+		IsDefault:  arg.IsDefault,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := d.checkDefaultAddress(address); err != nil {
+		return repository.CustomerAddress{}, err
+	}
+	address.ID = d.nextID("customer_address")
+	d.customerAddresses[address.ID] = address
+	return address, nil
+}
+
+func (q memoryQueries) UpdateCustomerAddress(_ context.Context, arg repository.UpdateCustomerAddressParams) (repository.CustomerAddress, error) {
+	defer q.lock()()
+	d := q.d()
+	address, ok := d.customerAddresses[arg.ID]
+	if !ok || address.CustomerID != arg.CustomerID {
+		return repository.CustomerAddress{}, sql.ErrNoRows
+	}
+	address.Name = arg.Name
+	address.Phone = arg.Phone
+	address.Detail = arg.Detail
+	address.IsDefault = arg.IsDefault
+	address.UpdatedAt = q.store.now()
+	if err := d.checkDefaultAddress(address); err != nil {
+		return repository.CustomerAddress{}, err
+	}
+	d.customerAddresses[address.ID] = address
+	return address, nil
+}
+
+func (q memoryQueries) DeleteCustomerAddress(_ context.Context, arg repository.DeleteCustomerAddressParams) (repository.CustomerAddress, error) {
+	defer q.lock()()
+	d := q.d()
+	address, ok := d.customerAddresses[arg.ID]
+	if !ok || address.CustomerID != arg.CustomerID {
+		return repository.CustomerAddress{}, sql.ErrNoRows
+	}
+	delete(d.customerAddresses, address.ID)
+	return address, nil
+}
+
+func (q memoryQueries) GetCustomerAddressByID(_ context.Context, id int64) (repository.CustomerAddress, error) {
+	defer q.lock()()
+	address, ok := q.d().customerAddresses[id]
+	if !ok {
+		return repository.CustomerAddress{}, sql.ErrNoRows
+	}
+	return address, nil
+}
+
+func (q memoryQueries) GetCustomerAddressByCustomer(_ context.Context, customerID int64) ([]repository.CustomerAddress, error) {
+	defer q.lock()()
+	items := reverse(rows(q.d().customerAddresses, func(address repository.CustomerAddress) bool {
+		return address.CustomerID == customerID
+	}))
+	sort.SliceStable(items, func(i, j int) bool { return items[i].IsDefault && !items[j].IsDefault })
+	return items, nil
+}
+
+func (q memoryQueries) CountCustomerAddress(_ context.Context, customerID int64) (int64, error) {
+	defer q.lock()()
+	return int64(len(rows(q.d().customerAddresses, func(address repository.CustomerAddress) bool {
+		return address.CustomerID == customerID
+	}))), nil
+}
+
+func (q memoryQueries) ClearDefaultCustomerAddress(_ context.Context, customerID int64) error {
+	defer q.lock()()
+	d := q.d()
+	for id, address := range d.customerAddresses {
+		if address.CustomerID == customerID && address.IsDefault {
+			address.IsDefault = false
+			address.UpdatedAt = q.store.now()
+			d.customerAddresses[id] = address
+		}
+	}
+	return nil
+}
+
+// dashboard.sql
+
+func (q memoryQueries) CreateCheckoutOutcome(_ context.Context, arg repository.CreateCheckoutOutcomeParams) error {
+	defer q.lock()()
+	d := q.d()
+	outcome := repository.CheckoutOutcome{
+		ID:            d.nextID("checkout_outcome"),
+		CustomerID:    arg.CustomerID,
+		FailureReason: arg.FailureReason,
+		LineCount:     arg.LineCount,
+		CreatedAt:     q.store.now(),
+	}
+	d.checkoutOutcomes[outcome.ID] = outcome
+	return nil
+}
+
+// TryLockDashboardRefresh always gets the lock, the transactions of the
+// store already run one at a time.
+func (q memoryQueries) TryLockDashboardRefresh(_ context.Context) (bool, error) {
+	return true, nil
+}
+
+// dashboardDay is the day of t in the time zone of the dashboard, as a date
+// column is read.
+func dashboardDay(t time.Time, loc *time.Location) time.Time {
+	return sqlDate(t.In(loc))
+}
+
+// RefreshDashboardSummary computes the materialized views of the migration
+// 000014_create_dashboard_summary, they stay as they are until the next refresh.
+func (q memoryQueries) RefreshDashboardSummary(_ context.Context) error {
+	defer q.lock()()
+	loc, err := time.LoadLocation(dashboardTimeZone)
+	if err != nil {
+		return err
+	}
+	d := q.d()
+	now := q.store.now()
+
+	daily := make(map[time.Time]repository.OrderDailySummary)
+	suppliers := make(map[int64]repository.SupplierHandlingSummary)
+	handlingSeconds := make(map[int64]float64)
+	for _, order := range q.orders(func(repository.Order) bool { return true }) {
+		day := dashboardDay(order.CreatedAt, loc)
+		summary := daily[day]
+		summary.Day = day
+		summary.OrderCount++
+		if hasStatus(order.Status, repository.OrderStatusEnumWaiting) {
+			summary.WaitingCount++
+		}
+		if order.HandledAt.Valid {
+			summary.HandledCount++
+		}
+		if hasStatus(order.Status, repository.OrderStatusEnumCancel) {
+			summary.CancelledCount++
+		}
+		// "status" <> 'cancel' is not true for a NULL status
+		if order.Status.Valid && !hasStatus(order.Status, repository.OrderStatusEnumCancel) {
+			summary.UnitCount += int64(order.Quantity)
+			summary.Gmv += order.Price*int64(order.Quantity) - order.Discount
+		}
+		daily[day] = summary
+
+		if order.CreatedAt.Before(now.AddDate(0, 0, -30)) {
+			continue
+		}
+		supplier := suppliers[order.SupplierID]
+		supplier.SupplierID = order.SupplierID
+		supplier.OrderCount++
+		if order.HandledAt.Valid {
+			supplier.HandledCount++
+			handlingSeconds[order.SupplierID] += order.HandledAt.Time.Sub(order.CreatedAt).Seconds()
+		}
+		if hasStatus(order.Status, repository.OrderStatusEnumWaiting) {
+			supplier.WaitingCount++
+			if !supplier.OldestWaitingAt.Valid || order.CreatedAt.Before(supplier.OldestWaitingAt.Time) {
+				supplier.OldestWaitingAt = validTime(order.CreatedAt)
+			}
+		}
+		suppliers[order.SupplierID] = supplier
+	}
+
+	outcomes := make(map[repository.CheckoutOutcomeDailySummary]int64)
+	for _, outcome := range d.checkoutOutcomes {
+		outcomes[repository.CheckoutOutcomeDailySummary{
+			Day:           dashboardDay(outcome.CreatedAt, loc),
+			FailureReason: outcome.FailureReason,
+		}]++
+	}
+
+	d.orderDailySummary = nil
+	for _, summary := range daily {
+		d.orderDailySummary = append(d.orderDailySummary, summary)
+	}
+	d.supplierHandlingSummary = nil
+	for supplierID, supplier := range suppliers {
+		if supplier.HandledCount > 0 {
+			supplier.AverageHandlingSeconds = handlingSeconds[supplierID] / float64(supplier.HandledCount)
+		}
+		d.supplierHandlingSummary = append(d.supplierHandlingSummary, supplier)
+	}
+	d.checkoutOutcomeDailySummary = nil
+	for summary, count := range outcomes {
+		summary.CheckoutCount = count
+		d.checkoutOutcomeDailySummary = append(d.checkoutOutcomeDailySummary, summary)
+	}
+	d.dashboardRefreshedAt = now
+	return nil
+}
+
+func (q memoryQueries) GetDashboardRefreshedAt(_ context.Context) (time.Time, error) {
+	defer q.lock()()
+	return q.d().dashboardRefreshedAt, nil
+}
+
+// inDays is "day" >= day_from::date AND "day" < day_to::date.
+func inDays(day, from, to time.Time) bool {
+	return !day.Before(sqlDate(from)) && day.Before(sqlDate(to))
+}
+
+func (q memoryQueries) GetGmvSeries(_ context.Context, arg repository.GetGmvSeriesParams) ([]repository.GetGmvSeriesRow, error) {
+	defer q.lock()()
+	loc, err := time.LoadLocation(dashboardTimeZone)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int64]repository.GetGmvSeriesRow)
+	for _, summary := range q.d().orderDailySummary {
+		if !inDays(summary.Day, arg.DayFrom, arg.DayTo) {
+			continue
+		}
+		day := time.Date(summary.Day.Year(), summary.Day.Month(), summary.Day.Day(), 0, 0, 0, 0, loc)
+		start := reportBucketStart(day, arg.Bucket, loc)
+		row := m[start.Unix()]
+		row.BucketStart = start
+		row.OrderCount += summary.OrderCount
+		row.CancelledCount += summary.CancelledCount
+		row.UnitCount += summary.UnitCount
+		row.Gmv += summary.Gmv
+		m[start.Unix()] = row
+	}
+	var items []repository.GetGmvSeriesRow
+	for _, row := range m {
+		items = append(items, row)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].BucketStart.Before(items[j].BucketStart) })
+	return items, nil
+}
+
+func (q memoryQueries) GetOrderFunnel(_ context.Context, arg repository.GetOrderFunnelParams) (repository.GetOrderFunnelRow, error) {
+	defer q.lock()()
+	var row repository.GetOrderFunnelRow
+	for _, summary := range q.d().orderDailySummary {
+		if !inDays(summary.Day, arg.DayFrom, arg.DayTo) {
+			continue
+		}
+		row.OrderCount += summary.OrderCount
+		row.WaitingCount += summary.WaitingCount
+		row.HandledCount += summary.HandledCount
+		row.CancelledCount += summary.CancelledCount
+	}
+	return row, nil
+}
+
+func (q memoryQueries) GetSlowestSuppliers(_ context.Context, limit int32) ([]repository.SupplierHandlingSummary, error) {
+	defer q.lock()()
+	var items []repository.SupplierHandlingSummary
+	for _, supplier := range q.d().supplierHandlingSummary {
+		if supplier.HandledCount > 0 {
+			items = append(items, supplier)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].AverageHandlingSeconds != items[j].AverageHandlingSeconds {
+			return items[i].AverageHandlingSeconds > items[j].AverageHandlingSeconds
+		}
+		return items[i].SupplierID < items[j].SupplierID
+	})
+	if len(items) > int(limit) {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (q memoryQueries) GetCheckoutFailures(_ context.Context, arg repository.GetCheckoutFailuresParams) ([]repository.GetCheckoutFailuresRow, error) {
+	defer q.lock()()
+	m := make(map[string]int64)
+	for _, summary := range q.d().checkoutOutcomeDailySummary {
+		if inDays(summary.Day, arg.DayFrom, arg.DayTo) {
+			m[summary.FailureReason] += summary.CheckoutCount
+		}
+	}
+	var items []repository.GetCheckoutFailuresRow
+	for reason, count := range m {
+		items = append(items, repository.GetCheckoutFailuresRow{FailureReason: reason, CheckoutCount: count})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].CheckoutCount != items[j].CheckoutCount {
+			return items[i].CheckoutCount > items[j].CheckoutCount
+		}
+		return items[i].FailureReason < items[j].FailureReason
+	})
+	return items, nil
+}
+
+// export.sql
+
+func (q memoryQueries) GetOrdersForExport(_ context.Context, arg repository.GetOrdersForExportParams) ([]repository.GetOrdersForExportRow, error) {
+	defer q.lock()()
+	d := q.d()
+	listOrder := q.orders(func(order repository.Order) bool {
+		return order.ID > arg.AfterID &&
+			(!arg.CustomerID.Valid || order.CustomerID == arg.CustomerID.Int64) &&
+			(!arg.SupplierID.Valid || order.SupplierID == arg.SupplierID.Int64) &&
+			hasAnyStatus(order.Status, arg.ListStatus) &&
+			inRange(order.CreatedAt, arg.CreatedFrom, arg.CreatedTo)
+	})
+	var items []repository.GetOrdersForExportRow
+	for _, order := range listOrder {
+		if len(items) == int(arg.RowLimit) {
+			break
+		}
+		address := d.addresses[order.AddressID]
+		items = append(items, repository.GetOrdersForExportRow{
+			ID:            order.ID,
+			OrderNumber:   order.OrderNumber,
+			CreatedAt:     order.CreatedAt,
+			UpdatedAt:     order.UpdatedAt,
+			HandledAt:     order.HandledAt,
+			CancelledAt:   order.CancelledAt,
+			Status:        order.Status,
+			PaymentStatus: order.PaymentStatus,
+			CustomerID:    order.CustomerID,
+			SupplierID:    order.SupplierID,
+			ProductID:     order.ProductID,
+			ProductName:   order.ProductName,
+			Price:         order.Price,
+			Quantity:      order.Quantity,
+			Discount:      order.Discount,
+			ShippingFee:   order.ShippingFee,
+			CancelReason:  order.CancelReason,
+			AddressName:   address.Name,
+			AddressPhone:  address.Phone,
+			AddressDetail: address.Detail,
+		})
+	}
+	return items, nil
+}
+
+// invoice.sql
+
+func (q memoryQueries) UpsertSupplierInvoiceSetting(_ context.Context, arg repository.UpsertSupplierInvoiceSettingParams) (repository.SupplierInvoiceSetting, error) {
+	defer q.lock()()
+	if arg.VatPercent < 0 || arg.VatPercent > 100 {
+		return repository.SupplierInvoiceSetting{}, fmt.Errorf("memory store: vat percent %d is out of range", arg.VatPercent)
+	}
+	setting := repository.SupplierInvoiceSetting{
+		SupplierID: arg.SupplierID,
+		LegalName:  arg.LegalName,
+		TaxCode:    arg.TaxCode,
+		Address:    arg.Address,
+		VatPercent: arg.VatPercent,
+		UpdatedAt:  q.store.now(),
+	}
+	q.d().invoiceSettings[setting.SupplierID] = setting
+	return setting, nil
+}
+
+func (q memoryQueries) GetSupplierInvoiceSetting(_ context.Context, supplierID int64) (repository.SupplierInvoiceSetting, error) {
+	defer q.lock()()
+	setting, ok := q.d().invoiceSettings[supplierID]
+	if !ok {
+		return repository.SupplierInvoiceSetting{}, sql.ErrNoRows
+	}
+	return setting, nil
+}
+
+func (q memoryQueries) NextInvoiceNumber(_ context.Context, arg repository.NextInvoiceNumberParams) (int32, error) {
+	defer q.lock()()
+	d := q.d()
+	d.invoiceSequences[arg]++
+	return d.invoiceSequences[arg], nil
+}
+
+func (q memoryQueries) CreateInvoice(_ context.Context, arg repository.CreateInvoiceParams) (repository.Invoice, error) {
+	defer q.lock()()
+	d := q.d()
+	if _, ok := d.orders[arg.OrderID]; !ok {
+		return repository.Invoice{}, fmt.Errorf("memory store: order %d does not exist", arg.OrderID)
+	}
+	for _, invoice := range d.invoices {
+		if invoice.OrderID == arg.OrderID {
+			return repository.Invoice{}, fmt.Errorf("memory store: order %d already has an invoice", arg.OrderID)
+		}
+		if invoice.SupplierID == arg.SupplierID && invoice.Year == arg.Year && invoice.Number == arg.Number {
+			return repository.Invoice{}, fmt.Errorf("memory store: invoice number %d/%d is taken", arg.Year, arg.Number)
+		}
+	}
+	invoice := repository.Invoice{
+		ID:            d.nextID("invoice"),
+		OrderID:       arg.OrderID,
+		SupplierID:    arg.SupplierID,
+		CustomerID:    arg.CustomerID,
+		Year:          arg.Year,
+		Number:        arg.Number,
+		IssuedAt:      arg.IssuedAt,
+		SellerName:    arg.SellerName,
+		SellerTaxCode: arg.SellerTaxCode,
+		SellerAddress: arg.SellerAddress,
+		BuyerName:     arg.BuyerName,
+		BuyerPhone:    arg.BuyerPhone,
+		BuyerAddress:  arg.BuyerAddress,
+		Subtotal:      arg.Subtotal,
+		Tax:           arg.Tax,
+		Total:         arg.Total,
+	}
+	d.invoices[invoice.ID] = invoice
+	return invoice, nil
+}
+
+func (q memoryQueries) CreateInvoiceLine(_ context.Context, arg repository.CreateInvoiceLineParams) (repository.InvoiceLine, error) {
+	defer q.lock()()
+	d := q.d()
+	if _, ok := d.invoices[arg.InvoiceID]; !ok {
+		return repository.InvoiceLine{}, fmt.Errorf("memory store: invoice %d does not exist", arg.InvoiceID)
+	}
+	line := repository.InvoiceLine{
+		ID:          d.nextID("invoice_line"),
+		InvoiceID:   arg.InvoiceID,
+		Description: arg.Description,
+		Quantity:    arg.Quantity,
+		UnitPrice:   arg.UnitPrice,
+		Amount:      arg.Amount,
+		VatPercent:  arg.VatPercent,
+		Tax:         arg.Tax,
+	}
+	d.invoiceLines[line.ID] = line
+	return line, nil
+}
+
+func (q memoryQueries) GetInvoiceByID(_ context.Context, id int64) (repository.Invoice, error) {
+	defer q.lock()()
+	invoice, ok := q.d().invoices[id]
+	if !ok {
+		return repository.Invoice{}, sql.ErrNoRows
+	}
+	return invoice, nil
+}
+
+func (q memoryQueries) GetInvoiceByOrder(_ context.Context, orderID int64) (repository.Invoice, error) {
+	defer q.lock()()
+	listInvoice := rows(q.d().invoices, func(invoice repository.Invoice) bool {
+		return invoice.OrderID == orderID
+	})
+	if len(listInvoice) == 0 {
+		return repository.Invoice{}, sql.ErrNoRows
+	}
+	return listInvoice[0], nil
+}
+
+func (q memoryQueries) GetInvoiceLineByInvoice(_ context.Context, invoiceID int64) ([]repository.InvoiceLine, error) {
+	defer q.lock()()
+	return rows(q.d().invoiceLines, func(line repository.InvoiceLine) bool {
+		return line.InvoiceID == invoiceID
+	}), nil
+}
+
+// restock.sql
+
+// restockDue is "restock_pending" AND ("restock_retry_at" IS NULL OR
+// "restock_retry_at" <= now()) AND "restock_attempts" < max_attempts.
+func restockDue(order repository.Order, now time.Time, maxAttempts int32) bool {
+	return order.RestockPending && (!order.RestockRetryAt.Valid || !order.RestockRetryAt.Time.After(now)) &&
+		order.RestockAttempts < maxAttempts
+}
+
+// claimRestock counts an attempt and puts the next one off, twice as long
+// as the one before up to 64 times retryAfterSeconds.
+func claimRestock(now time.Time, retryAfterSeconds int32) func(*repository.Order) {
+	return func(order *repository.Order) {
+		backoff := order.RestockAttempts
+		if backoff > 6 {
+			backoff = 6
+		}
+		order.RestockAttempts++
+		order.RestockRetryAt = validTime(now.Add(time.Duration(retryAfterSeconds) * time.Second << backoff))
+	}
+}
+
+func (q memoryQueries) ClaimOrderRestock(_ context.Context, arg repository.ClaimOrderRestockParams) (repository.Order, error) {
+	defer q.lock()()
+	now := q.store.now()
+	return q.updateOrder(arg.ID, func(order repository.Order) bool {
+		return restockDue(order, now, arg.MaxAttempts)
+	}, claimRestock(now, arg.RetryAfterSeconds))
+}
+
+func (q memoryQueries) ClaimDueOrderRestock(_ context.Context, arg repository.ClaimDueOrderRestockParams) ([]repository.Order, error) {
+	defer q.lock()()
+	now := q.store.now()
+	listOrder := q.orders(func(order repository.Order) bool {
+		return restockDue(order, now, arg.MaxAttempts)
+	})
+	// ORDER BY "restock_retry_at" NULLS FIRST, "id"
+	sort.SliceStable(listOrder, func(i, j int) bool {
+		a, b := listOrder[i].RestockRetryAt, listOrder[j].RestockRetryAt
+		if a.Valid != b.Valid {
+			return !a.Valid
+		}
+		return a.Time.Before(b.Time)
+	})
+	if len(listOrder) > int(arg.BatchSize) {
+		listOrder = listOrder[:arg.BatchSize]
+	}
+	var items []repository.Order
+	for _, order := range listOrder {
+		order, err := q.updateOrder(order.ID, func(repository.Order) bool { return true }, claimRestock(now, arg.RetryAfterSeconds))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, order)
+	}
+	return items, nil
+}
+
+func (q memoryQueries) FinishOrderRestock(_ context.Context, id int64) error {
+	defer q.lock()()
+	_, err := q.updateOrder(id, func(repository.Order) bool { return true }, func(order *repository.Order) {
+		order.RestockPending = false
+		order.RestockRetryAt = sql.NullTime{}
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+func (q memoryQueries) RetryOrderRestock(_ context.Context, arg repository.RetryOrderRestockParams) error {
+	defer q.lock()()
+	_, err := q.updateOrder(arg.ID, func(repository.Order) bool { return true }, func(order *repository.Order) {
+		order.RestockPending = true
+		order.RestockRetryAt = arg.RestockRetryAt
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+// return.sql
+
+func (q memoryQueries) CreateReturnRequest(_ context.Context, arg repository.CreateReturnRequestParams) (repository.ReturnRequest, error) {
+	defer q.lock()()
+	d := q.d()
+	now := q.store.now()
+	orderReturn := repository.ReturnRequest{
+		ID:         d.nextID("return_request"),
+		CustomerID: arg.CustomerID,
+		SupplierID: arg.SupplierID,
+		Reason:     arg.Reason,
+		Status:     repository.ReturnStatusEnumRequested,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	d.returnRequests[orderReturn.ID] = orderReturn
+	return orderReturn, nil
+}
+
+func (q memoryQueries) CreateReturnItem(_ context.Context, arg repository.CreateReturnItemParams) (repository.ReturnItem, error) {
+	defer q.lock()()
+	d := q.d()
+	if _, ok := d.returnRequests[arg.ReturnID]; !ok {
+		return repository.ReturnItem{}, fmt.Errorf("memory store: return %d does not exist", arg.ReturnID)
+	}
+	if _, ok := d.orders[arg.OrderID]; !ok {
+		return repository.ReturnItem{}, fmt.Errorf("memory store: order %d does not exist", arg.OrderID)
+	}
+	if arg.Quantity <= 0 {
+		return repository.ReturnItem{}, fmt.Errorf("memory store: return quantity %d is not positive", arg.Quantity)
+	}
+	item := repository.ReturnItem{
+		ID:       d.nextID("return_item"),
+		ReturnID: arg.ReturnID,
+		OrderID:  arg.OrderID,
+		Quantity: arg.Quantity,
+	}
+	d.returnItems[item.ID] = item
+	return item, nil
+}
+
+func (q memoryQueries) GetReturnRequestByID(_ context.Context, id int64) (repository.ReturnRequest, error) {
+	defer q.lock()()
+	orderReturn, ok := q.d().returnRequests[id]
+	if !ok {
+		return repository.ReturnRequest{}, sql.ErrNoRows
+	}
+	return orderReturn, nil
+}
+
+func (q memoryQueries) GetReturnItemByReturn(_ context.Context, returnID int64) ([]repository.ReturnItem, error) {
+	defer q.lock()()
+	return rows(q.d().returnItems, func(item repository.ReturnItem) bool {
+		return item.ReturnID == returnID
+	}), nil
+}
+
+func (q memoryQueries) GetReturnRequestByCustomer(_ context.Context, customerID int64) ([]repository.ReturnRequest, error) {
+	defer q.lock()()
+	return reverse(rows(q.d().returnRequests, func(orderReturn repository.ReturnRequest) bool {
+		return orderReturn.CustomerID == customerID
+	})), nil
+}
+
+func (q memoryQueries) GetReturnRequestBySupplier(_ context.Context, supplierID int64) ([]repository.ReturnRequest, error) {
+	defer q.lock()()
+	return reverse(rows(q.d().returnRequests, func(orderReturn repository.ReturnRequest) bool {
+		return orderReturn.SupplierID == supplierID
+	})), nil
+}
+
+func (q memoryQueries) UpdateReturnRequestStatus(_ context.Context, arg repository.UpdateReturnRequestStatusParams) (repository.ReturnRequest, error) {
+	defer q.lock()()
+	d := q.d()
+	orderReturn, ok := d.returnRequests[arg.ID]
+	if !ok || orderReturn.Status != arg.OldStatus {
+		return repository.ReturnRequest{}, sql.ErrNoRows
+	}
+	orderReturn.Status = arg.NewStatus
+	orderReturn.Note = arg.Note
+	orderReturn.UpdatedAt = q.store.now()
+	d.returnRequests[orderReturn.ID] = orderReturn
+	return orderReturn, nil
+}
+
+func (q memoryQueries) GetReturnedQuantityByOrder(_ context.Context, orderID int64) (int32, error) {
+	defer q.lock()()
+	d := q.d()
+	var returned int32
+	for _, item := range d.returnItems {
+		if item.OrderID == orderID && d.returnRequests[item.ReturnID].Status != repository.ReturnStatusEnumRejected {
+			returned += item.Quantity
+		}
+	}
+	return returned, nil
+}
+
+func (q memoryQueries) CreateRefund(_ context.Context, arg repository.CreateRefundParams) (repository.Refund, error) {
+	defer q.lock()()
+	d := q.d()
+	if _, ok := d.returnRequests[arg.ReturnID]; !ok {
+		return repository.Refund{}, fmt.Errorf("memory store: return %d does not exist", arg.ReturnID)
+	}
+	if _, ok := d.orders[arg.OrderID]; !ok {
+		return repository.Refund{}, fmt.Errorf("memory store: order %d does not exist", arg.OrderID)
+	}
+	refund := repository.Refund{
+		ID:        d.nextID("refund"),
+		ReturnID:  arg.ReturnID,
+		OrderID:   arg.OrderID,
+		Amount:    arg.Amount,
+		PaymentID: arg.PaymentID,
+		CreatedAt: q.store.now(),
+		Status:    arg.Status,
+	}
+	d.refunds[refund.ID] = refund
+	return refund, nil
+}
+
+func (q memoryQueries) GetRefundByReturn(_ context.Context, returnID int64) ([]repository.Refund, error) {
+	defer q.lock()()
+	return rows(q.d().refunds, func(refund repository.Refund) bool {
+		return refund.ReturnID == returnID
+	}), nil
+}
+
+func (q memoryQueries) GetRefundedAmountByOrder(_ context.Context, orderID int64) (int64, error) {
+	defer q.lock()()
+	var refunded int64
+	for _, refund := range q.d().refunds {
+		if refund.OrderID == orderID && refund.Status == repository.RefundStatusEnumRefunded {
+			refunded += refund.Amount
+		}
+	}
+	return refunded, nil
+}
+
+// refundDue is "status" = 'pending' AND ("retry_at" IS NULL OR
+// "retry_at" <= now()) AND "attempts" < max_attempts.
+func refundDue(refund repository.Refund, now time.Time, maxAttempts int32) bool {
+	return refund.Status == repository.RefundStatusEnumPending && (!refund.RetryAt.Valid || !refund.RetryAt.Time.After(now)) &&
+		refund.Attempts < maxAttempts
+}
+
+// claimRefund counts an attempt and puts the next one off like claimRestock.
+func claimRefund(refund repository.Refund, now time.Time, retryAfterSeconds int32) repository.Refund {
+	backoff := refund.Attempts
+	if backoff > 6 {
+		backoff = 6
+	}
+	refund.Attempts++
+	refund.RetryAt = validTime(now.Add(time.Duration(retryAfterSeconds) * time.Second << backoff))
+	return refund
+}
+
+func (q memoryQueries) ClaimRefund(_ context.Context, arg repository.ClaimRefundParams) (repository.Refund, error) {
+	defer q.lock()()
+	d := q.d()
+	now := q.store.now()
+	refund, ok := d.refunds[arg.ID]
+	if !ok || !refundDue(refund, now, arg.MaxAttempts) {
+		return repository.Refund{}, sql.ErrNoRows
+	}
+	refund = claimRefund(refund, now, arg.RetryAfterSeconds)
+	d.refunds[refund.ID] = refund
+	return refund, nil
+}
+
+func (q memoryQueries) ClaimDueRefund(_ context.Context, arg repository.ClaimDueRefundParams) ([]repository.Refund, error) {
+	defer q.lock()()
+	d := q.d()
+	now := q.store.now()
+	listRefund := rows(d.refunds, func(refund repository.Refund) bool {
+		return refundDue(refund, now, arg.MaxAttempts)
+	})
+	// ORDER BY "retry_at" NULLS FIRST, "id"
+	sort.SliceStable(listRefund, func(i, j int) bool {
+		a, b := listRefund[i].RetryAt, listRefund[j].RetryAt
+		if a.Valid != b.Valid {
+			return !a.Valid
+		}
+		return a.Time.Before(b.Time)
+	})
+	if len(listRefund) > int(arg.BatchSize) {
+		listRefund = listRefund[:arg.BatchSize]
+	}
+	var items []repository.Refund
+	for _, refund := range listRefund {
+		refund = claimRefund(refund, now, arg.RetryAfterSeconds)
+		d.refunds[refund.ID] = refund
+		items = append(items, refund)
+	}
+	return items, nil
+}
+
+func (q memoryQueries) FinishRefund(_ context.Context, id int64) error {
+	defer q.lock()()
+	d := q.d()
+	refund, ok := d.refunds[id]
+	if !ok || refund.Status != repository.RefundStatusEnumPending {
+		return nil
+	}
+	refund.Status = repository.RefundStatusEnumRefunded
+	refund.RetryAt = sql.NullTime{}
+	d.refunds[id] = refund
+	return nil
+}
+
+// sales_report.sql
+
+// supplierSales returns the orders of the supplier created in the range.
+func (q memoryQueries) supplierSales(supplierID int64, from, to time.Time) []repository.Order {
+	return q.orders(func(order repository.Order) bool {
+		return order.SupplierID == supplierID && inRange(order.CreatedAt, validTime(from), validTime(to))
+	})
+}
+
+func (q memoryQueries) GetSupplierSalesSeries(_ context.Context, arg repository.GetSupplierSalesSeriesParams) ([]repository.GetSupplierSalesSeriesRow, error) {
+	defer q.lock()()
+	loc, err := time.LoadLocation(arg.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int64]repository.GetSupplierSalesSeriesRow)
+	for _, order := range q.supplierSales(arg.SupplierID, arg.CreatedFrom, arg.CreatedTo) {
+		if !isHandledStatus(order.Status) {
+			continue
+		}
+		start := reportBucketStart(order.CreatedAt, arg.Bucket, loc)
+		row := m[start.Unix()]
+		row.BucketStart = start
+		row.OrderCount++
+		row.UnitCount += int64(order.Quantity)
+		row.Revenue += order.Price*int64(order.Quantity) - order.Discount
+		m[start.Unix()] = row
+	}
+	var items []repository.GetSupplierSalesSeriesRow
+	for _, row := range m {
+		items = append(items, row)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].BucketStart.Before(items[j].BucketStart) })
+	return items, nil
+}
+
+func (q memoryQueries) GetSupplierTopProducts(_ context.Context, arg repository.GetSupplierTopProductsParams) ([]repository.GetSupplierTopProductsRow, error) {
+	defer q.lock()()
+	m := make(map[int64]repository.GetSupplierTopProductsRow)
+	for _, order := range q.supplierSales(arg.SupplierID, arg.CreatedFrom, arg.CreatedTo) {
+		if !isHandledStatus(order.Status) {
+			continue
+		}
+		row := m[order.ProductID]
+		row.ProductID = order.ProductID
+		if order.ProductName > row.ProductName {
+			row.ProductName = order.ProductName
+		}
+		row.OrderCount++
+		row.UnitCount += int64(order.Quantity)
+		row.Revenue += order.Price*int64(order.Quantity) - order.Discount
+		m[order.ProductID] = row
+	}
+	var items []repository.GetSupplierTopProductsRow
+	for _, row := range m {
+		items = append(items, row)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Revenue != items[j].Revenue {
+			return items[i].Revenue > items[j].Revenue
+		}
+		if items[i].UnitCount != items[j].UnitCount {
+			return items[i].UnitCount > items[j].UnitCount
+		}
+		return items[i].ProductID < items[j].ProductID
+	})
+	if len(items) > int(arg.RowLimit) {
+		items = items[:arg.RowLimit]
+	}
+	return items, nil
+}
+
+func (q memoryQueries) GetSupplierSalesSummary(_ context.Context, arg repository.GetSupplierSalesSummaryParams) (repository.GetSupplierSalesSummaryRow, error) {
+	defer q.lock()()
+	var row repository.GetSupplierSalesSummaryRow
+	var handlingSeconds float64
+	var handledCount int
+	for _, order := range q.supplierSales(arg.SupplierID, arg.CreatedFrom, arg.CreatedTo) {
+		row.OrderCount++
+		if hasStatus(order.Status, repository.OrderStatusEnumCancel) {
+			row.CancelledCount++
+		}
+		if isHandledStatus(order.Status) {
+			row.UnitCount += int64(order.Quantity)
+			row.Revenue += order.Price*int64(order.Quantity) - order.Discount
+		}
+		if order.HandledAt.Valid {
+			handlingSeconds += order.HandledAt.Time.Sub(order.CreatedAt).Seconds()
+			handledCount++
+		}
+	}
+	if handledCount > 0 {
+		row.AverageHandlingSeconds = handlingSeconds / float64(handledCount)
+	}
+	return row, nil
+}
+
+// search.sql
+
+func (q memoryQueries) SearchOrders(_ context.Context, arg repository.SearchOrdersParams) ([]repository.Order, error) {
+	defer q.lock()()
+	d := q.d()
+	items := q.orders(func(order repository.Order) bool {
+		document, ok := d.searchDocuments[order.ID]
+		return ok &&
+			(!arg.Query.Valid || matchSearch(document, arg.Query.String)) &&
+			(!arg.OrderNumber.Valid || order.OrderNumber == arg.OrderNumber.String) &&
+			(!arg.SupplierID.Valid || order.SupplierID == arg.SupplierID.Int64) &&
+			(!arg.CustomerID.Valid || order.CustomerID == arg.CustomerID.Int64) &&
+			hasAnyStatus(order.Status, arg.ListStatus) &&
+			inRange(order.CreatedAt, arg.CreatedFrom, arg.CreatedTo)
+	})
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.After(items[j].CreatedAt)
+		}
+		return items[i].ID > items[j].ID
+	})
+	if int(arg.RowOffset) >= len(items) {
+		return nil, nil
+	}
+	items = items[arg.RowOffset:]
+	if len(items) > int(arg.RowLimit) {
+		items = items[:arg.RowLimit]
+	}
+	return items, nil
+}
+
+// shipment.sql
+
+func (q memoryQueries) CreateShipment(_ context.Context, arg repository.CreateShipmentParams) (repository.Shipment, error) {
+	defer q.lock()()
+	d := q.d()
+	shipment := repository.Shipment{
+		ID:             d.nextID("shipment"),
+		SupplierID:     arg.SupplierID,
+		Carrier:        arg.Carrier,
+		TrackingNumber: arg.TrackingNumber,
+		Status:         repository.ShipmentStatusEnumPending,
+		CreatedAt:      q.store.now(),
+	}
+	d.shipments[shipment.ID] = shipment
+	return shipment, nil
+}
+
+func (q memoryQueries) CreateShipmentItem(_ context.Context, arg repository.CreateShipmentItemParams) (repository.ShipmentItem, error) {
+	defer q.lock()()
+	d := q.d()
+	if _, ok := d.shipments[arg.ShipmentID]; !ok {
+		return repository.ShipmentItem{}, fmt.Errorf("memory store: shipment %d does not exist", arg.ShipmentID)
+	}
+	if _, ok := d.orders[arg.OrderID]; !ok {
+		return repository.ShipmentItem{}, fmt.Errorf("memory store: order %d does not exist", arg.OrderID)
+	}
+	if arg.Quantity <= 0 {
+		return repository.ShipmentItem{}, fmt.Errorf("memory store: shipment quantity %d is not positive", arg.Quantity)
+	}
+	item := repository.ShipmentItem{
+		ID:         d.nextID("shipment_item"),
+		ShipmentID: arg.ShipmentID,
+		OrderID:    arg.OrderID,
+		Quantity:   arg.Quantity,
+	}
+	d.shipmentItems[item.ID] = item
+	return item, nil
+}
+
+func (q memoryQueries) GetShipmentByID(_ context.Context, id int64) (repository.Shipment, error) {
+	defer q.lock()()
+	shipment, ok := q.d().shipments[id]
+	if !ok {
+		return repository.Shipment{}, sql.ErrNoRows
+	}
+	return shipment, nil
+}
+
+func (q memoryQueries) GetShipmentItemByShipment(_ context.Context, shipmentID int64) ([]repository.ShipmentItem, error) {
+	defer q.lock()()
+	return rows(q.d().shipmentItems, func(item repository.ShipmentItem) bool {
+		return item.ShipmentID == shipmentID
+	}), nil
+}
+
+func (q memoryQueries) GetShipmentByOrder(_ context.Context, orderID int64) ([]repository.Shipment, error) {
+	defer q.lock()()
+	d := q.d()
+	m := make(map[int64]bool)
+	for _, item := range d.shipmentItems {
+		if item.OrderID == orderID {
+			m[item.ShipmentID] = true
+		}
+	}
+	return rows(d.shipments, func(shipment repository.Shipment) bool {
+		return m[shipment.ID]
+	}), nil
+}
+
+// updateShipment applies set to the shipment when where accepts it as it is.
+func (q memoryQueries) updateShipment(id int64, where func(repository.Shipment) bool, set func(*repository.Shipment)) (repository.Shipment, error) {
+	d := q.d()
+	shipment, ok := d.shipments[id]
+	if !ok || !where(shipment) {
+		return repository.Shipment{}, sql.ErrNoRows
+	}
+	set(&shipment)
+	d.shipments[id] = shipment
+	return shipment, nil
+}
+
+func (q memoryQueries) SetShipmentTrackingNumber(_ context.Context, arg repository.SetShipmentTrackingNumberParams) (repository.Shipment, error) {
+	defer q.lock()()
+	return q.updateShipment(arg.ID, func(shipment repository.Shipment) bool { return shipment.TrackingNumber == "" }, func(shipment *repository.Shipment) {
+		shipment.TrackingNumber = arg.TrackingNumber
+	})
+}
+
+func (q memoryQueries) ShipShipment(_ context.Context, arg repository.ShipShipmentParams) (repository.Shipment, error) {
+	defer q.lock()()
+	return q.updateShipment(arg.ID, func(shipment repository.Shipment) bool {
+		return shipment.Status == repository.ShipmentStatusEnumPending || shipment.Status == repository.ShipmentStatusEnumShipped
+	}, func(shipment *repository.Shipment) {
+		shipment.Carrier = arg.Carrier
+		shipment.TrackingNumber = arg.TrackingNumber
+		shipment.Status = repository.ShipmentStatusEnumShipped
+		if !shipment.ShippedAt.Valid {
+			shipment.ShippedAt = validTime(q.store.now())
+		}
+	})
+}
+
+func (q memoryQueries) DeliverShipment(_ context.Context, id int64) (repository.Shipment, error) {
+	defer q.lock()()
+	return q.updateShipment(id, func(shipment repository.Shipment) bool {
+		return shipment.Status == repository.ShipmentStatusEnumShipped
+	}, func(shipment *repository.Shipment) {
+		shipment.Status = repository.ShipmentStatusEnumDelivered
+		shipment.DeliveredAt = validTime(q.store.now())
+	})
+}
+
+func (q memoryQueries) GetShipmentQuantityByOrder(_ context.Context, orderID int64) (repository.GetShipmentQuantityByOrderRow, error) {
+	defer q.lock()()
+	d := q.d()
+	var row repository.GetShipmentQuantityByOrderRow
+	for _, item := range d.shipmentItems {
+		if item.OrderID != orderID {
+			continue
+		}
+		row.Allocated += item.Quantity
+		switch d.shipments[item.ShipmentID].Status {
+		case repository.ShipmentStatusEnumShipped:
+			row.Shipped += item.Quantity
+		case repository.ShipmentStatusEnumDelivered:
+			row.Shipped += item.Quantity
+			row.Delivered += item.Quantity
+		}
+	}
+	return row, nil
+}
+
+// stale_order.sql
+
+func (q memoryQueries) LockStaleWaitingOrder(_ context.Context, arg repository.LockStaleWaitingOrderParams) ([]repository.Order, error) {
+	defer q.lock()()
+	d := q.d()
+	now := q.store.now()
+	items := q.orders(func(order repository.Order) bool {
+		deadlineHours := arg.DefaultDeadlineHours
+		if setting, ok := d.orderSettings[order.SupplierID]; ok {
+			deadlineHours = setting.HandleDeadlineHours
+		}
+		return hasStatus(order.Status, repository.OrderStatusEnumWaiting) && !order.HeldAt.Valid && order.ID > arg.AfterID &&
+			order.CreatedAt.Before(now.Add(-time.Duration(deadlineHours)*time.Hour))
+	})
+	if len(items) > int(arg.BatchSize) {
+		items = items[:arg.BatchSize]
+	}
+	return items, nil
+}
+
+func (q memoryQueries) CancelWaitingOrder(_ context.Context, arg repository.CancelWaitingOrderParams) (repository.Order, error) {
+	defer q.lock()()
+	return q.updateOrder(arg.ID, func(order repository.Order) bool {
+		return hasStatus(order.Status, repository.OrderStatusEnumWaiting)
+	}, func(order *repository.Order) {
+		order.Status = orderStatus(repository.OrderStatusEnumCancel)
+		order.CancelReason = arg.CancelReason
+		order.RestockPending = true
+	})
+}
+
+func (q memoryQueries) UpsertSupplierOrderSetting(_ context.Context, arg repository.UpsertSupplierOrderSettingParams) (repository.SupplierOrderSetting, error) {
+	defer q.lock()()
+	if arg.HandleDeadlineHours <= 0 {
+		return repository.SupplierOrderSetting{}, fmt.Errorf("memory store: handle deadline %d is not positive", arg.HandleDeadlineHours)
+	}
+	setting := repository.SupplierOrderSetting{
+		SupplierID:          arg.SupplierID,
+		HandleDeadlineHours: arg.HandleDeadlineHours,
+		UpdatedAt:           q.store.now(),
+	}
+	q.d().orderSettings[setting.SupplierID] = setting
+	return setting, nil
+}
+
+func (q memoryQueries) GetSupplierOrderSetting(_ context.Context, supplierID int64) (repository.SupplierOrderSetting, error) {
+	defer q.lock()()
+	setting, ok := q.d().orderSettings[supplierID]
+	if !ok {
+		return repository.SupplierOrderSetting{}, sql.ErrNoRows
+	}
+	return setting, nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed db/migration/*.sql
+var migrations embed.FS
+
+// newMigrate opens its own connection to the database, the postgres driver
+// of migrate closes it with the Migrate. Up, Down and Force hold a Postgres
+// advisory lock, the pods starting together run the migrations one by one.
+func newMigrate(dsn string) (*migrate.Migrate, error) {
+	source, err := iofs.New(migrations, "db/migration")
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return migrate.NewWithInstance("iofs", source, "postgres", driver)
+}
+
+// expectedSchemaVersion is the newest migration embedded in the binary.
+func expectedSchemaVersion() (uint, error) {
+	source, err := iofs.New(migrations, "db/migration")
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// schemaVersion returns the version of the database, 0 before the first
+// migration.
+func schemaVersion(m *migrate.Migrate) (uint, bool, error) {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// checkSchemaVersion refuses a database older than the binary, or left dirty
+// by a failed migration. With autoMigrate the missing migrations are applied
+// first. A newer schema is accepted, the previous release keeps serving while
+// the next one is rolled out.
+func checkSchemaVersion(dsn string, autoMigrate bool) error {
+	expected, err := expectedSchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	m, err := newMigrate(dsn)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if autoMigrate {
+		err := m.Up()
+		if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("auto migrate: %w", err)
+		}
+	}
+
+	version, dirty, err := schemaVersion(m)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("database schema version %d is dirty, fix it and run migrate force", version)
+	}
+	if version < expected {
+		return fmt.Errorf("database schema version %d is older than %d, run migrate up", version, expected)
+	}
+	if version > expected {
+		log.Printf("database schema version %d is newer than %d", version, expected)
+	}
+	return nil
+}
+
+// runMigrateCommand runs "migrate up [n]", "migrate down [n]",
+// "migrate status" or "migrate force <version>" with the embedded migrations.
+func runMigrateCommand(dsn string, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up [n] | down [n] | status | force <version>")
+	}
+
+	// the step count of up and down, the version of force. Forcing -1 marks
+	// the database as without any migration
+	n := 0
+	if len(args) > 1 {
+		var err error
+		n, err = strconv.Atoi(args[1])
+		if err != nil || n < 0 && !(args[0] == "force" && n == database.NilVersion) {
+			return fmt.Errorf("invalid number: %s", args[1])
+		}
+	}
+
+	m, err := newMigrate(dsn)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	m.Log = migrateLogger{}
+
+	switch args[0] {
+	case "up":
+		if n > 0 {
+			err = m.Steps(n)
+		} else {
+			err = m.Up()
+		}
+	case "down":
+		// without a count only the last migration is reverted, dropping the
+		// whole schema has to be asked for
+		if n == 0 {
+			n = 1
+		}
+		err = m.Steps(-n)
+	case "force":
+		if len(args) < 2 {
+			return errors.New("usage: migrate force <version>, -1 for none")
+		}
+		err = m.Force(n)
+	case "status":
+		return printMigrateStatus(m)
+	default:
+		return fmt.Errorf("unknown migrate command: %s", args[0])
+	}
+	if errors.Is(err, migrate.ErrNoChange) {
+		log.Println("no change")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return printMigrateStatus(m)
+}
+
+func printMigrateStatus(m *migrate.Migrate) error {
+	expected, err := expectedSchemaVersion()
+	if err != nil {
+		return err
+	}
+	version, dirty, err := schemaVersion(m)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("database version: %d", version)
+	if dirty {
+		fmt.Print(" (dirty)")
+	}
+	fmt.Printf("\nbinary version: %d\n", expected)
+	return nil
+}
+
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...interface{}) {
+	log.Printf(format, v...)
+}
+
+func (migrateLogger) Verbose() bool {
+	return true
+}
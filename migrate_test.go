@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunMigrateCommandArgs(t *testing.T) {
+	// a database that can't be reached, the arguments are checked first
+	dsn := "host=127.0.0.1 port=1 user=order dbname=order sslmode=disable connect_timeout=1"
+	for _, tc := range []struct {
+		args    []string
+		invalid bool
+	}{
+		{[]string{"up", "-1"}, true},
+		{[]string{"down", "x"}, true},
+		{[]string{"force", "-2"}, true},
+		{[]string{"force", "-1"}, false},
+		{[]string{"force", "3"}, false},
+	} {
+		err := runMigrateCommand(dsn, tc.args)
+		if err == nil {
+			t.Errorf("migrate %v ran without a database", tc.args)
+			continue
+		}
+		if invalid := strings.HasPrefix(err.Error(), "invalid number"); invalid != tc.invalid {
+			t.Errorf("migrate %v: %v", tc.args, err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+// order numbers look like ORD-20261018-7KQ2XME: the day of the order, six
+// random characters and a check character, in the Crockford base32 alphabet
+// which has no I, L, O or U to misread. The orders created before have four
+// random characters and the check of legacyOrderNumberCheck.
+const (
+	orderNumberPrefix       = "ORD-"
+	orderNumberAlphabet     = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	orderNumberRandom       = 6
+	orderNumberLength       = len("ORD-20060102-XXXXXXX")
+	legacyOrderNumberLength = len("ORD-20060102-XXXXX")
+)
+
+// newOrderNumber returns a new order number for an order created at t. It
+// is only unique with high probability, the caller checks it isn't taken.
+func newOrderNumber(t time.Time) (string, error) {
+	random := make([]byte, orderNumberRandom)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	date := t.Format("20060102")
+	code := make([]byte, 0, len(random)+1)
+	for _, b := range random {
+		code = append(code, orderNumberAlphabet[b%32])
+	}
+	code = append(code, orderNumberCheck(date+string(code)))
+	return orderNumberPrefix + date + "-" + string(code), nil
+}
+
+// takeOrderNumber returns a new order number no order has yet. Two checkouts
+// drawing the same number at the same time are stopped by the unique index.
+func takeOrderNumber(ctx context.Context, q repository.Querier) (string, error) {
+	for i := 0; i < 5; i++ {
+		number, err := newOrderNumber(time.Now())
+		if err != nil {
+			return "", err
+		}
+		_, err = q.GetOrderByNumber(ctx, number)
+		if errors.Is(err, sql.ErrNoRows) {
+			return number, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", errors.New("no free order number")
+}
+
+// orderNumbers maps the ids of orders to their numbers.
+func (srv orderService) orderNumbers(ctx context.Context, listID []int64) (map[int64]string, error) {
+	listRow, err := srv.orderStore.GetOrderNumberByIDs(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int64]string, len(listRow))
+	for _, row := range listRow {
+		m[row.ID] = row.OrderNumber
+	}
+	return m, nil
+}
+
+// orderNumberCheck is the check character of the Damm algorithm over the 32
+// characters of the alphabet, taken as the elements of GF(32): every
+// character c takes the check so far to 2·check + c. It catches any mistyped
+// character and any two swapped neighbours, the check character included.
+func orderNumberCheck(body string) byte {
+	check := 0
+	for i := 0; i < len(body); i++ {
+		check = gf32Double(check) ^ strings.IndexByte(orderNumberAlphabet, body[i])
+	}
+	return orderNumberAlphabet[gf32Double(check)]
+}
+
+// gf32Double multiplies x by 2 in GF(32), modulo x^5 + x^2 + 1.
+func gf32Double(x int) int {
+	x <<= 1
+	if x&32 != 0 {
+		x ^= 0x25
+	}
+	return x
+}
+
+// legacyOrderNumberCheck is the check of the order numbers with four random
+// characters, the weighted sum of the characters modulo 31. It can't tell 0
+// from Z, the migration 000010_add_order_number computes it the same way.
+func legacyOrderNumberCheck(body string) byte {
+	total := 0
+	for i := 0; i < len(body); i++ {
+		total += (i + 1) * strings.IndexByte(orderNumberAlphabet, body[i])
+	}
+	return orderNumberAlphabet[total%31]
+}
+
+// parseOrderNumber normalizes an order number typed by a person and checks
+// it, ok is false when it can't be one.
+func parseOrderNumber(s string) (number string, ok bool) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) != orderNumberLength && len(s) != legacyOrderNumberLength ||
+		!strings.HasPrefix(s, orderNumberPrefix) || s[12] != '-' {
+		return "", false
+	}
+
+	date := s[4:12]
+	if _, err := time.Parse("20060102", date); err != nil {
+		return "", false
+	}
+	// the letters base32 leaves out are read as the digits they look like
+	code := strings.NewReplacer("O", "0", "I", "1", "L", "1").Replace(s[13:])
+	for i := 0; i < len(code); i++ {
+		if strings.IndexByte(orderNumberAlphabet, code[i]) < 0 {
+			return "", false
+		}
+	}
+	check := orderNumberCheck
+	if len(s) == legacyOrderNumberLength {
+		check = legacyOrderNumberCheck
+	}
+	last := len(code) - 1
+	if check(date+code[:last]) != code[last] {
+		return "", false
+	}
+	return orderNumberPrefix + date + "-" + code, true
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+func TestParseOrderNumber(t *testing.T) {
+	number, err := newOrderNumber(time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(number) != orderNumberLength || !strings.HasPrefix(number, "ORD-20261018-") {
+		t.Fatalf("newOrderNumber = %s", number)
+	}
+	for _, s := range []string{number, strings.ToLower(number), " " + number + "\n", "ORD-20261018-7KQ2XME"} {
+		if got, ok := parseOrderNumber(s); !ok || got != strings.ToUpper(strings.TrimSpace(s)) {
+			t.Errorf("parseOrderNumber(%q) = %s, %v", s, got, ok)
+		}
+	}
+	// the letters base32 leaves out are read as digits
+	check := string(orderNumberCheck("20261018" + "10KQ2X"))
+	if got, ok := parseOrderNumber("ORD-20261018-IOKQ2X" + check); !ok || got != "ORD-20261018-10KQ2X"+check {
+		t.Errorf("parseOrderNumber with misread letters = %s, %v", got, ok)
+	}
+	// the numbers of the orders created before
+	legacy := "ORD-20261018-7KQ2" + string(legacyOrderNumberCheck("202610187KQ2"))
+	if got, ok := parseOrderNumber(legacy); !ok || got != legacy {
+		t.Errorf("parseOrderNumber(%s) = %s, %v", legacy, got, ok)
+	}
+	for _, s := range []string{"", "ORD-20261318-7KQ2XME", "ORD-20261018-7KQ2XM", "ORD-20261018+7KQ2XME", "ORD-20261018-7KQ2XMU"} {
+		if got, ok := parseOrderNumber(s); ok {
+			t.Errorf("parseOrderNumber(%q) = %s, want it refused", s, got)
+		}
+	}
+}
+
+func TestOrderNumberCheckErrors(t *testing.T) {
+	body := "20261018" + "0Z7KQ2"
+	number := body + string(orderNumberCheck(body))
+	valid := func(s string) bool {
+		return orderNumberCheck(s[:len(s)-1]) == s[len(s)-1]
+	}
+
+	// every mistyped character, the check character included
+	for i := 0; i < len(number); i++ {
+		for j := 0; j < len(orderNumberAlphabet); j++ {
+			c := orderNumberAlphabet[j]
+			if c == number[i] {
+				continue
+			}
+			typo := number[:i] + string(c) + number[i+1:]
+			if valid(typo) {
+				t.Errorf("%s is accepted for %s", typo, number)
+			}
+		}
+	}
+	// every swap of two different neighbours, 0 and Z too
+	for i := 0; i+1 < len(number); i++ {
+		if number[i] == number[i+1] {
+			continue
+		}
+		swapped := number[:i] + string(number[i+1]) + string(number[i]) + number[i+2:]
+		if valid(swapped) {
+			t.Errorf("%s is accepted for %s", swapped, number)
+		}
+	}
+}
+
+// takenNumberQuerier finds an order for every number.
+type takenNumberQuerier struct {
+	repository.Querier
+	lookups int
+}
+
+func (q *takenNumberQuerier) GetOrderByNumber(_ context.Context, orderNumber string) (repository.Order, error) {
+	q.lookups++
+	return repository.Order{OrderNumber: orderNumber}, nil
+}
+
+func TestTakeOrderNumberGivesUp(t *testing.T) {
+	q := &takenNumberQuerier{}
+	if number, err := takeOrderNumber(context.Background(), q); err == nil {
+		t.Fatalf("takeOrderNumber = %s while every number is taken", number)
+	}
+	if q.lookups != 5 {
+		t.Errorf("takeOrderNumber tried %d numbers, want 5", q.lookups)
+	}
+}
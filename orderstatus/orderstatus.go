@@ -0,0 +1,57 @@
+// Package orderstatus converts the status of an order between the database
+// enum and the API enum. Both enums have the same values, but the names are
+// not trusted to match: a value one side doesn't know is an error, and is
+// shown as unknown_status rather than as a real status.
+package orderstatus
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+// ToPb converts a database status to the API one.
+func ToPb(status repository.OrderStatusEnum) (pb.OrderStatus, error) {
+	switch status {
+	case repository.OrderStatusEnumWaiting:
+		return pb.OrderStatus_waiting, nil
+	case repository.OrderStatusEnumHandled:
+		return pb.OrderStatus_handled, nil
+	case repository.OrderStatusEnumCancel:
+		return pb.OrderStatus_cancel, nil
+	case repository.OrderStatusEnumShipped:
+		return pb.OrderStatus_shipped, nil
+	case repository.OrderStatusEnumDelivered:
+		return pb.OrderStatus_delivered, nil
+	}
+	return pb.OrderStatus_unknown_status, fmt.Errorf("unknown order status %q", status)
+}
+
+// NullToPb converts the nullable status column, an order without status is
+// an error.
+func NullToPb(status repository.NullOrderStatusEnum) (pb.OrderStatus, error) {
+	if !status.Valid {
+		return pb.OrderStatus_unknown_status, errors.New("order has no status")
+	}
+	return ToPb(status.OrderStatusEnum)
+}
+
+// FromPb converts an API status to the database one, unknown_status has no
+// database status.
+func FromPb(status pb.OrderStatus) (repository.OrderStatusEnum, error) {
+	switch status {
+	case pb.OrderStatus_waiting:
+		return repository.OrderStatusEnumWaiting, nil
+	case pb.OrderStatus_handled:
+		return repository.OrderStatusEnumHandled, nil
+	case pb.OrderStatus_cancel:
+		return repository.OrderStatusEnumCancel, nil
+	case pb.OrderStatus_shipped:
+		return repository.OrderStatusEnumShipped, nil
+	case pb.OrderStatus_delivered:
+		return repository.OrderStatusEnumDelivered, nil
+	}
+	return "", fmt.Errorf("unknown order status %d", status)
+}
@@ -0,0 +1,80 @@
+package orderstatus
+
+import (
+	"testing"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+func TestToPb(t *testing.T) {
+	for _, tc := range []struct {
+		status repository.OrderStatusEnum
+		want   pb.OrderStatus
+	}{
+		{repository.OrderStatusEnumWaiting, pb.OrderStatus_waiting},
+		{repository.OrderStatusEnumHandled, pb.OrderStatus_handled},
+		{repository.OrderStatusEnumCancel, pb.OrderStatus_cancel},
+		{repository.OrderStatusEnumShipped, pb.OrderStatus_shipped},
+		{repository.OrderStatusEnumDelivered, pb.OrderStatus_delivered},
+	} {
+		got, err := ToPb(tc.status)
+		if err != nil || got != tc.want {
+			t.Errorf("ToPb(%q) = %v, %v, want %v", tc.status, got, err, tc.want)
+		}
+		back, err := FromPb(got)
+		if err != nil || back != tc.status {
+			t.Errorf("FromPb(%v) = %q, %v, want %q", got, back, err, tc.status)
+		}
+	}
+
+	for _, status := range []repository.OrderStatusEnum{"", "returned", "Waiting"} {
+		if got, err := ToPb(status); err == nil || got != pb.OrderStatus_unknown_status {
+			t.Errorf("ToPb(%q) = %v, %v, want unknown_status and an error", status, got, err)
+		}
+	}
+}
+
+// every value of the database enum must be known to the API
+func TestToPbAllValues(t *testing.T) {
+	for _, status := range repository.AllOrderStatusEnumValues() {
+		if _, err := ToPb(status); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNullToPb(t *testing.T) {
+	got, err := NullToPb(repository.NullOrderStatusEnum{})
+	if err == nil || got != pb.OrderStatus_unknown_status {
+		t.Errorf("NullToPb(NULL) = %v, %v, want unknown_status and an error", got, err)
+	}
+	got, err = NullToPb(repository.NullOrderStatusEnum{OrderStatusEnum: "returned", Valid: true})
+	if err == nil || got != pb.OrderStatus_unknown_status {
+		t.Errorf("NullToPb(returned) = %v, %v, want unknown_status and an error", got, err)
+	}
+	got, err = NullToPb(repository.NullOrderStatusEnum{OrderStatusEnum: repository.OrderStatusEnumShipped, Valid: true})
+	if err != nil || got != pb.OrderStatus_shipped {
+		t.Errorf("NullToPb(shipped) = %v, %v", got, err)
+	}
+}
+
+// every value of the API enum but unknown_status must be known to the database
+func TestFromPbAllValues(t *testing.T) {
+	for value, name := range pb.OrderStatus_name {
+		status := pb.OrderStatus(value)
+		_, err := FromPb(status)
+		if status == pb.OrderStatus_unknown_status {
+			if err == nil {
+				t.Error("FromPb(unknown_status) is a database status")
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("FromPb(%s): %v", name, err)
+		}
+	}
+	if _, err := FromPb(pb.OrderStatus(42)); err == nil {
+		t.Error("FromPb(42) is a database status")
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+// PaymentProvider is the integration point with a payment gateway. Every
+// order is authorized on its own so it can be captured, voided or refunded
+// independently of the other orders of the same checkout.
+type PaymentProvider interface {
+	// Authorize holds amount on the customer's payment method and returns the payment id.
+	Authorize(ctx context.Context, customerID int64, amount int64) (string, error)
+	// Void releases an authorization that has not been captured.
+	Void(ctx context.Context, paymentID string) error
+	// Capture charges an authorized payment.
+	Capture(ctx context.Context, paymentID string, amount int64) error
+	// Refund gives back amount of a captured payment, it can be called more
+	// than once. The provider refunds a key once, so a refund which may or may
+	// not have gone through is retried with the same key.
+	Refund(ctx context.Context, paymentID string, amount int64, key string) error
+}
+
+type fakePayment struct {
+	amount   int64
+	status   repository.PaymentStatusEnum
+	captured int64
+	refunded int64
+}
+
+// fakePaymentProvider keeps payments in memory and numbers them sequentially,
+// so the same calls always produce the same payment ids. Authorizations above
+// declineOver are declined, a zero declineOver accepts every amount.
+type fakePaymentProvider struct {
+	mu          sync.Mutex
+	next        int64
+	declineOver int64
+	payments    map[string]*fakePayment
+	refundKeys  map[string]bool
+}
+
+func newFakePaymentProvider(declineOver int64) *fakePaymentProvider {
+	return &fakePaymentProvider{
+		declineOver: declineOver,
+		payments:    make(map[string]*fakePayment),
+		refundKeys:  make(map[string]bool),
+	}
+}
+
+func (p *fakePaymentProvider) Authorize(_ context.Context, customerID int64, amount int64) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("fake payment: invalid amount %d", amount)
+	}
+	if p.declineOver > 0 && amount > p.declineOver {
+		return "", fmt.Errorf("fake payment: card of customer %d declined", customerID)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.next++
+	paymentID := fmt.Sprintf("PAY-%08d", p.next)
+	p.payments[paymentID] = &fakePayment{
+		amount: amount,
+		status: repository.PaymentStatusEnumAuthorized,
+	}
+	return paymentID, nil
+}
+
+func (p *fakePaymentProvider) Void(_ context.Context, paymentID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	payment, err := p.get(paymentID, repository.PaymentStatusEnumAuthorized)
+	if err != nil {
+		return err
+	}
+	payment.status = repository.PaymentStatusEnumUnpaid
+	return nil
+}
+
+func (p *fakePaymentProvider) Capture(_ context.Context, paymentID string, amount int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	payment, err := p.get(paymentID, repository.PaymentStatusEnumAuthorized)
+	if err != nil {
+		return err
+	}
+	if amount > payment.amount {
+		return fmt.Errorf("fake payment: capture %d over authorized %d", amount, payment.amount)
+	}
+	payment.status = repository.PaymentStatusEnumCaptured
+	payment.captured = amount
+	return nil
+}
+
+func (p *fakePaymentProvider) Refund(_ context.Context, paymentID string, amount int64, key string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.refundKeys[key] {
+		return nil
+	}
+	payment, err := p.get(paymentID, repository.PaymentStatusEnumCaptured)
+	if err != nil {
+		return err
+	}
+	if payment.refunded+amount > payment.captured {
+		return fmt.Errorf("fake payment: refund %d over captured %d", payment.refunded+amount, payment.captured)
+	}
+	payment.refunded += amount
+	p.refundKeys[key] = true
+	if payment.refunded == payment.captured {
+		payment.status = repository.PaymentStatusEnumRefunded
+	}
+	return nil
+}
+
+func (p *fakePaymentProvider) get(paymentID string, want repository.PaymentStatusEnum) (*fakePayment, error) {
+	payment, ok := p.payments[paymentID]
+	if !ok {
+		return nil, fmt.Errorf("fake payment: unknown payment %s", paymentID)
+	}
+	if payment.status != want {
+		return nil, fmt.Errorf("fake payment: payment %s is %s, not %s", paymentID, payment.status, want)
+	}
+	return payment, nil
+}
+
+// givePaymentBack voids the payment of a cancelled order when it was only
+// authorized and refunds it when it was captured. A payment which failed may
+// still hold its authorization, it is voided too, but as the provider may
+// have dropped it already a void which fails doesn't stop the cancel.
+func (srv orderService) givePaymentBack(ctx context.Context, q repository.Querier, order repository.Order) error {
+	if srv.payment == nil {
+		return nil
+	}
+	var err error
+	var paymentStatus repository.PaymentStatusEnum
+	switch order.PaymentStatus {
+	case repository.PaymentStatusEnumAuthorized:
+		err = srv.payment.Void(ctx, order.PaymentID)
+		paymentStatus = repository.PaymentStatusEnumUnpaid
+	case repository.PaymentStatusEnumFailed:
+		if order.PaymentID == "" {
+			return nil
+		}
+		if err := srv.payment.Void(ctx, order.PaymentID); err != nil {
+			log.Println("can't void failed payment: ", order.PaymentID, err)
+		}
+		paymentStatus = repository.PaymentStatusEnumUnpaid
+	case repository.PaymentStatusEnumCaptured:
+		err = srv.payment.Refund(ctx, order.PaymentID, orderAmount(order), fmt.Sprintf("order-%d", order.ID))
+		paymentStatus = repository.PaymentStatusEnumRefunded
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return q.UpdateOrderPayment(ctx, repository.UpdateOrderPaymentParams{
+		ID:            order.ID,
+		PaymentStatus: paymentStatus,
+		PaymentID:     order.PaymentID,
+	})
+}
+
+// orderAmount is what the customer pays for an order, after its discount
+// and with its shipping fee.
+func orderAmount(order repository.Order) int64 {
+	return goodsAmount(order) + order.ShippingFee
+}
+
+// goodsAmount is what the customer pays for the products of an order, after
+// its discount.
+func goodsAmount(order repository.Order) int64 {
+	return order.Price*int64(order.Quantity) - order.Discount
+}
+
+// refundAmount is the share of goodsAmount for quantity items of the order,
+// so a discounted order is never refunded more than was paid. The shipping
+// fee is only given back when the whole order is cancelled.
+func refundAmount(order repository.Order, quantity int32) int64 {
+	if order.Quantity == 0 {
+		return 0
+	}
+	return goodsAmount(order) * int64(quantity) / int64(order.Quantity)
+}
+
+func toPbPaymentStatus(status repository.PaymentStatusEnum) pb.PaymentStatus {
+	return pb.PaymentStatus(pb.PaymentStatus_value["payment_"+string(status)])
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+func fakePaymentStatus(srv orderService, paymentID string) repository.PaymentStatusEnum {
+	payment := srv.payment.(*fakePaymentProvider)
+	payment.mu.Lock()
+	defer payment.mu.Unlock()
+	return payment.payments[paymentID].status
+}
+
+func TestHandleOrderCaptureFailure(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+
+		// the authorization doesn't cover the order, its capture fails
+		paymentID, err := srv.payment.Authorize(ctx, 1, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		order := testOrder(t, store, func(arg *repository.CreateOrderParams) {
+			arg.ProductID = 1
+			arg.PaymentStatus = repository.PaymentStatusEnumAuthorized
+			arg.PaymentID = paymentID
+		})
+		for i := 0; i < 2; i++ {
+			if _, err := srv.HandleOrder(userContext("supplier"), &pb.HandleOrderRequest{OrderId: order.ID}); err == nil {
+				t.Fatal("an order was handled without its payment")
+			}
+			found, err := store.GetOrderByID(ctx, order.ID)
+			if err != nil || !hasStatus(found.Status, repository.OrderStatusEnumWaiting) || found.PaymentStatus != repository.PaymentStatusEnumAuthorized {
+				t.Fatalf("after a failed capture the order is %+v, %v, want it waiting and authorized", found, err)
+			}
+		}
+
+		if _, err := srv.DeleteOrder(userContext("customer"), &pb.DeleteOrderRequest{OrderId: order.ID}); err != nil {
+			t.Fatal(err)
+		}
+		if status := fakePaymentStatus(srv, paymentID); status != repository.PaymentStatusEnumUnpaid {
+			t.Errorf("the authorization of the cancelled order is %s, want it voided", status)
+		}
+	})
+}
+
+func TestFailedPayment(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+
+		paymentID, err := srv.payment.Authorize(ctx, 1, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		order := testOrder(t, store, func(arg *repository.CreateOrderParams) {
+			arg.ProductID = 1
+			arg.PaymentStatus = repository.PaymentStatusEnumFailed
+			arg.PaymentID = paymentID
+		})
+		if _, err := srv.HandleOrder(userContext("supplier"), &pb.HandleOrderRequest{OrderId: order.ID}); err == nil {
+			t.Fatal("an order whose payment failed was handled")
+		}
+
+		if _, err := srv.DeleteOrder(userContext("customer"), &pb.DeleteOrderRequest{OrderId: order.ID}); err != nil {
+			t.Fatal(err)
+		}
+		if status := fakePaymentStatus(srv, paymentID); status != repository.PaymentStatusEnumUnpaid {
+			t.Errorf("the authorization of the failed payment is %s, want it voided", status)
+		}
+		found, err := store.GetOrderByID(ctx, order.ID)
+		if err != nil || found.PaymentStatus != repository.PaymentStatusEnumUnpaid {
+			t.Errorf("the cancelled order is %+v, %v", found, err)
+		}
+
+		// a failed payment whose authorization is gone doesn't stop the cancel
+		gone := testOrder(t, store, func(arg *repository.CreateOrderParams) {
+			arg.PaymentStatus = repository.PaymentStatusEnumFailed
+			arg.PaymentID = "unknown"
+		})
+		if _, err := srv.DeleteOrder(userContext("customer"), &pb.DeleteOrderRequest{OrderId: gone.ID}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
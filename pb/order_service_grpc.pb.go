@@ -25,6 +25,7 @@ const _ = grpc.SupportPackageIsVersion7
 type OrderServiceClient interface {
 	Ping(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*Pong, error)
 	CreateOrder(ctx context.Context, in *CreateOrderRequest, opts ...grpc.CallOption) (*CreateOrderResponse, error)
+	PreviewOrder(ctx context.Context, in *CreateOrderRequest, opts ...grpc.CallOption) (*PreviewOrderResponse, error)
 	DeleteOrder(ctx context.Context, in *DeleteOrderRequest, opts ...grpc.CallOption) (*DeleteOrderResponse, error)
 	UpdateOrder(ctx context.Context, in *UpdateOrderStatusRequest, opts ...grpc.CallOption) (*UpdateOrderStatusResponse, error)
 	HandleOrder(ctx context.Context, in *HandleOrderRequest, opts ...grpc.CallOption) (*HandleOrderResponse, error)
@@ -38,6 +39,38 @@ type OrderServiceClient interface {
 	GetCancelOrderByCustomer(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*GetHandledOrderByCustomerResponse, error)
 	GetCancelOrderBySupplier(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*GetHandledOrderBySupplierResponse, error)
 	GetAddressOrder(ctx context.Context, in *GetAddressOrderRequest, opts ...grpc.CallOption) (*GetAddressOrderResponse, error)
+	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	CreateShipment(ctx context.Context, in *CreateShipmentRequest, opts ...grpc.CallOption) (*Shipment, error)
+	UpdateShipmentTracking(ctx context.Context, in *UpdateShipmentTrackingRequest, opts ...grpc.CallOption) (*Shipment, error)
+	MarkShipmentDelivered(ctx context.Context, in *MarkShipmentDeliveredRequest, opts ...grpc.CallOption) (*Shipment, error)
+	GetShipmentByOrder(ctx context.Context, in *GetShipmentByOrderRequest, opts ...grpc.CallOption) (*GetShipmentByOrderResponse, error)
+	RequestReturn(ctx context.Context, in *RequestReturnRequest, opts ...grpc.CallOption) (*OrderReturn, error)
+	ApproveReturn(ctx context.Context, in *UpdateReturnRequest, opts ...grpc.CallOption) (*OrderReturn, error)
+	RejectReturn(ctx context.Context, in *UpdateReturnRequest, opts ...grpc.CallOption) (*OrderReturn, error)
+	ReceiveReturn(ctx context.Context, in *UpdateReturnRequest, opts ...grpc.CallOption) (*OrderReturn, error)
+	RefundReturn(ctx context.Context, in *UpdateReturnRequest, opts ...grpc.CallOption) (*OrderReturn, error)
+	GetReturn(ctx context.Context, in *GetReturnRequest, opts ...grpc.CallOption) (*OrderReturn, error)
+	ListReturn(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListReturnResponse, error)
+	CreateCustomerAddress(ctx context.Context, in *CreateCustomerAddressRequest, opts ...grpc.CallOption) (*CustomerAddress, error)
+	UpdateCustomerAddress(ctx context.Context, in *UpdateCustomerAddressRequest, opts ...grpc.CallOption) (*CustomerAddress, error)
+	DeleteCustomerAddress(ctx context.Context, in *DeleteCustomerAddressRequest, opts ...grpc.CallOption) (*DeleteCustomerAddressResponse, error)
+	ListCustomerAddress(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListCustomerAddressResponse, error)
+	CreateCoupon(ctx context.Context, in *CreateCouponRequest, opts ...grpc.CallOption) (*Coupon, error)
+	SetCouponActive(ctx context.Context, in *SetCouponActiveRequest, opts ...grpc.CallOption) (*Coupon, error)
+	ListCoupon(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListCouponResponse, error)
+	SetSupplierOrderSetting(ctx context.Context, in *SupplierOrderSetting, opts ...grpc.CallOption) (*SupplierOrderSetting, error)
+	SearchOrders(ctx context.Context, in *SearchOrdersRequest, opts ...grpc.CallOption) (*SearchOrdersResponse, error)
+	AdminCancelOrder(ctx context.Context, in *AdminOrderActionRequest, opts ...grpc.CallOption) (*Order, error)
+	AdminHoldOrder(ctx context.Context, in *AdminOrderActionRequest, opts ...grpc.CallOption) (*Order, error)
+	AdminReleaseOrder(ctx context.Context, in *AdminOrderActionRequest, opts ...grpc.CallOption) (*Order, error)
+	GetOrderHistory(ctx context.Context, in *GetOrderHistoryRequest, opts ...grpc.CallOption) (*GetOrderHistoryResponse, error)
+	GetSupplierSalesReport(ctx context.Context, in *GetSupplierSalesReportRequest, opts ...grpc.CallOption) (*GetSupplierSalesReportResponse, error)
+	GetGmvReport(ctx context.Context, in *GetGmvReportRequest, opts ...grpc.CallOption) (*GetGmvReportResponse, error)
+	GetSlowestSuppliers(ctx context.Context, in *GetSlowestSuppliersRequest, opts ...grpc.CallOption) (*GetSlowestSuppliersResponse, error)
+	GetCheckoutFailures(ctx context.Context, in *GetCheckoutFailuresRequest, opts ...grpc.CallOption) (*GetCheckoutFailuresResponse, error)
+	ExportOrders(ctx context.Context, in *ExportOrdersRequest, opts ...grpc.CallOption) (OrderService_ExportOrdersClient, error)
+	SetSupplierInvoiceSetting(ctx context.Context, in *SupplierInvoiceSetting, opts ...grpc.CallOption) (*SupplierInvoiceSetting, error)
+	GetInvoice(ctx context.Context, in *GetInvoiceRequest, opts ...grpc.CallOption) (*GetInvoiceResponse, error)
 }
 
 type orderServiceClient struct {
@@ -66,6 +99,15 @@ func (c *orderServiceClient) CreateOrder(ctx context.Context, in *CreateOrderReq
 	return out, nil
 }
 
+func (c *orderServiceClient) PreviewOrder(ctx context.Context, in *CreateOrderRequest, opts ...grpc.CallOption) (*PreviewOrderResponse, error) {
+	out := new(PreviewOrderResponse)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/PreviewOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *orderServiceClient) DeleteOrder(ctx context.Context, in *DeleteOrderRequest, opts ...grpc.CallOption) (*DeleteOrderResponse, error) {
 	out := new(DeleteOrderResponse)
 	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/DeleteOrder", in, out, opts...)
@@ -183,12 +225,324 @@ func (c *orderServiceClient) GetAddressOrder(ctx context.Context, in *GetAddress
 	return out, nil
 }
 
+func (c *orderServiceClient) GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/GetOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) CreateShipment(ctx context.Context, in *CreateShipmentRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/CreateShipment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) UpdateShipmentTracking(ctx context.Context, in *UpdateShipmentTrackingRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/UpdateShipmentTracking", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) MarkShipmentDelivered(ctx context.Context, in *MarkShipmentDeliveredRequest, opts ...grpc.CallOption) (*Shipment, error) {
+	out := new(Shipment)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/MarkShipmentDelivered", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetShipmentByOrder(ctx context.Context, in *GetShipmentByOrderRequest, opts ...grpc.CallOption) (*GetShipmentByOrderResponse, error) {
+	out := new(GetShipmentByOrderResponse)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/GetShipmentByOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) RequestReturn(ctx context.Context, in *RequestReturnRequest, opts ...grpc.CallOption) (*OrderReturn, error) {
+	out := new(OrderReturn)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/RequestReturn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ApproveReturn(ctx context.Context, in *UpdateReturnRequest, opts ...grpc.CallOption) (*OrderReturn, error) {
+	out := new(OrderReturn)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/ApproveReturn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) RejectReturn(ctx context.Context, in *UpdateReturnRequest, opts ...grpc.CallOption) (*OrderReturn, error) {
+	out := new(OrderReturn)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/RejectReturn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ReceiveReturn(ctx context.Context, in *UpdateReturnRequest, opts ...grpc.CallOption) (*OrderReturn, error) {
+	out := new(OrderReturn)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/ReceiveReturn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) RefundReturn(ctx context.Context, in *UpdateReturnRequest, opts ...grpc.CallOption) (*OrderReturn, error) {
+	out := new(OrderReturn)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/RefundReturn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetReturn(ctx context.Context, in *GetReturnRequest, opts ...grpc.CallOption) (*OrderReturn, error) {
+	out := new(OrderReturn)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/GetReturn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ListReturn(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListReturnResponse, error) {
+	out := new(ListReturnResponse)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/ListReturn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) CreateCustomerAddress(ctx context.Context, in *CreateCustomerAddressRequest, opts ...grpc.CallOption) (*CustomerAddress, error) {
+	out := new(CustomerAddress)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/CreateCustomerAddress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) UpdateCustomerAddress(ctx context.Context, in *UpdateCustomerAddressRequest, opts ...grpc.CallOption) (*CustomerAddress, error) {
+	out := new(CustomerAddress)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/UpdateCustomerAddress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) DeleteCustomerAddress(ctx context.Context, in *DeleteCustomerAddressRequest, opts ...grpc.CallOption) (*DeleteCustomerAddressResponse, error) {
+	out := new(DeleteCustomerAddressResponse)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/DeleteCustomerAddress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ListCustomerAddress(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListCustomerAddressResponse, error) {
+	out := new(ListCustomerAddressResponse)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/ListCustomerAddress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) CreateCoupon(ctx context.Context, in *CreateCouponRequest, opts ...grpc.CallOption) (*Coupon, error) {
+	out := new(Coupon)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/CreateCoupon", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) SetCouponActive(ctx context.Context, in *SetCouponActiveRequest, opts ...grpc.CallOption) (*Coupon, error) {
+	out := new(Coupon)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/SetCouponActive", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ListCoupon(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListCouponResponse, error) {
+	out := new(ListCouponResponse)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/ListCoupon", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) SetSupplierOrderSetting(ctx context.Context, in *SupplierOrderSetting, opts ...grpc.CallOption) (*SupplierOrderSetting, error) {
+	out := new(SupplierOrderSetting)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/SetSupplierOrderSetting", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) SearchOrders(ctx context.Context, in *SearchOrdersRequest, opts ...grpc.CallOption) (*SearchOrdersResponse, error) {
+	out := new(SearchOrdersResponse)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/SearchOrders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) AdminCancelOrder(ctx context.Context, in *AdminOrderActionRequest, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/AdminCancelOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) AdminHoldOrder(ctx context.Context, in *AdminOrderActionRequest, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/AdminHoldOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) AdminReleaseOrder(ctx context.Context, in *AdminOrderActionRequest, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/AdminReleaseOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetOrderHistory(ctx context.Context, in *GetOrderHistoryRequest, opts ...grpc.CallOption) (*GetOrderHistoryResponse, error) {
+	out := new(GetOrderHistoryResponse)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/GetOrderHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetSupplierSalesReport(ctx context.Context, in *GetSupplierSalesReportRequest, opts ...grpc.CallOption) (*GetSupplierSalesReportResponse, error) {
+	out := new(GetSupplierSalesReportResponse)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/GetSupplierSalesReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetGmvReport(ctx context.Context, in *GetGmvReportRequest, opts ...grpc.CallOption) (*GetGmvReportResponse, error) {
+	out := new(GetGmvReportResponse)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/GetGmvReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetSlowestSuppliers(ctx context.Context, in *GetSlowestSuppliersRequest, opts ...grpc.CallOption) (*GetSlowestSuppliersResponse, error) {
+	out := new(GetSlowestSuppliersResponse)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/GetSlowestSuppliers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetCheckoutFailures(ctx context.Context, in *GetCheckoutFailuresRequest, opts ...grpc.CallOption) (*GetCheckoutFailuresResponse, error) {
+	out := new(GetCheckoutFailuresResponse)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/GetCheckoutFailures", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ExportOrders(ctx context.Context, in *ExportOrdersRequest, opts ...grpc.CallOption) (OrderService_ExportOrdersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OrderService_ServiceDesc.Streams[0], "/ecommerce.OrderService/ExportOrders", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &orderServiceExportOrdersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type OrderService_ExportOrdersClient interface {
+	Recv() (*ExportOrdersChunk, error)
+	grpc.ClientStream
+}
+
+type orderServiceExportOrdersClient struct {
+	grpc.ClientStream
+}
+
+func (x *orderServiceExportOrdersClient) Recv() (*ExportOrdersChunk, error) {
+	m := new(ExportOrdersChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *orderServiceClient) SetSupplierInvoiceSetting(ctx context.Context, in *SupplierInvoiceSetting, opts ...grpc.CallOption) (*SupplierInvoiceSetting, error) {
+	out := new(SupplierInvoiceSetting)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/SetSupplierInvoiceSetting", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetInvoice(ctx context.Context, in *GetInvoiceRequest, opts ...grpc.CallOption) (*GetInvoiceResponse, error) {
+	out := new(GetInvoiceResponse)
+	err := c.cc.Invoke(ctx, "/ecommerce.OrderService/GetInvoice", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OrderServiceServer is the server API for OrderService service.
 // All implementations must embed UnimplementedOrderServiceServer
 // for forward compatibility
 type OrderServiceServer interface {
 	Ping(context.Context, *empty.Empty) (*Pong, error)
 	CreateOrder(context.Context, *CreateOrderRequest) (*CreateOrderResponse, error)
+	PreviewOrder(context.Context, *CreateOrderRequest) (*PreviewOrderResponse, error)
 	DeleteOrder(context.Context, *DeleteOrderRequest) (*DeleteOrderResponse, error)
 	UpdateOrder(context.Context, *UpdateOrderStatusRequest) (*UpdateOrderStatusResponse, error)
 	HandleOrder(context.Context, *HandleOrderRequest) (*HandleOrderResponse, error)
@@ -202,6 +556,38 @@ type OrderServiceServer interface {
 	GetCancelOrderByCustomer(context.Context, *empty.Empty) (*GetHandledOrderByCustomerResponse, error)
 	GetCancelOrderBySupplier(context.Context, *empty.Empty) (*GetHandledOrderBySupplierResponse, error)
 	GetAddressOrder(context.Context, *GetAddressOrderRequest) (*GetAddressOrderResponse, error)
+	GetOrder(context.Context, *GetOrderRequest) (*Order, error)
+	CreateShipment(context.Context, *CreateShipmentRequest) (*Shipment, error)
+	UpdateShipmentTracking(context.Context, *UpdateShipmentTrackingRequest) (*Shipment, error)
+	MarkShipmentDelivered(context.Context, *MarkShipmentDeliveredRequest) (*Shipment, error)
+	GetShipmentByOrder(context.Context, *GetShipmentByOrderRequest) (*GetShipmentByOrderResponse, error)
+	RequestReturn(context.Context, *RequestReturnRequest) (*OrderReturn, error)
+	ApproveReturn(context.Context, *UpdateReturnRequest) (*OrderReturn, error)
+	RejectReturn(context.Context, *UpdateReturnRequest) (*OrderReturn, error)
+	ReceiveReturn(context.Context, *UpdateReturnRequest) (*OrderReturn, error)
+	RefundReturn(context.Context, *UpdateReturnRequest) (*OrderReturn, error)
+	GetReturn(context.Context, *GetReturnRequest) (*OrderReturn, error)
+	ListReturn(context.Context, *empty.Empty) (*ListReturnResponse, error)
+	CreateCustomerAddress(context.Context, *CreateCustomerAddressRequest) (*CustomerAddress, error)
+	UpdateCustomerAddress(context.Context, *UpdateCustomerAddressRequest) (*CustomerAddress, error)
+	DeleteCustomerAddress(context.Context, *DeleteCustomerAddressRequest) (*DeleteCustomerAddressResponse, error)
+	ListCustomerAddress(context.Context, *empty.Empty) (*ListCustomerAddressResponse, error)
+	CreateCoupon(context.Context, *CreateCouponRequest) (*Coupon, error)
+	SetCouponActive(context.Context, *SetCouponActiveRequest) (*Coupon, error)
+	ListCoupon(context.Context, *empty.Empty) (*ListCouponResponse, error)
+	SetSupplierOrderSetting(context.Context, *SupplierOrderSetting) (*SupplierOrderSetting, error)
+	SearchOrders(context.Context, *SearchOrdersRequest) (*SearchOrdersResponse, error)
+	AdminCancelOrder(context.Context, *AdminOrderActionRequest) (*Order, error)
+	AdminHoldOrder(context.Context, *AdminOrderActionRequest) (*Order, error)
+	AdminReleaseOrder(context.Context, *AdminOrderActionRequest) (*Order, error)
+	GetOrderHistory(context.Context, *GetOrderHistoryRequest) (*GetOrderHistoryResponse, error)
+	GetSupplierSalesReport(context.Context, *GetSupplierSalesReportRequest) (*GetSupplierSalesReportResponse, error)
+	GetGmvReport(context.Context, *GetGmvReportRequest) (*GetGmvReportResponse, error)
+	GetSlowestSuppliers(context.Context, *GetSlowestSuppliersRequest) (*GetSlowestSuppliersResponse, error)
+	GetCheckoutFailures(context.Context, *GetCheckoutFailuresRequest) (*GetCheckoutFailuresResponse, error)
+	ExportOrders(*ExportOrdersRequest, OrderService_ExportOrdersServer) error
+	SetSupplierInvoiceSetting(context.Context, *SupplierInvoiceSetting) (*SupplierInvoiceSetting, error)
+	GetInvoice(context.Context, *GetInvoiceRequest) (*GetInvoiceResponse, error)
 	mustEmbedUnimplementedOrderServiceServer()
 }
 
@@ -215,6 +601,9 @@ func (UnimplementedOrderServiceServer) Ping(context.Context, *empty.Empty) (*Pon
 func (UnimplementedOrderServiceServer) CreateOrder(context.Context, *CreateOrderRequest) (*CreateOrderResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateOrder not implemented")
 }
+func (UnimplementedOrderServiceServer) PreviewOrder(context.Context, *CreateOrderRequest) (*PreviewOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreviewOrder not implemented")
+}
 func (UnimplementedOrderServiceServer) DeleteOrder(context.Context, *DeleteOrderRequest) (*DeleteOrderResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteOrder not implemented")
 }
@@ -254,6 +643,102 @@ func (UnimplementedOrderServiceServer) GetCancelOrderBySupplier(context.Context,
 func (UnimplementedOrderServiceServer) GetAddressOrder(context.Context, *GetAddressOrderRequest) (*GetAddressOrderResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAddressOrder not implemented")
 }
+func (UnimplementedOrderServiceServer) GetOrder(context.Context, *GetOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) CreateShipment(context.Context, *CreateShipmentRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateShipment not implemented")
+}
+func (UnimplementedOrderServiceServer) UpdateShipmentTracking(context.Context, *UpdateShipmentTrackingRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateShipmentTracking not implemented")
+}
+func (UnimplementedOrderServiceServer) MarkShipmentDelivered(context.Context, *MarkShipmentDeliveredRequest) (*Shipment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkShipmentDelivered not implemented")
+}
+func (UnimplementedOrderServiceServer) GetShipmentByOrder(context.Context, *GetShipmentByOrderRequest) (*GetShipmentByOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShipmentByOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) RequestReturn(context.Context, *RequestReturnRequest) (*OrderReturn, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestReturn not implemented")
+}
+func (UnimplementedOrderServiceServer) ApproveReturn(context.Context, *UpdateReturnRequest) (*OrderReturn, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveReturn not implemented")
+}
+func (UnimplementedOrderServiceServer) RejectReturn(context.Context, *UpdateReturnRequest) (*OrderReturn, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RejectReturn not implemented")
+}
+func (UnimplementedOrderServiceServer) ReceiveReturn(context.Context, *UpdateReturnRequest) (*OrderReturn, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReceiveReturn not implemented")
+}
+func (UnimplementedOrderServiceServer) RefundReturn(context.Context, *UpdateReturnRequest) (*OrderReturn, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundReturn not implemented")
+}
+func (UnimplementedOrderServiceServer) GetReturn(context.Context, *GetReturnRequest) (*OrderReturn, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReturn not implemented")
+}
+func (UnimplementedOrderServiceServer) ListReturn(context.Context, *empty.Empty) (*ListReturnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReturn not implemented")
+}
+func (UnimplementedOrderServiceServer) CreateCustomerAddress(context.Context, *CreateCustomerAddressRequest) (*CustomerAddress, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCustomerAddress not implemented")
+}
+func (UnimplementedOrderServiceServer) UpdateCustomerAddress(context.Context, *UpdateCustomerAddressRequest) (*CustomerAddress, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCustomerAddress not implemented")
+}
+func (UnimplementedOrderServiceServer) DeleteCustomerAddress(context.Context, *DeleteCustomerAddressRequest) (*DeleteCustomerAddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCustomerAddress not implemented")
+}
+func (UnimplementedOrderServiceServer) ListCustomerAddress(context.Context, *empty.Empty) (*ListCustomerAddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCustomerAddress not implemented")
+}
+func (UnimplementedOrderServiceServer) CreateCoupon(context.Context, *CreateCouponRequest) (*Coupon, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCoupon not implemented")
+}
+func (UnimplementedOrderServiceServer) SetCouponActive(context.Context, *SetCouponActiveRequest) (*Coupon, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCouponActive not implemented")
+}
+func (UnimplementedOrderServiceServer) ListCoupon(context.Context, *empty.Empty) (*ListCouponResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCoupon not implemented")
+}
+func (UnimplementedOrderServiceServer) SetSupplierOrderSetting(context.Context, *SupplierOrderSetting) (*SupplierOrderSetting, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSupplierOrderSetting not implemented")
+}
+func (UnimplementedOrderServiceServer) SearchOrders(context.Context, *SearchOrdersRequest) (*SearchOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchOrders not implemented")
+}
+func (UnimplementedOrderServiceServer) AdminCancelOrder(context.Context, *AdminOrderActionRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminCancelOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) AdminHoldOrder(context.Context, *AdminOrderActionRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminHoldOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) AdminReleaseOrder(context.Context, *AdminOrderActionRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminReleaseOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) GetOrderHistory(context.Context, *GetOrderHistoryRequest) (*GetOrderHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderHistory not implemented")
+}
+func (UnimplementedOrderServiceServer) GetSupplierSalesReport(context.Context, *GetSupplierSalesReportRequest) (*GetSupplierSalesReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSupplierSalesReport not implemented")
+}
+func (UnimplementedOrderServiceServer) GetGmvReport(context.Context, *GetGmvReportRequest) (*GetGmvReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGmvReport not implemented")
+}
+func (UnimplementedOrderServiceServer) GetSlowestSuppliers(context.Context, *GetSlowestSuppliersRequest) (*GetSlowestSuppliersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSlowestSuppliers not implemented")
+}
+func (UnimplementedOrderServiceServer) GetCheckoutFailures(context.Context, *GetCheckoutFailuresRequest) (*GetCheckoutFailuresResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCheckoutFailures not implemented")
+}
+func (UnimplementedOrderServiceServer) ExportOrders(*ExportOrdersRequest, OrderService_ExportOrdersServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportOrders not implemented")
+}
+func (UnimplementedOrderServiceServer) SetSupplierInvoiceSetting(context.Context, *SupplierInvoiceSetting) (*SupplierInvoiceSetting, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSupplierInvoiceSetting not implemented")
+}
+func (UnimplementedOrderServiceServer) GetInvoice(context.Context, *GetInvoiceRequest) (*GetInvoiceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInvoice not implemented")
+}
 func (UnimplementedOrderServiceServer) mustEmbedUnimplementedOrderServiceServer() {}
 
 // UnsafeOrderServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -303,6 +788,24 @@ func _OrderService_CreateOrder_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrderService_PreviewOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).PreviewOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/PreviewOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).PreviewOrder(ctx, req.(*CreateOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _OrderService_DeleteOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteOrderRequest)
 	if err := dec(in); err != nil {
@@ -537,6 +1040,585 @@ func _OrderService_GetAddressOrder_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrderService_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/GetOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetOrder(ctx, req.(*GetOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_CreateShipment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateShipmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CreateShipment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/CreateShipment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CreateShipment(ctx, req.(*CreateShipmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_UpdateShipmentTracking_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateShipmentTrackingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).UpdateShipmentTracking(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/UpdateShipmentTracking",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).UpdateShipmentTracking(ctx, req.(*UpdateShipmentTrackingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_MarkShipmentDelivered_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkShipmentDeliveredRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).MarkShipmentDelivered(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/MarkShipmentDelivered",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).MarkShipmentDelivered(ctx, req.(*MarkShipmentDeliveredRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetShipmentByOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetShipmentByOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetShipmentByOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/GetShipmentByOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetShipmentByOrder(ctx, req.(*GetShipmentByOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_RequestReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).RequestReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/RequestReturn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).RequestReturn(ctx, req.(*RequestReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ApproveReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ApproveReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/ApproveReturn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ApproveReturn(ctx, req.(*UpdateReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_RejectReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).RejectReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/RejectReturn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).RejectReturn(ctx, req.(*UpdateReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ReceiveReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ReceiveReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/ReceiveReturn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ReceiveReturn(ctx, req.(*UpdateReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_RefundReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).RefundReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/RefundReturn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).RefundReturn(ctx, req.(*UpdateReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/GetReturn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetReturn(ctx, req.(*GetReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ListReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/ListReturn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListReturn(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_CreateCustomerAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCustomerAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CreateCustomerAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/CreateCustomerAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CreateCustomerAddress(ctx, req.(*CreateCustomerAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_UpdateCustomerAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCustomerAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).UpdateCustomerAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/UpdateCustomerAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).UpdateCustomerAddress(ctx, req.(*UpdateCustomerAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_DeleteCustomerAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCustomerAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).DeleteCustomerAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/DeleteCustomerAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).DeleteCustomerAddress(ctx, req.(*DeleteCustomerAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ListCustomerAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListCustomerAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/ListCustomerAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListCustomerAddress(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_CreateCoupon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCouponRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CreateCoupon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/CreateCoupon",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CreateCoupon(ctx, req.(*CreateCouponRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_SetCouponActive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCouponActiveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).SetCouponActive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/SetCouponActive",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).SetCouponActive(ctx, req.(*SetCouponActiveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ListCoupon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListCoupon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/ListCoupon",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListCoupon(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_SetSupplierOrderSetting_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SupplierOrderSetting)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).SetSupplierOrderSetting(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/SetSupplierOrderSetting",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).SetSupplierOrderSetting(ctx, req.(*SupplierOrderSetting))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_SearchOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).SearchOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/SearchOrders",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).SearchOrders(ctx, req.(*SearchOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_AdminCancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminOrderActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).AdminCancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/AdminCancelOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).AdminCancelOrder(ctx, req.(*AdminOrderActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_AdminHoldOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminOrderActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).AdminHoldOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/AdminHoldOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).AdminHoldOrder(ctx, req.(*AdminOrderActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_AdminReleaseOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminOrderActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).AdminReleaseOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/AdminReleaseOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).AdminReleaseOrder(ctx, req.(*AdminOrderActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetOrderHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetOrderHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/GetOrderHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetOrderHistory(ctx, req.(*GetOrderHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetSupplierSalesReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSupplierSalesReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetSupplierSalesReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/GetSupplierSalesReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetSupplierSalesReport(ctx, req.(*GetSupplierSalesReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetGmvReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGmvReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetGmvReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/GetGmvReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetGmvReport(ctx, req.(*GetGmvReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetSlowestSuppliers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSlowestSuppliersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetSlowestSuppliers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/GetSlowestSuppliers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetSlowestSuppliers(ctx, req.(*GetSlowestSuppliersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetCheckoutFailures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCheckoutFailuresRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetCheckoutFailures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/GetCheckoutFailures",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetCheckoutFailures(ctx, req.(*GetCheckoutFailuresRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ExportOrders_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportOrdersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OrderServiceServer).ExportOrders(m, &orderServiceExportOrdersServer{stream})
+}
+
+type OrderService_ExportOrdersServer interface {
+	Send(*ExportOrdersChunk) error
+	grpc.ServerStream
+}
+
+type orderServiceExportOrdersServer struct {
+	grpc.ServerStream
+}
+
+func (x *orderServiceExportOrdersServer) Send(m *ExportOrdersChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _OrderService_SetSupplierInvoiceSetting_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SupplierInvoiceSetting)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).SetSupplierInvoiceSetting(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/SetSupplierInvoiceSetting",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).SetSupplierInvoiceSetting(ctx, req.(*SupplierInvoiceSetting))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInvoiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ecommerce.OrderService/GetInvoice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetInvoice(ctx, req.(*GetInvoiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // OrderService_ServiceDesc is the grpc.ServiceDesc for OrderService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -552,6 +1634,10 @@ var OrderService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateOrder",
 			Handler:    _OrderService_CreateOrder_Handler,
 		},
+		{
+			MethodName: "PreviewOrder",
+			Handler:    _OrderService_PreviewOrder_Handler,
+		},
 		{
 			MethodName: "DeleteOrder",
 			Handler:    _OrderService_DeleteOrder_Handler,
@@ -604,7 +1690,137 @@ var OrderService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAddressOrder",
 			Handler:    _OrderService_GetAddressOrder_Handler,
 		},
+		{
+			MethodName: "GetOrder",
+			Handler:    _OrderService_GetOrder_Handler,
+		},
+		{
+			MethodName: "CreateShipment",
+			Handler:    _OrderService_CreateShipment_Handler,
+		},
+		{
+			MethodName: "UpdateShipmentTracking",
+			Handler:    _OrderService_UpdateShipmentTracking_Handler,
+		},
+		{
+			MethodName: "MarkShipmentDelivered",
+			Handler:    _OrderService_MarkShipmentDelivered_Handler,
+		},
+		{
+			MethodName: "GetShipmentByOrder",
+			Handler:    _OrderService_GetShipmentByOrder_Handler,
+		},
+		{
+			MethodName: "RequestReturn",
+			Handler:    _OrderService_RequestReturn_Handler,
+		},
+		{
+			MethodName: "ApproveReturn",
+			Handler:    _OrderService_ApproveReturn_Handler,
+		},
+		{
+			MethodName: "RejectReturn",
+			Handler:    _OrderService_RejectReturn_Handler,
+		},
+		{
+			MethodName: "ReceiveReturn",
+			Handler:    _OrderService_ReceiveReturn_Handler,
+		},
+		{
+			MethodName: "RefundReturn",
+			Handler:    _OrderService_RefundReturn_Handler,
+		},
+		{
+			MethodName: "GetReturn",
+			Handler:    _OrderService_GetReturn_Handler,
+		},
+		{
+			MethodName: "ListReturn",
+			Handler:    _OrderService_ListReturn_Handler,
+		},
+		{
+			MethodName: "CreateCustomerAddress",
+			Handler:    _OrderService_CreateCustomerAddress_Handler,
+		},
+		{
+			MethodName: "UpdateCustomerAddress",
+			Handler:    _OrderService_UpdateCustomerAddress_Handler,
+		},
+		{
+			MethodName: "DeleteCustomerAddress",
+			Handler:    _OrderService_DeleteCustomerAddress_Handler,
+		},
+		{
+			MethodName: "ListCustomerAddress",
+			Handler:    _OrderService_ListCustomerAddress_Handler,
+		},
+		{
+			MethodName: "CreateCoupon",
+			Handler:    _OrderService_CreateCoupon_Handler,
+		},
+		{
+			MethodName: "SetCouponActive",
+			Handler:    _OrderService_SetCouponActive_Handler,
+		},
+		{
+			MethodName: "ListCoupon",
+			Handler:    _OrderService_ListCoupon_Handler,
+		},
+		{
+			MethodName: "SetSupplierOrderSetting",
+			Handler:    _OrderService_SetSupplierOrderSetting_Handler,
+		},
+		{
+			MethodName: "SearchOrders",
+			Handler:    _OrderService_SearchOrders_Handler,
+		},
+		{
+			MethodName: "AdminCancelOrder",
+			Handler:    _OrderService_AdminCancelOrder_Handler,
+		},
+		{
+			MethodName: "AdminHoldOrder",
+			Handler:    _OrderService_AdminHoldOrder_Handler,
+		},
+		{
+			MethodName: "AdminReleaseOrder",
+			Handler:    _OrderService_AdminReleaseOrder_Handler,
+		},
+		{
+			MethodName: "GetOrderHistory",
+			Handler:    _OrderService_GetOrderHistory_Handler,
+		},
+		{
+			MethodName: "GetSupplierSalesReport",
+			Handler:    _OrderService_GetSupplierSalesReport_Handler,
+		},
+		{
+			MethodName: "GetGmvReport",
+			Handler:    _OrderService_GetGmvReport_Handler,
+		},
+		{
+			MethodName: "GetSlowestSuppliers",
+			Handler:    _OrderService_GetSlowestSuppliers_Handler,
+		},
+		{
+			MethodName: "GetCheckoutFailures",
+			Handler:    _OrderService_GetCheckoutFailures_Handler,
+		},
+		{
+			MethodName: "SetSupplierInvoiceSetting",
+			Handler:    _OrderService_SetSupplierInvoiceSetting_Handler,
+		},
+		{
+			MethodName: "GetInvoice",
+			Handler:    _OrderService_GetInvoice_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportOrders",
+			Handler:       _OrderService_ExportOrders_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "order_service.proto",
 }
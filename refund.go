@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+const (
+	// how long a claimed refund is kept before it is tried again, the wait
+	// doubles with every failed attempt
+	refundRetryAfter = 5 * time.Minute
+	// a refund still failing after that many attempts is left to an admin
+	refundMaxAttempts = 10
+	refundBatchSize   = 50
+)
+
+// refundPayment sends a pending refund to the payment provider. It is only
+// called once the refund is committed, and the refund stays pending until the
+// provider confirms it, so a failure is tried again by refundPendingReturns.
+func (srv orderService) refundPayment(ctx context.Context, refund repository.Refund) error {
+	refund, err := srv.orderStore.ClaimRefund(ctx, repository.ClaimRefundParams{
+		ID:                refund.ID,
+		RetryAfterSeconds: int32(refundRetryAfter / time.Second),
+		MaxAttempts:       refundMaxAttempts,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// refunded already, or being refunded by someone else
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return srv.sendRefund(ctx, refund)
+}
+
+// refundPendingReturns retries one batch of the refunds the provider didn't
+// confirm and returns how many were done. The refunds that failed the
+// longest ago come first.
+func (srv orderService) refundPendingReturns(ctx context.Context) (int, error) {
+	listRefund, err := srv.orderStore.ClaimDueRefund(ctx, repository.ClaimDueRefundParams{
+		RetryAfterSeconds: int32(refundRetryAfter / time.Second),
+		MaxAttempts:       refundMaxAttempts,
+		BatchSize:         refundBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, refund := range listRefund {
+		if err := srv.sendRefund(ctx, refund); err != nil {
+			if refund.Attempts >= refundMaxAttempts {
+				log.Println("can't refund payment, given up after attempts: ", refund.ID, refund.Attempts, err)
+			} else {
+				log.Println("can't refund payment, it is tried again later: ", refund.ID, err)
+			}
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+// sendRefund calls the provider with the id of the refund as its key, so a
+// refund whose confirmation was lost isn't paid twice when it is retried.
+// The order is marked refunded once its goods are refunded in full.
+func (srv orderService) sendRefund(ctx context.Context, refund repository.Refund) error {
+	if srv.payment == nil {
+		return errors.New("no payment provider")
+	}
+	err := srv.payment.Refund(ctx, refund.PaymentID, refund.Amount, fmt.Sprintf("refund-%d", refund.ID))
+	if err != nil {
+		return err
+	}
+	if err := srv.orderStore.FinishRefund(ctx, refund.ID); err != nil {
+		return err
+	}
+
+	order, err := srv.orderStore.GetOrderByID(ctx, refund.OrderID)
+	if err != nil {
+		return err
+	}
+	refunded, err := srv.orderStore.GetRefundedAmountByOrder(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if refunded < goodsAmount(order) {
+		return nil
+	}
+	return srv.orderStore.UpdateOrderPayment(ctx, repository.UpdateOrderPaymentParams{
+		ID:            order.ID,
+		PaymentStatus: repository.PaymentStatusEnumRefunded,
+		PaymentID:     order.PaymentID,
+	})
+}
+
+func toPbRefundStatus(status repository.RefundStatusEnum) pb.RefundStatus {
+	return pb.RefundStatus(pb.RefundStatus_value["refund_"+string(status)])
+}
@@ -0,0 +1,255 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.0
+// source: admin.sql
+
+package repository
+
+import (
+	"context"
+)
+
+const createOrderAudit = `-- name: CreateOrderAudit :one
+INSERT INTO "order_audit" (
+    "order_id", "admin_id", "action", "reason", "old_status", "new_status"
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, order_id, admin_id, action, reason, old_status, new_status, created_at
+`
+
+type CreateOrderAuditParams struct {
+	OrderID   int64
+	AdminID   int64
+	Action    string
+	Reason    string
+	OldStatus NullOrderStatusEnum
+	NewStatus NullOrderStatusEnum
+}
+
+func (q *Queries) CreateOrderAudit(ctx context.Context, arg CreateOrderAuditParams) (OrderAudit, error) {
+	row := q.db.QueryRowContext(ctx, createOrderAudit,
+		arg.OrderID,
+		arg.AdminID,
+		arg.Action,
+		arg.Reason,
+		arg.OldStatus,
+		arg.NewStatus,
+	)
+	var i OrderAudit
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.AdminID,
+		&i.Action,
+		&i.Reason,
+		&i.OldStatus,
+		&i.NewStatus,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const forceCancelOrder = `-- name: ForceCancelOrder :one
+UPDATE "order"
+SET "status" = 'cancel', "cancel_reason" = $2, "held_at" = NULL, "hold_reason" = '', "restock_pending" = true
+WHERE "id" = $1 AND "status" IN ('waiting', 'handled')
+RETURNING id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee
+`
+
+type ForceCancelOrderParams struct {
+	ID           int64
+	CancelReason string
+}
+
+// an admin can cancel a held order, shipped goods come back through a return
+func (q *Queries) ForceCancelOrder(ctx context.Context, arg ForceCancelOrderParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, forceCancelOrder, arg.ID, arg.CancelReason)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.Status,
+		&i.AddressID,
+		&i.CreatedAt,
+		&i.Price,
+		&i.PaymentStatus,
+		&i.PaymentID,
+		&i.Discount,
+		&i.CouponRedemptionID,
+		&i.CancelReason,
+		&i.UpdatedAt,
+		&i.HandledAt,
+		&i.CancelledAt,
+		&i.OrderNumber,
+		&i.ProductName,
+		&i.HeldAt,
+		&i.HoldReason,
+		&i.RestockPending,
+		&i.RestockRetryAt,
+		&i.RestockAttempts,
+		&i.ShippingFee,
+	)
+	return i, err
+}
+
+const getOrderAuditByOrder = `-- name: GetOrderAuditByOrder :many
+SELECT id, order_id, admin_id, action, reason, old_status, new_status, created_at FROM "order_audit"
+WHERE "order_id" = $1
+ORDER BY "id"
+`
+
+func (q *Queries) GetOrderAuditByOrder(ctx context.Context, orderID int64) ([]OrderAudit, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderAuditByOrder, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrderAudit
+	for rows.Next() {
+		var i OrderAudit
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.AdminID,
+			&i.Action,
+			&i.Reason,
+			&i.OldStatus,
+			&i.NewStatus,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderStatusHistoryByOrder = `-- name: GetOrderStatusHistoryByOrder :many
+SELECT id, order_id, old_status, new_status, created_at FROM "order_status_history"
+WHERE "order_id" = $1
+ORDER BY "id"
+`
+
+func (q *Queries) GetOrderStatusHistoryByOrder(ctx context.Context, orderID int64) ([]OrderStatusHistory, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderStatusHistoryByOrder, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrderStatusHistory
+	for rows.Next() {
+		var i OrderStatusHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.OldStatus,
+			&i.NewStatus,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const holdOrder = `-- name: HoldOrder :one
+UPDATE "order"
+SET "held_at" = now(), "hold_reason" = $2
+WHERE "id" = $1 AND "held_at" IS NULL AND "status" IN ('waiting', 'handled')
+RETURNING id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee
+`
+
+type HoldOrderParams struct {
+	ID         int64
+	HoldReason string
+}
+
+// goods on their way can't be held, a held order gets no new shipment
+func (q *Queries) HoldOrder(ctx context.Context, arg HoldOrderParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, holdOrder, arg.ID, arg.HoldReason)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.Status,
+		&i.AddressID,
+		&i.CreatedAt,
+		&i.Price,
+		&i.PaymentStatus,
+		&i.PaymentID,
+		&i.Discount,
+		&i.CouponRedemptionID,
+		&i.CancelReason,
+		&i.UpdatedAt,
+		&i.HandledAt,
+		&i.CancelledAt,
+		&i.OrderNumber,
+		&i.ProductName,
+		&i.HeldAt,
+		&i.HoldReason,
+		&i.RestockPending,
+		&i.RestockRetryAt,
+		&i.RestockAttempts,
+		&i.ShippingFee,
+	)
+	return i, err
+}
+
+const releaseOrder = `-- name: ReleaseOrder :one
+UPDATE "order"
+SET "held_at" = NULL, "hold_reason" = ''
+WHERE "id" = $1 AND "held_at" IS NOT NULL
+RETURNING id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee
+`
+
+func (q *Queries) ReleaseOrder(ctx context.Context, id int64) (Order, error) {
+	row := q.db.QueryRowContext(ctx, releaseOrder, id)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.Status,
+		&i.AddressID,
+		&i.CreatedAt,
+		&i.Price,
+		&i.PaymentStatus,
+		&i.PaymentID,
+		&i.Discount,
+		&i.CouponRedemptionID,
+		&i.CancelReason,
+		&i.UpdatedAt,
+		&i.HandledAt,
+		&i.CancelledAt,
+		&i.OrderNumber,
+		&i.ProductName,
+		&i.HeldAt,
+		&i.HoldReason,
+		&i.RestockPending,
+		&i.RestockRetryAt,
+		&i.RestockAttempts,
+		&i.ShippingFee,
+	)
+	return i, err
+}
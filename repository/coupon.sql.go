@@ -0,0 +1,398 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.0
+// source: coupon.sql
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const countActiveOrderByCouponRedemption = `-- name: CountActiveOrderByCouponRedemption :one
+SELECT COUNT(*) FROM "order"
+WHERE "coupon_redemption_id" = $1 AND "status" <> 'cancel'
+`
+
+func (q *Queries) CountActiveOrderByCouponRedemption(ctx context.Context, couponRedemptionID sql.NullInt64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countActiveOrderByCouponRedemption, couponRedemptionID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countCouponRedemptionByCustomer = `-- name: CountCouponRedemptionByCustomer :one
+SELECT COUNT(*) FROM "coupon_redemption"
+WHERE "coupon_id" = $1 AND "customer_id" = $2 AND NOT "released"
+`
+
+type CountCouponRedemptionByCustomerParams struct {
+	CouponID   int64
+	CustomerID int64
+}
+
+func (q *Queries) CountCouponRedemptionByCustomer(ctx context.Context, arg CountCouponRedemptionByCustomerParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCouponRedemptionByCustomer, arg.CouponID, arg.CustomerID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createCoupon = `-- name: CreateCoupon :one
+INSERT INTO "coupon" (
+    "code", "type", "value", "max_discount", "min_order_value", "supplier_id", "product_id",
+    "usage_limit", "usage_limit_per_customer", "starts_at", "ends_at", "created_by"
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+) RETURNING id, code, type, value, max_discount, min_order_value, supplier_id, product_id, usage_limit, usage_limit_per_customer, used_count, starts_at, ends_at, active, created_by, created_at
+`
+
+type CreateCouponParams struct {
+	Code                  string
+	Type                  CouponTypeEnum
+	Value                 int64
+	MaxDiscount           int64
+	MinOrderValue         int64
+	SupplierID            int64
+	ProductID             int64
+	UsageLimit            int32
+	UsageLimitPerCustomer int32
+	StartsAt              time.Time
+	EndsAt                sql.NullTime
+	CreatedBy             int64
+}
+
+func (q *Queries) CreateCoupon(ctx context.Context, arg CreateCouponParams) (Coupon, error) {
+	row := q.db.QueryRowContext(ctx, createCoupon,
+		arg.Code,
+		arg.Type,
+		arg.Value,
+		arg.MaxDiscount,
+		arg.MinOrderValue,
+		arg.SupplierID,
+		arg.ProductID,
+		arg.UsageLimit,
+		arg.UsageLimitPerCustomer,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.CreatedBy,
+	)
+	var i Coupon
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Type,
+		&i.Value,
+		&i.MaxDiscount,
+		&i.MinOrderValue,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.UsageLimit,
+		&i.UsageLimitPerCustomer,
+		&i.UsedCount,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Active,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createCouponRedemption = `-- name: CreateCouponRedemption :one
+INSERT INTO "coupon_redemption" (
+    "coupon_id", "customer_id", "discount"
+) VALUES (
+    $1, $2, $3
+) RETURNING id, coupon_id, customer_id, discount, released, created_at
+`
+
+type CreateCouponRedemptionParams struct {
+	CouponID   int64
+	CustomerID int64
+	Discount   int64
+}
+
+func (q *Queries) CreateCouponRedemption(ctx context.Context, arg CreateCouponRedemptionParams) (CouponRedemption, error) {
+	row := q.db.QueryRowContext(ctx, createCouponRedemption, arg.CouponID, arg.CustomerID, arg.Discount)
+	var i CouponRedemption
+	err := row.Scan(
+		&i.ID,
+		&i.CouponID,
+		&i.CustomerID,
+		&i.Discount,
+		&i.Released,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAllCoupon = `-- name: GetAllCoupon :many
+SELECT id, code, type, value, max_discount, min_order_value, supplier_id, product_id, usage_limit, usage_limit_per_customer, used_count, starts_at, ends_at, active, created_by, created_at FROM "coupon"
+ORDER BY "id" DESC
+`
+
+func (q *Queries) GetAllCoupon(ctx context.Context) ([]Coupon, error) {
+	rows, err := q.db.QueryContext(ctx, getAllCoupon)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Coupon
+	for rows.Next() {
+		var i Coupon
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Type,
+			&i.Value,
+			&i.MaxDiscount,
+			&i.MinOrderValue,
+			&i.SupplierID,
+			&i.ProductID,
+			&i.UsageLimit,
+			&i.UsageLimitPerCustomer,
+			&i.UsedCount,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Active,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCouponByCode = `-- name: GetCouponByCode :one
+SELECT id, code, type, value, max_discount, min_order_value, supplier_id, product_id, usage_limit, usage_limit_per_customer, used_count, starts_at, ends_at, active, created_by, created_at FROM "coupon"
+WHERE "code" = $1 LIMIT 1
+`
+
+func (q *Queries) GetCouponByCode(ctx context.Context, code string) (Coupon, error) {
+	row := q.db.QueryRowContext(ctx, getCouponByCode, code)
+	var i Coupon
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Type,
+		&i.Value,
+		&i.MaxDiscount,
+		&i.MinOrderValue,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.UsageLimit,
+		&i.UsageLimitPerCustomer,
+		&i.UsedCount,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Active,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getCouponByID = `-- name: GetCouponByID :one
+SELECT id, code, type, value, max_discount, min_order_value, supplier_id, product_id, usage_limit, usage_limit_per_customer, used_count, starts_at, ends_at, active, created_by, created_at FROM "coupon"
+WHERE "id" = $1 LIMIT 1
+`
+
+func (q *Queries) GetCouponByID(ctx context.Context, id int64) (Coupon, error) {
+	row := q.db.QueryRowContext(ctx, getCouponByID, id)
+	var i Coupon
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Type,
+		&i.Value,
+		&i.MaxDiscount,
+		&i.MinOrderValue,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.UsageLimit,
+		&i.UsageLimitPerCustomer,
+		&i.UsedCount,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Active,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getCouponBySupplier = `-- name: GetCouponBySupplier :many
+SELECT id, code, type, value, max_discount, min_order_value, supplier_id, product_id, usage_limit, usage_limit_per_customer, used_count, starts_at, ends_at, active, created_by, created_at FROM "coupon"
+WHERE "supplier_id" = $1
+ORDER BY "id" DESC
+`
+
+func (q *Queries) GetCouponBySupplier(ctx context.Context, supplierID int64) ([]Coupon, error) {
+	rows, err := q.db.QueryContext(ctx, getCouponBySupplier, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Coupon
+	for rows.Next() {
+		var i Coupon
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Type,
+			&i.Value,
+			&i.MaxDiscount,
+			&i.MinOrderValue,
+			&i.SupplierID,
+			&i.ProductID,
+			&i.UsageLimit,
+			&i.UsageLimitPerCustomer,
+			&i.UsedCount,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Active,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCouponRedemptionForUpdate = `-- name: GetCouponRedemptionForUpdate :one
+SELECT id, coupon_id, customer_id, discount, released, created_at FROM "coupon_redemption"
+WHERE "id" = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetCouponRedemptionForUpdate(ctx context.Context, id int64) (CouponRedemption, error) {
+	row := q.db.QueryRowContext(ctx, getCouponRedemptionForUpdate, id)
+	var i CouponRedemption
+	err := row.Scan(
+		&i.ID,
+		&i.CouponID,
+		&i.CustomerID,
+		&i.Discount,
+		&i.Released,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const redeemCoupon = `-- name: RedeemCoupon :one
+UPDATE "coupon"
+SET "used_count" = "used_count" + 1
+WHERE "id" = $1 AND "active" AND ("usage_limit" = 0 OR "used_count" < "usage_limit")
+RETURNING id, code, type, value, max_discount, min_order_value, supplier_id, product_id, usage_limit, usage_limit_per_customer, used_count, starts_at, ends_at, active, created_by, created_at
+`
+
+func (q *Queries) RedeemCoupon(ctx context.Context, id int64) (Coupon, error) {
+	row := q.db.QueryRowContext(ctx, redeemCoupon, id)
+	var i Coupon
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Type,
+		&i.Value,
+		&i.MaxDiscount,
+		&i.MinOrderValue,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.UsageLimit,
+		&i.UsageLimitPerCustomer,
+		&i.UsedCount,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Active,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const releaseCouponRedemption = `-- name: ReleaseCouponRedemption :one
+UPDATE "coupon_redemption"
+SET "released" = true
+WHERE "id" = $1 AND NOT "released"
+RETURNING id, coupon_id, customer_id, discount, released, created_at
+`
+
+func (q *Queries) ReleaseCouponRedemption(ctx context.Context, id int64) (CouponRedemption, error) {
+	row := q.db.QueryRowContext(ctx, releaseCouponRedemption, id)
+	var i CouponRedemption
+	err := row.Scan(
+		&i.ID,
+		&i.CouponID,
+		&i.CustomerID,
+		&i.Discount,
+		&i.Released,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const setCouponActive = `-- name: SetCouponActive :one
+UPDATE "coupon"
+SET "active" = $2
+WHERE "id" = $1
+RETURNING id, code, type, value, max_discount, min_order_value, supplier_id, product_id, usage_limit, usage_limit_per_customer, used_count, starts_at, ends_at, active, created_by, created_at
+`
+
+type SetCouponActiveParams struct {
+	ID     int64
+	Active bool
+}
+
+func (q *Queries) SetCouponActive(ctx context.Context, arg SetCouponActiveParams) (Coupon, error) {
+	row := q.db.QueryRowContext(ctx, setCouponActive, arg.ID, arg.Active)
+	var i Coupon
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Type,
+		&i.Value,
+		&i.MaxDiscount,
+		&i.MinOrderValue,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.UsageLimit,
+		&i.UsageLimitPerCustomer,
+		&i.UsedCount,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Active,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const unredeemCoupon = `-- name: UnredeemCoupon :exec
+UPDATE "coupon"
+SET "used_count" = "used_count" - 1
+WHERE "id" = $1 AND "used_count" > 0
+`
+
+func (q *Queries) UnredeemCoupon(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, unredeemCoupon, id)
+	return err
+}
@@ -0,0 +1,196 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.0
+// source: customer_address.sql
+
+package repository
+
+import (
+	"context"
+)
+
+const clearDefaultCustomerAddress = `-- name: ClearDefaultCustomerAddress :exec
+UPDATE "customer_address"
+SET "is_default" = false, "updated_at" = now()
+WHERE "customer_id" = $1 AND "is_default"
+`
+
+func (q *Queries) ClearDefaultCustomerAddress(ctx context.Context, customerID int64) error {
+	_, err := q.db.ExecContext(ctx, clearDefaultCustomerAddress, customerID)
+	return err
+}
+
+const countCustomerAddress = `-- name: CountCustomerAddress :one
+SELECT COUNT(*) FROM "customer_address"
+WHERE "customer_id" = $1
+`
+
+func (q *Queries) CountCustomerAddress(ctx context.Context, customerID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCustomerAddress, customerID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createCustomerAddress = `-- name: CreateCustomerAddress :one
+INSERT INTO "customer_address" (
+    "customer_id", "name", "phone", "detail", "is_default"
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING id, customer_id, name, phone, detail, is_default, created_at, updated_at
+`
+
+type CreateCustomerAddressParams struct {
+	CustomerID int64
+	Name       string
+	Phone      string
+	Detail     string
+	IsDefault  bool
+}
+
+func (q *Queries) CreateCustomerAddress(ctx context.Context, arg CreateCustomerAddressParams) (CustomerAddress, error) {
+	row := q.db.QueryRowContext(ctx, createCustomerAddress,
+		arg.CustomerID,
+		arg.Name,
+		arg.Phone,
+		arg.Detail,
+		arg.IsDefault,
+	)
+	var i CustomerAddress
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.Name,
+		&i.Phone,
+		&i.Detail,
+		&i.IsDefault,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteCustomerAddress = `-- name: DeleteCustomerAddress :one
+DELETE FROM "customer_address"
+WHERE "id" = $1 AND "customer_id" = $2
+RETURNING id, customer_id, name, phone, detail, is_default, created_at, updated_at
+`
+
+type DeleteCustomerAddressParams struct {
+	ID         int64
+	CustomerID int64
+}
+
+func (q *Queries) DeleteCustomerAddress(ctx context.Context, arg DeleteCustomerAddressParams) (CustomerAddress, error) {
+	row := q.db.QueryRowContext(ctx, deleteCustomerAddress, arg.ID, arg.CustomerID)
+	var i CustomerAddress
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.Name,
+		&i.Phone,
+		&i.Detail,
+		&i.IsDefault,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getCustomerAddressByCustomer = `-- name: GetCustomerAddressByCustomer :many
+SELECT id, customer_id, name, phone, detail, is_default, created_at, updated_at FROM "customer_address"
+WHERE "customer_id" = $1
+ORDER BY "is_default" DESC, "id" DESC
+`
+
+func (q *Queries) GetCustomerAddressByCustomer(ctx context.Context, customerID int64) ([]CustomerAddress, error) {
+	rows, err := q.db.QueryContext(ctx, getCustomerAddressByCustomer, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CustomerAddress
+	for rows.Next() {
+		var i CustomerAddress
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomerID,
+			&i.Name,
+			&i.Phone,
+			&i.Detail,
+			&i.IsDefault,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCustomerAddressByID = `-- name: GetCustomerAddressByID :one
+SELECT id, customer_id, name, phone, detail, is_default, created_at, updated_at FROM "customer_address"
+WHERE "id" = $1 LIMIT 1
+`
+
+func (q *Queries) GetCustomerAddressByID(ctx context.Context, id int64) (CustomerAddress, error) {
+	row := q.db.QueryRowContext(ctx, getCustomerAddressByID, id)
+	var i CustomerAddress
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.Name,
+		&i.Phone,
+		&i.Detail,
+		&i.IsDefault,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateCustomerAddress = `-- name: UpdateCustomerAddress :one
+UPDATE "customer_address"
+SET "name" = $3, "phone" = $4, "detail" = $5, "is_default" = $6, "updated_at" = now()
+WHERE "id" = $1 AND "customer_id" = $2
+RETURNING id, customer_id, name, phone, detail, is_default, created_at, updated_at
+`
+
+type UpdateCustomerAddressParams struct {
+	ID         int64
+	CustomerID int64
+	Name       string
+	Phone      string
+	Detail     string
+	IsDefault  bool
+}
+
+func (q *Queries) UpdateCustomerAddress(ctx context.Context, arg UpdateCustomerAddressParams) (CustomerAddress, error) {
+	row := q.db.QueryRowContext(ctx, updateCustomerAddress,
+		arg.ID,
+		arg.CustomerID,
+		arg.Name,
+		arg.Phone,
+		arg.Detail,
+		arg.IsDefault,
+	)
+	var i CustomerAddress
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.Name,
+		&i.Phone,
+		&i.Detail,
+		&i.IsDefault,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,228 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.0
+// source: dashboard.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const createCheckoutOutcome = `-- name: CreateCheckoutOutcome :exec
+INSERT INTO "checkout_outcome" (
+    "customer_id", "failure_reason", "line_count"
+) VALUES (
+    $1, $2, $3
+)
+`
+
+type CreateCheckoutOutcomeParams struct {
+	CustomerID    int64
+	FailureReason string
+	LineCount     int32
+}
+
+func (q *Queries) CreateCheckoutOutcome(ctx context.Context, arg CreateCheckoutOutcomeParams) error {
+	_, err := q.db.ExecContext(ctx, createCheckoutOutcome, arg.CustomerID, arg.FailureReason, arg.LineCount)
+	return err
+}
+
+const getCheckoutFailures = `-- name: GetCheckoutFailures :many
+SELECT "failure_reason", sum("checkout_count")::bigint AS "checkout_count"
+FROM "checkout_outcome_daily_summary"
+WHERE "day" >= $1::date AND "day" < $2::date
+GROUP BY "failure_reason"
+ORDER BY 2 DESC, 1
+`
+
+type GetCheckoutFailuresParams struct {
+	DayFrom time.Time
+	DayTo   time.Time
+}
+
+type GetCheckoutFailuresRow struct {
+	FailureReason string
+	CheckoutCount int64
+}
+
+func (q *Queries) GetCheckoutFailures(ctx context.Context, arg GetCheckoutFailuresParams) ([]GetCheckoutFailuresRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCheckoutFailures, arg.DayFrom, arg.DayTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCheckoutFailuresRow
+	for rows.Next() {
+		var i GetCheckoutFailuresRow
+		if err := rows.Scan(&i.FailureReason, &i.CheckoutCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDashboardRefreshedAt = `-- name: GetDashboardRefreshedAt :one
+SELECT "refreshed_at" FROM "dashboard_refresh" WHERE "id" = 1
+`
+
+func (q *Queries) GetDashboardRefreshedAt(ctx context.Context) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getDashboardRefreshedAt)
+	var refreshed_at time.Time
+	err := row.Scan(&refreshed_at)
+	return refreshed_at, err
+}
+
+const getGmvSeries = `-- name: GetGmvSeries :many
+SELECT (date_trunc($1::text, "day"::timestamp) AT TIME ZONE 'Asia/Ho_Chi_Minh')::timestamptz AS "bucket_start",
+    sum("order_count")::bigint AS "order_count",
+    sum("cancelled_count")::bigint AS "cancelled_count",
+    sum("unit_count")::bigint AS "unit_count",
+    sum("gmv")::bigint AS "gmv"
+FROM "order_daily_summary"
+WHERE "day" >= $2::date AND "day" < $3::date
+GROUP BY 1
+ORDER BY 1
+`
+
+type GetGmvSeriesParams struct {
+	Bucket  string
+	DayFrom time.Time
+	DayTo   time.Time
+}
+
+type GetGmvSeriesRow struct {
+	BucketStart    time.Time
+	OrderCount     int64
+	CancelledCount int64
+	UnitCount      int64
+	Gmv            int64
+}
+
+func (q *Queries) GetGmvSeries(ctx context.Context, arg GetGmvSeriesParams) ([]GetGmvSeriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGmvSeries, arg.Bucket, arg.DayFrom, arg.DayTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGmvSeriesRow
+	for rows.Next() {
+		var i GetGmvSeriesRow
+		if err := rows.Scan(
+			&i.BucketStart,
+			&i.OrderCount,
+			&i.CancelledCount,
+			&i.UnitCount,
+			&i.Gmv,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderFunnel = `-- name: GetOrderFunnel :one
+SELECT coalesce(sum("order_count"), 0)::bigint AS "order_count",
+    coalesce(sum("waiting_count"), 0)::bigint AS "waiting_count",
+    coalesce(sum("handled_count"), 0)::bigint AS "handled_count",
+    coalesce(sum("cancelled_count"), 0)::bigint AS "cancelled_count"
+FROM "order_daily_summary"
+WHERE "day" >= $1::date AND "day" < $2::date
+`
+
+type GetOrderFunnelParams struct {
+	DayFrom time.Time
+	DayTo   time.Time
+}
+
+type GetOrderFunnelRow struct {
+	OrderCount     int64
+	WaitingCount   int64
+	HandledCount   int64
+	CancelledCount int64
+}
+
+func (q *Queries) GetOrderFunnel(ctx context.Context, arg GetOrderFunnelParams) (GetOrderFunnelRow, error) {
+	row := q.db.QueryRowContext(ctx, getOrderFunnel, arg.DayFrom, arg.DayTo)
+	var i GetOrderFunnelRow
+	err := row.Scan(
+		&i.OrderCount,
+		&i.WaitingCount,
+		&i.HandledCount,
+		&i.CancelledCount,
+	)
+	return i, err
+}
+
+const getSlowestSuppliers = `-- name: GetSlowestSuppliers :many
+SELECT supplier_id, order_count, handled_count, waiting_count, average_handling_seconds, oldest_waiting_at FROM "supplier_handling_summary"
+WHERE "handled_count" > 0
+ORDER BY "average_handling_seconds" DESC, "supplier_id"
+LIMIT $1
+`
+
+func (q *Queries) GetSlowestSuppliers(ctx context.Context, limit int32) ([]SupplierHandlingSummary, error) {
+	rows, err := q.db.QueryContext(ctx, getSlowestSuppliers, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SupplierHandlingSummary
+	for rows.Next() {
+		var i SupplierHandlingSummary
+		if err := rows.Scan(
+			&i.SupplierID,
+			&i.OrderCount,
+			&i.HandledCount,
+			&i.WaitingCount,
+			&i.AverageHandlingSeconds,
+			&i.OldestWaitingAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const refreshDashboardSummary = `-- name: RefreshDashboardSummary :exec
+SELECT refresh_dashboard_summary()
+`
+
+func (q *Queries) RefreshDashboardSummary(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, refreshDashboardSummary)
+	return err
+}
+
+const tryLockDashboardRefresh = `-- name: TryLockDashboardRefresh :one
+SELECT pg_try_advisory_xact_lock(hashtext('dashboard_refresh'))::boolean AS "locked"
+`
+
+// only one replica refreshes the summaries, the others skip their turn
+func (q *Queries) TryLockDashboardRefresh(ctx context.Context) (bool, error) {
+	row := q.db.QueryRowContext(ctx, tryLockDashboardRefresh)
+	var locked bool
+	err := row.Scan(&locked)
+	return locked, err
+}
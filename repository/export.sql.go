@@ -0,0 +1,119 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.0
+// source: export.sql
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const getOrdersForExport = `-- name: GetOrdersForExport :many
+SELECT "order"."id", "order"."order_number", "order"."created_at", "order"."updated_at",
+    "order"."handled_at", "order"."cancelled_at", "order"."status", "order"."payment_status",
+    "order"."customer_id", "order"."supplier_id", "order"."product_id", "order"."product_name",
+    "order"."price", "order"."quantity", "order"."discount", "order"."shipping_fee", "order"."cancel_reason",
+    "address"."name" AS "address_name", "address"."phone" AS "address_phone", "address"."detail" AS "address_detail"
+FROM "order"
+JOIN "address" ON "address"."id" = "order"."address_id"
+WHERE "order"."id" > $1
+    AND ($2::bigint IS NULL OR "order"."customer_id" = $2::bigint)
+    AND ($3::bigint IS NULL OR "order"."supplier_id" = $3::bigint)
+    AND (cardinality($4::text[]) = 0 OR "order"."status"::text = ANY($4::text[]))
+    AND ($5::timestamptz IS NULL OR "order"."created_at" >= $5::timestamptz)
+    AND ($6::timestamptz IS NULL OR "order"."created_at" < $6::timestamptz)
+ORDER BY "order"."id"
+LIMIT $7
+`
+
+type GetOrdersForExportParams struct {
+	AfterID     int64
+	CustomerID  sql.NullInt64
+	SupplierID  sql.NullInt64
+	ListStatus  []string
+	CreatedFrom sql.NullTime
+	CreatedTo   sql.NullTime
+	RowLimit    int32
+}
+
+type GetOrdersForExportRow struct {
+	ID            int64
+	OrderNumber   string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	HandledAt     sql.NullTime
+	CancelledAt   sql.NullTime
+	Status        NullOrderStatusEnum
+	PaymentStatus PaymentStatusEnum
+	CustomerID    int64
+	SupplierID    int64
+	ProductID     int64
+	ProductName   string
+	Price         int64
+	Quantity      int32
+	Discount      int64
+	ShippingFee   int64
+	CancelReason  string
+	AddressName   string
+	AddressPhone  string
+	AddressDetail string
+}
+
+// the orders are read in batches of row_limit after the last id, the
+// export never holds more than one batch
+func (q *Queries) GetOrdersForExport(ctx context.Context, arg GetOrdersForExportParams) ([]GetOrdersForExportRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrdersForExport,
+		arg.AfterID,
+		arg.CustomerID,
+		arg.SupplierID,
+		pq.Array(arg.ListStatus),
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrdersForExportRow
+	for rows.Next() {
+		var i GetOrdersForExportRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderNumber,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.HandledAt,
+			&i.CancelledAt,
+			&i.Status,
+			&i.PaymentStatus,
+			&i.CustomerID,
+			&i.SupplierID,
+			&i.ProductID,
+			&i.ProductName,
+			&i.Price,
+			&i.Quantity,
+			&i.Discount,
+			&i.ShippingFee,
+			&i.CancelReason,
+			&i.AddressName,
+			&i.AddressPhone,
+			&i.AddressDetail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
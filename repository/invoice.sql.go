@@ -0,0 +1,305 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.0
+// source: invoice.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const createInvoice = `-- name: CreateInvoice :one
+INSERT INTO "invoice" (
+    "order_id", "supplier_id", "customer_id", "year", "number", "issued_at",
+    "seller_name", "seller_tax_code", "seller_address",
+    "buyer_name", "buyer_phone", "buyer_address",
+    "subtotal", "tax", "total"
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+) RETURNING id, order_id, supplier_id, customer_id, year, number, issued_at, seller_name, seller_tax_code, seller_address, buyer_name, buyer_phone, buyer_address, subtotal, tax, total
+`
+
+type CreateInvoiceParams struct {
+	OrderID       int64
+	SupplierID    int64
+	CustomerID    int64
+	Year          int32
+	Number        int32
+	IssuedAt      time.Time
+	SellerName    string
+	SellerTaxCode string
+	SellerAddress string
+	BuyerName     string
+	BuyerPhone    string
+	BuyerAddress  string
+	Subtotal      int64
+	Tax           int64
+	Total         int64
+}
+
+func (q *Queries) CreateInvoice(ctx context.Context, arg CreateInvoiceParams) (Invoice, error) {
+	row := q.db.QueryRowContext(ctx, createInvoice,
+		arg.OrderID,
+		arg.SupplierID,
+		arg.CustomerID,
+		arg.Year,
+		arg.Number,
+		arg.IssuedAt,
+		arg.SellerName,
+		arg.SellerTaxCode,
+		arg.SellerAddress,
+		arg.BuyerName,
+		arg.BuyerPhone,
+		arg.BuyerAddress,
+		arg.Subtotal,
+		arg.Tax,
+		arg.Total,
+	)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.SupplierID,
+		&i.CustomerID,
+		&i.Year,
+		&i.Number,
+		&i.IssuedAt,
+		&i.SellerName,
+		&i.SellerTaxCode,
+		&i.SellerAddress,
+		&i.BuyerName,
+		&i.BuyerPhone,
+		&i.BuyerAddress,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Total,
+	)
+	return i, err
+}
+
+const createInvoiceLine = `-- name: CreateInvoiceLine :one
+INSERT INTO "invoice_line" (
+    "invoice_id", "description", "quantity", "unit_price", "amount", "vat_percent", "tax"
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, invoice_id, description, quantity, unit_price, amount, vat_percent, tax
+`
+
+type CreateInvoiceLineParams struct {
+	InvoiceID   int64
+	Description string
+	Quantity    int32
+	UnitPrice   int64
+	Amount      int64
+	VatPercent  int32
+	Tax         int64
+}
+
+func (q *Queries) CreateInvoiceLine(ctx context.Context, arg CreateInvoiceLineParams) (InvoiceLine, error) {
+	row := q.db.QueryRowContext(ctx, createInvoiceLine,
+		arg.InvoiceID,
+		arg.Description,
+		arg.Quantity,
+		arg.UnitPrice,
+		arg.Amount,
+		arg.VatPercent,
+		arg.Tax,
+	)
+	var i InvoiceLine
+	err := row.Scan(
+		&i.ID,
+		&i.InvoiceID,
+		&i.Description,
+		&i.Quantity,
+		&i.UnitPrice,
+		&i.Amount,
+		&i.VatPercent,
+		&i.Tax,
+	)
+	return i, err
+}
+
+const getInvoiceByID = `-- name: GetInvoiceByID :one
+SELECT id, order_id, supplier_id, customer_id, year, number, issued_at, seller_name, seller_tax_code, seller_address, buyer_name, buyer_phone, buyer_address, subtotal, tax, total FROM "invoice"
+WHERE "id" = $1
+`
+
+func (q *Queries) GetInvoiceByID(ctx context.Context, id int64) (Invoice, error) {
+	row := q.db.QueryRowContext(ctx, getInvoiceByID, id)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.SupplierID,
+		&i.CustomerID,
+		&i.Year,
+		&i.Number,
+		&i.IssuedAt,
+		&i.SellerName,
+		&i.SellerTaxCode,
+		&i.SellerAddress,
+		&i.BuyerName,
+		&i.BuyerPhone,
+		&i.BuyerAddress,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Total,
+	)
+	return i, err
+}
+
+const getInvoiceByOrder = `-- name: GetInvoiceByOrder :one
+SELECT id, order_id, supplier_id, customer_id, year, number, issued_at, seller_name, seller_tax_code, seller_address, buyer_name, buyer_phone, buyer_address, subtotal, tax, total FROM "invoice"
+WHERE "order_id" = $1
+`
+
+func (q *Queries) GetInvoiceByOrder(ctx context.Context, orderID int64) (Invoice, error) {
+	row := q.db.QueryRowContext(ctx, getInvoiceByOrder, orderID)
+	var i Invoice
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.SupplierID,
+		&i.CustomerID,
+		&i.Year,
+		&i.Number,
+		&i.IssuedAt,
+		&i.SellerName,
+		&i.SellerTaxCode,
+		&i.SellerAddress,
+		&i.BuyerName,
+		&i.BuyerPhone,
+		&i.BuyerAddress,
+		&i.Subtotal,
+		&i.Tax,
+		&i.Total,
+	)
+	return i, err
+}
+
+const getInvoiceLineByInvoice = `-- name: GetInvoiceLineByInvoice :many
+SELECT id, invoice_id, description, quantity, unit_price, amount, vat_percent, tax FROM "invoice_line"
+WHERE "invoice_id" = $1
+ORDER BY "id"
+`
+
+func (q *Queries) GetInvoiceLineByInvoice(ctx context.Context, invoiceID int64) ([]InvoiceLine, error) {
+	rows, err := q.db.QueryContext(ctx, getInvoiceLineByInvoice, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InvoiceLine
+	for rows.Next() {
+		var i InvoiceLine
+		if err := rows.Scan(
+			&i.ID,
+			&i.InvoiceID,
+			&i.Description,
+			&i.Quantity,
+			&i.UnitPrice,
+			&i.Amount,
+			&i.VatPercent,
+			&i.Tax,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSupplierInvoiceSetting = `-- name: GetSupplierInvoiceSetting :one
+SELECT supplier_id, legal_name, tax_code, address, vat_percent, updated_at FROM "supplier_invoice_setting"
+WHERE "supplier_id" = $1
+`
+
+func (q *Queries) GetSupplierInvoiceSetting(ctx context.Context, supplierID int64) (SupplierInvoiceSetting, error) {
+	row := q.db.QueryRowContext(ctx, getSupplierInvoiceSetting, supplierID)
+	var i SupplierInvoiceSetting
+	err := row.Scan(
+		&i.SupplierID,
+		&i.LegalName,
+		&i.TaxCode,
+		&i.Address,
+		&i.VatPercent,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const nextInvoiceNumber = `-- name: NextInvoiceNumber :one
+INSERT INTO "invoice_sequence" (
+    "supplier_id", "year", "last_number"
+) VALUES (
+    $1, $2, 1
+)
+ON CONFLICT ("supplier_id", "year") DO UPDATE
+SET "last_number" = "invoice_sequence"."last_number" + 1
+RETURNING "last_number"
+`
+
+type NextInvoiceNumberParams struct {
+	SupplierID int64
+	Year       int32
+}
+
+// the row stays locked until the invoice is committed, the invoices of a
+// supplier in a year are numbered one at a time
+func (q *Queries) NextInvoiceNumber(ctx context.Context, arg NextInvoiceNumberParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, nextInvoiceNumber, arg.SupplierID, arg.Year)
+	var last_number int32
+	err := row.Scan(&last_number)
+	return last_number, err
+}
+
+const upsertSupplierInvoiceSetting = `-- name: UpsertSupplierInvoiceSetting :one
+INSERT INTO "supplier_invoice_setting" (
+    "supplier_id", "legal_name", "tax_code", "address", "vat_percent"
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT ("supplier_id") DO UPDATE
+SET "legal_name" = EXCLUDED."legal_name",
+    "tax_code" = EXCLUDED."tax_code",
+    "address" = EXCLUDED."address",
+    "vat_percent" = EXCLUDED."vat_percent",
+    "updated_at" = now()
+RETURNING supplier_id, legal_name, tax_code, address, vat_percent, updated_at
+`
+
+type UpsertSupplierInvoiceSettingParams struct {
+	SupplierID int64
+	LegalName  string
+	TaxCode    string
+	Address    string
+	VatPercent int32
+}
+
+func (q *Queries) UpsertSupplierInvoiceSetting(ctx context.Context, arg UpsertSupplierInvoiceSettingParams) (SupplierInvoiceSetting, error) {
+	row := q.db.QueryRowContext(ctx, upsertSupplierInvoiceSetting,
+		arg.SupplierID,
+		arg.LegalName,
+		arg.TaxCode,
+		arg.Address,
+		arg.VatPercent,
+	)
+	var i SupplierInvoiceSetting
+	err := row.Scan(
+		&i.SupplierID,
+		&i.LegalName,
+		&i.TaxCode,
+		&i.Address,
+		&i.VatPercent,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -5,17 +5,69 @@
 package repository
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"fmt"
 	"time"
 )
 
+type CouponTypeEnum string
+
+const (
+	CouponTypeEnumPercentage CouponTypeEnum = "percentage"
+	CouponTypeEnumFixed      CouponTypeEnum = "fixed"
+)
+
+func (e *CouponTypeEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CouponTypeEnum(s)
+	case string:
+		*e = CouponTypeEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CouponTypeEnum: %T", src)
+	}
+	return nil
+}
+
+type NullCouponTypeEnum struct {
+	CouponTypeEnum CouponTypeEnum
+	Valid          bool // Valid is true if CouponTypeEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCouponTypeEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.CouponTypeEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CouponTypeEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCouponTypeEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CouponTypeEnum), nil
+}
+
+func AllCouponTypeEnumValues() []CouponTypeEnum {
+	return []CouponTypeEnum{
+		CouponTypeEnumPercentage,
+		CouponTypeEnumFixed,
+	}
+}
+
 type OrderStatusEnum string
 
 const (
-	OrderStatusEnumWaiting OrderStatusEnum = "waiting"
-	OrderStatusEnumHandled OrderStatusEnum = "handled"
-	OrderStatusEnumCancel  OrderStatusEnum = "cancel"
+	OrderStatusEnumWaiting   OrderStatusEnum = "waiting"
+	OrderStatusEnumHandled   OrderStatusEnum = "handled"
+	OrderStatusEnumCancel    OrderStatusEnum = "cancel"
+	OrderStatusEnumShipped   OrderStatusEnum = "shipped"
+	OrderStatusEnumDelivered OrderStatusEnum = "delivered"
 )
 
 func (e *OrderStatusEnum) Scan(src interface{}) error {
@@ -53,20 +105,460 @@ func (ns NullOrderStatusEnum) Value() (driver.Value, error) {
 	return string(ns.OrderStatusEnum), nil
 }
 
+func AllOrderStatusEnumValues() []OrderStatusEnum {
+	return []OrderStatusEnum{
+		OrderStatusEnumWaiting,
+		OrderStatusEnumHandled,
+		OrderStatusEnumCancel,
+		OrderStatusEnumShipped,
+		OrderStatusEnumDelivered,
+	}
+}
+
+type PaymentStatusEnum string
+
+const (
+	PaymentStatusEnumUnpaid     PaymentStatusEnum = "unpaid"
+	PaymentStatusEnumAuthorized PaymentStatusEnum = "authorized"
+	PaymentStatusEnumCaptured   PaymentStatusEnum = "captured"
+	PaymentStatusEnumRefunded   PaymentStatusEnum = "refunded"
+	PaymentStatusEnumFailed     PaymentStatusEnum = "failed"
+)
+
+func (e *PaymentStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PaymentStatusEnum(s)
+	case string:
+		*e = PaymentStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PaymentStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullPaymentStatusEnum struct {
+	PaymentStatusEnum PaymentStatusEnum
+	Valid             bool // Valid is true if PaymentStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPaymentStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.PaymentStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PaymentStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPaymentStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PaymentStatusEnum), nil
+}
+
+func AllPaymentStatusEnumValues() []PaymentStatusEnum {
+	return []PaymentStatusEnum{
+		PaymentStatusEnumUnpaid,
+		PaymentStatusEnumAuthorized,
+		PaymentStatusEnumCaptured,
+		PaymentStatusEnumRefunded,
+		PaymentStatusEnumFailed,
+	}
+}
+
+type RefundStatusEnum string
+
+const (
+	RefundStatusEnumPending  RefundStatusEnum = "pending"
+	RefundStatusEnumRefunded RefundStatusEnum = "refunded"
+	RefundStatusEnumManual   RefundStatusEnum = "manual"
+)
+
+func (e *RefundStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RefundStatusEnum(s)
+	case string:
+		*e = RefundStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RefundStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullRefundStatusEnum struct {
+	RefundStatusEnum RefundStatusEnum
+	Valid            bool // Valid is true if RefundStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRefundStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.RefundStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RefundStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRefundStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RefundStatusEnum), nil
+}
+
+func AllRefundStatusEnumValues() []RefundStatusEnum {
+	return []RefundStatusEnum{
+		RefundStatusEnumPending,
+		RefundStatusEnumRefunded,
+		RefundStatusEnumManual,
+	}
+}
+
+type ReturnStatusEnum string
+
+const (
+	ReturnStatusEnumRequested ReturnStatusEnum = "requested"
+	ReturnStatusEnumApproved  ReturnStatusEnum = "approved"
+	ReturnStatusEnumRejected  ReturnStatusEnum = "rejected"
+	ReturnStatusEnumReceived  ReturnStatusEnum = "received"
+	ReturnStatusEnumRefunded  ReturnStatusEnum = "refunded"
+)
+
+func (e *ReturnStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ReturnStatusEnum(s)
+	case string:
+		*e = ReturnStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ReturnStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullReturnStatusEnum struct {
+	ReturnStatusEnum ReturnStatusEnum
+	Valid            bool // Valid is true if ReturnStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullReturnStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.ReturnStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ReturnStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullReturnStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ReturnStatusEnum), nil
+}
+
+func AllReturnStatusEnumValues() []ReturnStatusEnum {
+	return []ReturnStatusEnum{
+		ReturnStatusEnumRequested,
+		ReturnStatusEnumApproved,
+		ReturnStatusEnumRejected,
+		ReturnStatusEnumReceived,
+		ReturnStatusEnumRefunded,
+	}
+}
+
+type ShipmentStatusEnum string
+
+const (
+	ShipmentStatusEnumPending   ShipmentStatusEnum = "pending"
+	ShipmentStatusEnumShipped   ShipmentStatusEnum = "shipped"
+	ShipmentStatusEnumDelivered ShipmentStatusEnum = "delivered"
+)
+
+func (e *ShipmentStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ShipmentStatusEnum(s)
+	case string:
+		*e = ShipmentStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ShipmentStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullShipmentStatusEnum struct {
+	ShipmentStatusEnum ShipmentStatusEnum
+	Valid              bool // Valid is true if ShipmentStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullShipmentStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.ShipmentStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ShipmentStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullShipmentStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ShipmentStatusEnum), nil
+}
+
+func AllShipmentStatusEnumValues() []ShipmentStatusEnum {
+	return []ShipmentStatusEnum{
+		ShipmentStatusEnumPending,
+		ShipmentStatusEnumShipped,
+		ShipmentStatusEnumDelivered,
+	}
+}
+
 type Address struct {
-	ID     int64
-	Name   string
-	Phone  string
-	Detail string
+	ID         int64
+	Name       string
+	Phone      string
+	Detail     string
+	CustomerID sql.NullInt64
+}
+
+type CheckoutOutcome struct {
+	ID            int64
+	CustomerID    int64
+	FailureReason string
+	LineCount     int32
+	CreatedAt     time.Time
+}
+
+type CheckoutOutcomeDailySummary struct {
+	Day           time.Time
+	FailureReason string
+	CheckoutCount int64
+}
+
+type Coupon struct {
+	ID                    int64
+	Code                  string
+	Type                  CouponTypeEnum
+	Value                 int64
+	MaxDiscount           int64
+	MinOrderValue         int64
+	SupplierID            int64
+	ProductID             int64
+	UsageLimit            int32
+	UsageLimitPerCustomer int32
+	UsedCount             int32
+	StartsAt              time.Time
+	EndsAt                sql.NullTime
+	Active                bool
+	CreatedBy             int64
+	CreatedAt             time.Time
+}
+
+type CouponRedemption struct {
+	ID         int64
+	CouponID   int64
+	CustomerID int64
+	Discount   int64
+	Released   bool
+	CreatedAt  time.Time
+}
+
+type CustomerAddress struct {
+	ID         int64
+	CustomerID int64
+	Name       string
+	Phone      string
+	Detail     string
+	IsDefault  bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type DashboardRefresh struct {
+	ID          int32
+	RefreshedAt time.Time
+}
+
+type Invoice struct {
+	ID            int64
+	OrderID       int64
+	SupplierID    int64
+	CustomerID    int64
+	Year          int32
+	Number        int32
+	IssuedAt      time.Time
+	SellerName    string
+	SellerTaxCode string
+	SellerAddress string
+	BuyerName     string
+	BuyerPhone    string
+	BuyerAddress  string
+	Subtotal      int64
+	Tax           int64
+	Total         int64
+}
+
+type InvoiceLine struct {
+	ID          int64
+	InvoiceID   int64
+	Description string
+	Quantity    int32
+	UnitPrice   int64
+	Amount      int64
+	VatPercent  int32
+	Tax         int64
+}
+
+type InvoiceSequence struct {
+	SupplierID int64
+	Year       int32
+	LastNumber int32
 }
 
 type Order struct {
+	ID                 int64
+	CustomerID         int64
+	SupplierID         int64
+	ProductID          int64
+	Quantity           int32
+	Status             NullOrderStatusEnum
+	AddressID          int64
+	CreatedAt          time.Time
+	Price              int64
+	PaymentStatus      PaymentStatusEnum
+	PaymentID          string
+	Discount           int64
+	CouponRedemptionID sql.NullInt64
+	CancelReason       string
+	UpdatedAt          time.Time
+	HandledAt          sql.NullTime
+	CancelledAt        sql.NullTime
+	OrderNumber        string
+	ProductName        string
+	HeldAt             sql.NullTime
+	HoldReason         string
+	RestockPending     bool
+	RestockRetryAt     sql.NullTime
+	RestockAttempts    int32
+	ShippingFee        int64
+}
+
+type OrderAudit struct {
+	ID        int64
+	OrderID   int64
+	AdminID   int64
+	Action    string
+	Reason    string
+	OldStatus NullOrderStatusEnum
+	NewStatus NullOrderStatusEnum
+	CreatedAt time.Time
+}
+
+type OrderDailySummary struct {
+	Day            time.Time
+	OrderCount     int64
+	WaitingCount   int64
+	HandledCount   int64
+	CancelledCount int64
+	UnitCount      int64
+	Gmv            int64
+}
+
+type OrderSearch struct {
+	OrderID  int64
+	Document interface{}
+}
+
+type OrderStatusHistory struct {
+	ID        int64
+	OrderID   int64
+	OldStatus NullOrderStatusEnum
+	NewStatus NullOrderStatusEnum
+	CreatedAt time.Time
+}
+
+type Refund struct {
+	ID        int64
+	ReturnID  int64
+	OrderID   int64
+	Amount    int64
+	PaymentID string
+	CreatedAt time.Time
+	Status    RefundStatusEnum
+	Attempts  int32
+	RetryAt   sql.NullTime
+}
+
+type ReturnItem struct {
+	ID       int64
+	ReturnID int64
+	OrderID  int64
+	Quantity int32
+}
+
+type ReturnRequest struct {
 	ID         int64
 	CustomerID int64
 	SupplierID int64
-	ProductID  int64
-	Quantity   int32
-	Status     NullOrderStatusEnum
-	AddressID  int64
+	Reason     string
+	Status     ReturnStatusEnum
+	Note       string
 	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type Shipment struct {
+	ID             int64
+	SupplierID     int64
+	Carrier        string
+	TrackingNumber string
+	Status         ShipmentStatusEnum
+	ShippedAt      sql.NullTime
+	DeliveredAt    sql.NullTime
+	CreatedAt      time.Time
+}
+
+type ShipmentItem struct {
+	ID         int64
+	ShipmentID int64
+	OrderID    int64
+	Quantity   int32
+}
+
+type SupplierHandlingSummary struct {
+	SupplierID             int64
+	OrderCount             int64
+	HandledCount           int64
+	WaitingCount           int64
+	AverageHandlingSeconds float64
+	OldestWaitingAt        sql.NullTime
+}
+
+type SupplierInvoiceSetting struct {
+	SupplierID int64
+	LegalName  string
+	TaxCode    string
+	Address    string
+	VatPercent int32
+	UpdatedAt  time.Time
+}
+
+type SupplierOrderSetting struct {
+	SupplierID          int64
+	HandleDeadlineHours int32
+	UpdatedAt           time.Time
 }
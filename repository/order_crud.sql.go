@@ -7,22 +7,54 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
 )
 
-const cancelOrder = `-- name: CancelOrder :exec
+const cancelOrder = `-- name: CancelOrder :one
 UPDATE "order"
-SET "status" = 'cancel'
-WHERE "id" = $1
+SET "status" = 'cancel', "restock_pending" = true
+WHERE "id" = $1 AND "status" = 'waiting' AND "held_at" IS NULL
+RETURNING id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee
 `
 
-func (q *Queries) CancelOrder(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, cancelOrder, id)
-	return err
+func (q *Queries) CancelOrder(ctx context.Context, id int64) (Order, error) {
+	row := q.db.QueryRowContext(ctx, cancelOrder, id)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.Status,
+		&i.AddressID,
+		&i.CreatedAt,
+		&i.Price,
+		&i.PaymentStatus,
+		&i.PaymentID,
+		&i.Discount,
+		&i.CouponRedemptionID,
+		&i.CancelReason,
+		&i.UpdatedAt,
+		&i.HandledAt,
+		&i.CancelledAt,
+		&i.OrderNumber,
+		&i.ProductName,
+		&i.HeldAt,
+		&i.HoldReason,
+		&i.RestockPending,
+		&i.RestockRetryAt,
+		&i.RestockAttempts,
+		&i.ShippingFee,
+	)
+	return i, err
 }
 
 const checkOrderIsHandled = `-- name: CheckOrderIsHandled :one
 SELECT COUNT(*) FROM "order"
-WHERE "product_id" = $1 AND "customer_id" = $2 AND "status" = 'handled'
+WHERE "product_id" = $1 AND "customer_id" = $2 AND "status" IN ('handled', 'shipped', 'delivered')
 `
 
 type CheckOrderIsHandledParams struct {
@@ -51,7 +83,7 @@ func (q *Queries) CountOrderByProductId(ctx context.Context, productID int64) (i
 
 const countOrderHandledByProductId = `-- name: CountOrderHandledByProductId :many
 SELECT "quantity" from "order"
-WHERE "product_id" = $1 AND "status" = 'handled'
+WHERE "product_id" = $1 AND "status" IN ('handled', 'shipped', 'delivered')
 `
 
 func (q *Queries) CountOrderHandledByProductId(ctx context.Context, productID int64) ([]int32, error) {
@@ -79,44 +111,60 @@ func (q *Queries) CountOrderHandledByProductId(ctx context.Context, productID in
 
 const createAddress = `-- name: CreateAddress :one
 INSERT INTO "address" (
-    "name", "phone", "detail"
+    "name", "phone", "detail", "customer_id"
 ) VALUES (
-    $1, $2, $3
-) RETURNING id, name, phone, detail
+    $1, $2, $3, $4
+) RETURNING id, name, phone, detail, customer_id
 `
 
 type CreateAddressParams struct {
-	Name   string
-	Phone  string
-	Detail string
+	Name       string
+	Phone      string
+	Detail     string
+	CustomerID sql.NullInt64
 }
 
 func (q *Queries) CreateAddress(ctx context.Context, arg CreateAddressParams) (Address, error) {
-	row := q.db.QueryRowContext(ctx, createAddress, arg.Name, arg.Phone, arg.Detail)
+	row := q.db.QueryRowContext(ctx, createAddress,
+		arg.Name,
+		arg.Phone,
+		arg.Detail,
+		arg.CustomerID,
+	)
 	var i Address
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.Phone,
 		&i.Detail,
+		&i.CustomerID,
 	)
 	return i, err
 }
 
 const createOrder = `-- name: CreateOrder :one
 INSERT INTO "order" (
-    "customer_id", "supplier_id", "product_id", "quantity", "address_id"
+    "customer_id", "supplier_id", "product_id", "quantity", "address_id", "price", "discount", "coupon_redemption_id",
+    "payment_status", "payment_id", "order_number", "product_name", "shipping_fee"
 ) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee
 `
 
 type CreateOrderParams struct {
-	CustomerID int64
-	SupplierID int64
-	ProductID  int64
-	Quantity   int32
-	AddressID  int64
+	CustomerID         int64
+	SupplierID         int64
+	ProductID          int64
+	Quantity           int32
+	AddressID          int64
+	Price              int64
+	Discount           int64
+	CouponRedemptionID sql.NullInt64
+	PaymentStatus      PaymentStatusEnum
+	PaymentID          string
+	OrderNumber        string
+	ProductName        string
+	ShippingFee        int64
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error) {
@@ -126,6 +174,14 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		arg.ProductID,
 		arg.Quantity,
 		arg.AddressID,
+		arg.Price,
+		arg.Discount,
+		arg.CouponRedemptionID,
+		arg.PaymentStatus,
+		arg.PaymentID,
+		arg.OrderNumber,
+		arg.ProductName,
+		arg.ShippingFee,
 	)
 	var i Order
 	err := row.Scan(
@@ -137,6 +193,23 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		&i.Status,
 		&i.AddressID,
 		&i.CreatedAt,
+		&i.Price,
+		&i.PaymentStatus,
+		&i.PaymentID,
+		&i.Discount,
+		&i.CouponRedemptionID,
+		&i.CancelReason,
+		&i.UpdatedAt,
+		&i.HandledAt,
+		&i.CancelledAt,
+		&i.OrderNumber,
+		&i.ProductName,
+		&i.HeldAt,
+		&i.HoldReason,
+		&i.RestockPending,
+		&i.RestockRetryAt,
+		&i.RestockAttempts,
+		&i.ShippingFee,
 	)
 	return i, err
 }
@@ -151,19 +224,48 @@ func (q *Queries) DeleteAddress(ctx context.Context, id int64) error {
 	return err
 }
 
-const deleteOrder = `-- name: DeleteOrder :exec
+const deleteOrder = `-- name: DeleteOrder :one
 UPDATE "order"
-SET "status" = 'cancel'
-WHERE "id" = $1
+SET "status" = 'cancel', "restock_pending" = true
+WHERE "id" = $1 AND "status" IN ('waiting', 'handled') AND "held_at" IS NULL
+RETURNING id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee
 `
 
-func (q *Queries) DeleteOrder(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteOrder, id)
-	return err
+func (q *Queries) DeleteOrder(ctx context.Context, id int64) (Order, error) {
+	row := q.db.QueryRowContext(ctx, deleteOrder, id)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.Status,
+		&i.AddressID,
+		&i.CreatedAt,
+		&i.Price,
+		&i.PaymentStatus,
+		&i.PaymentID,
+		&i.Discount,
+		&i.CouponRedemptionID,
+		&i.CancelReason,
+		&i.UpdatedAt,
+		&i.HandledAt,
+		&i.CancelledAt,
+		&i.OrderNumber,
+		&i.ProductName,
+		&i.HeldAt,
+		&i.HoldReason,
+		&i.RestockPending,
+		&i.RestockRetryAt,
+		&i.RestockAttempts,
+		&i.ShippingFee,
+	)
+	return i, err
 }
 
 const getAddressById = `-- name: GetAddressById :one
-SELECT id, name, phone, detail FROM "address"
+SELECT id, name, phone, detail, customer_id FROM "address"
 WHERE "id" = $1
 LIMIT 1
 `
@@ -176,12 +278,13 @@ func (q *Queries) GetAddressById(ctx context.Context, id int64) (Address, error)
 		&i.Name,
 		&i.Phone,
 		&i.Detail,
+		&i.CustomerID,
 	)
 	return i, err
 }
 
 const getCancelOrderByCustomer = `-- name: GetCancelOrderByCustomer :many
-SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at FROM "order"
+SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee FROM "order"
 WHERE "customer_id" = $1 AND "status" = 'cancel'
 `
 
@@ -203,6 +306,23 @@ func (q *Queries) GetCancelOrderByCustomer(ctx context.Context, customerID int64
 			&i.Status,
 			&i.AddressID,
 			&i.CreatedAt,
+			&i.Price,
+			&i.PaymentStatus,
+			&i.PaymentID,
+			&i.Discount,
+			&i.CouponRedemptionID,
+			&i.CancelReason,
+			&i.UpdatedAt,
+			&i.HandledAt,
+			&i.CancelledAt,
+			&i.OrderNumber,
+			&i.ProductName,
+			&i.HeldAt,
+			&i.HoldReason,
+			&i.RestockPending,
+			&i.RestockRetryAt,
+			&i.RestockAttempts,
+			&i.ShippingFee,
 		); err != nil {
 			return nil, err
 		}
@@ -218,7 +338,7 @@ func (q *Queries) GetCancelOrderByCustomer(ctx context.Context, customerID int64
 }
 
 const getCancelOrderBySupplier = `-- name: GetCancelOrderBySupplier :many
-SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at FROM "order"
+SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee FROM "order"
 WHERE "supplier_id" = $1 AND "status" = 'cancel'
 `
 
@@ -240,6 +360,23 @@ func (q *Queries) GetCancelOrderBySupplier(ctx context.Context, supplierID int64
 			&i.Status,
 			&i.AddressID,
 			&i.CreatedAt,
+			&i.Price,
+			&i.PaymentStatus,
+			&i.PaymentID,
+			&i.Discount,
+			&i.CouponRedemptionID,
+			&i.CancelReason,
+			&i.UpdatedAt,
+			&i.HandledAt,
+			&i.CancelledAt,
+			&i.OrderNumber,
+			&i.ProductName,
+			&i.HeldAt,
+			&i.HoldReason,
+			&i.RestockPending,
+			&i.RestockRetryAt,
+			&i.RestockAttempts,
+			&i.ShippingFee,
 		); err != nil {
 			return nil, err
 		}
@@ -255,8 +392,8 @@ func (q *Queries) GetCancelOrderBySupplier(ctx context.Context, supplierID int64
 }
 
 const getHandledOrderByCustomer = `-- name: GetHandledOrderByCustomer :many
-SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at FROM "order"
-WHERE "customer_id" = $1 AND "status" = 'handled'
+SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee FROM "order"
+WHERE "customer_id" = $1 AND "status" IN ('handled', 'shipped', 'delivered')
 `
 
 func (q *Queries) GetHandledOrderByCustomer(ctx context.Context, customerID int64) ([]Order, error) {
@@ -277,6 +414,23 @@ func (q *Queries) GetHandledOrderByCustomer(ctx context.Context, customerID int6
 			&i.Status,
 			&i.AddressID,
 			&i.CreatedAt,
+			&i.Price,
+			&i.PaymentStatus,
+			&i.PaymentID,
+			&i.Discount,
+			&i.CouponRedemptionID,
+			&i.CancelReason,
+			&i.UpdatedAt,
+			&i.HandledAt,
+			&i.CancelledAt,
+			&i.OrderNumber,
+			&i.ProductName,
+			&i.HeldAt,
+			&i.HoldReason,
+			&i.RestockPending,
+			&i.RestockRetryAt,
+			&i.RestockAttempts,
+			&i.ShippingFee,
 		); err != nil {
 			return nil, err
 		}
@@ -292,8 +446,8 @@ func (q *Queries) GetHandledOrderByCustomer(ctx context.Context, customerID int6
 }
 
 const getHandledOrderBySupplier = `-- name: GetHandledOrderBySupplier :many
-SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at FROM "order"
-WHERE "supplier_id" = $1 AND "status" = 'handled'
+SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee FROM "order"
+WHERE "supplier_id" = $1 AND "status" IN ('handled', 'shipped', 'delivered')
 `
 
 func (q *Queries) GetHandledOrderBySupplier(ctx context.Context, supplierID int64) ([]Order, error) {
@@ -314,6 +468,77 @@ func (q *Queries) GetHandledOrderBySupplier(ctx context.Context, supplierID int6
 			&i.Status,
 			&i.AddressID,
 			&i.CreatedAt,
+			&i.Price,
+			&i.PaymentStatus,
+			&i.PaymentID,
+			&i.Discount,
+			&i.CouponRedemptionID,
+			&i.CancelReason,
+			&i.UpdatedAt,
+			&i.HandledAt,
+			&i.CancelledAt,
+			&i.OrderNumber,
+			&i.ProductName,
+			&i.HeldAt,
+			&i.HoldReason,
+			&i.RestockPending,
+			&i.RestockRetryAt,
+			&i.RestockAttempts,
+			&i.ShippingFee,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderByAddressId = `-- name: GetOrderByAddressId :many
+SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee FROM "order"
+WHERE "address_id" = $1
+`
+
+func (q *Queries) GetOrderByAddressId(ctx context.Context, addressID int64) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderByAddressId, addressID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomerID,
+			&i.SupplierID,
+			&i.ProductID,
+			&i.Quantity,
+			&i.Status,
+			&i.AddressID,
+			&i.CreatedAt,
+			&i.Price,
+			&i.PaymentStatus,
+			&i.PaymentID,
+			&i.Discount,
+			&i.CouponRedemptionID,
+			&i.CancelReason,
+			&i.UpdatedAt,
+			&i.HandledAt,
+			&i.CancelledAt,
+			&i.OrderNumber,
+			&i.ProductName,
+			&i.HeldAt,
+			&i.HoldReason,
+			&i.RestockPending,
+			&i.RestockRetryAt,
+			&i.RestockAttempts,
+			&i.ShippingFee,
 		); err != nil {
 			return nil, err
 		}
@@ -329,7 +554,7 @@ func (q *Queries) GetHandledOrderBySupplier(ctx context.Context, supplierID int6
 }
 
 const getOrderByID = `-- name: GetOrderByID :one
-SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at FROM "order"
+SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee FROM "order"
 WHERE "id" = $1 LIMIT 1
 `
 
@@ -345,12 +570,139 @@ func (q *Queries) GetOrderByID(ctx context.Context, id int64) (Order, error) {
 		&i.Status,
 		&i.AddressID,
 		&i.CreatedAt,
+		&i.Price,
+		&i.PaymentStatus,
+		&i.PaymentID,
+		&i.Discount,
+		&i.CouponRedemptionID,
+		&i.CancelReason,
+		&i.UpdatedAt,
+		&i.HandledAt,
+		&i.CancelledAt,
+		&i.OrderNumber,
+		&i.ProductName,
+		&i.HeldAt,
+		&i.HoldReason,
+		&i.RestockPending,
+		&i.RestockRetryAt,
+		&i.RestockAttempts,
+		&i.ShippingFee,
+	)
+	return i, err
+}
+
+const getOrderByIDForUpdate = `-- name: GetOrderByIDForUpdate :one
+SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee FROM "order"
+WHERE "id" = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetOrderByIDForUpdate(ctx context.Context, id int64) (Order, error) {
+	row := q.db.QueryRowContext(ctx, getOrderByIDForUpdate, id)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.Status,
+		&i.AddressID,
+		&i.CreatedAt,
+		&i.Price,
+		&i.PaymentStatus,
+		&i.PaymentID,
+		&i.Discount,
+		&i.CouponRedemptionID,
+		&i.CancelReason,
+		&i.UpdatedAt,
+		&i.HandledAt,
+		&i.CancelledAt,
+		&i.OrderNumber,
+		&i.ProductName,
+		&i.HeldAt,
+		&i.HoldReason,
+		&i.RestockPending,
+		&i.RestockRetryAt,
+		&i.RestockAttempts,
+		&i.ShippingFee,
 	)
 	return i, err
 }
 
+const getOrderByNumber = `-- name: GetOrderByNumber :one
+SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee FROM "order"
+WHERE "order_number" = $1 LIMIT 1
+`
+
+func (q *Queries) GetOrderByNumber(ctx context.Context, orderNumber string) (Order, error) {
+	row := q.db.QueryRowContext(ctx, getOrderByNumber, orderNumber)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.Status,
+		&i.AddressID,
+		&i.CreatedAt,
+		&i.Price,
+		&i.PaymentStatus,
+		&i.PaymentID,
+		&i.Discount,
+		&i.CouponRedemptionID,
+		&i.CancelReason,
+		&i.UpdatedAt,
+		&i.HandledAt,
+		&i.CancelledAt,
+		&i.OrderNumber,
+		&i.ProductName,
+		&i.HeldAt,
+		&i.HoldReason,
+		&i.RestockPending,
+		&i.RestockRetryAt,
+		&i.RestockAttempts,
+		&i.ShippingFee,
+	)
+	return i, err
+}
+
+const getOrderNumberByIDs = `-- name: GetOrderNumberByIDs :many
+SELECT "id", "order_number" FROM "order"
+WHERE "id" = ANY($1::bigint[])
+`
+
+type GetOrderNumberByIDsRow struct {
+	ID          int64
+	OrderNumber string
+}
+
+func (q *Queries) GetOrderNumberByIDs(ctx context.Context, ids []int64) ([]GetOrderNumberByIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderNumberByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrderNumberByIDsRow
+	for rows.Next() {
+		var i GetOrderNumberByIDsRow
+		if err := rows.Scan(&i.ID, &i.OrderNumber); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getWaitingOrderByCustomer = `-- name: GetWaitingOrderByCustomer :many
-SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at FROM "order"
+SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee FROM "order"
 WHERE "customer_id" = $1 AND "status" = 'waiting'
 `
 
@@ -372,6 +724,23 @@ func (q *Queries) GetWaitingOrderByCustomer(ctx context.Context, customerID int6
 			&i.Status,
 			&i.AddressID,
 			&i.CreatedAt,
+			&i.Price,
+			&i.PaymentStatus,
+			&i.PaymentID,
+			&i.Discount,
+			&i.CouponRedemptionID,
+			&i.CancelReason,
+			&i.UpdatedAt,
+			&i.HandledAt,
+			&i.CancelledAt,
+			&i.OrderNumber,
+			&i.ProductName,
+			&i.HeldAt,
+			&i.HoldReason,
+			&i.RestockPending,
+			&i.RestockRetryAt,
+			&i.RestockAttempts,
+			&i.ShippingFee,
 		); err != nil {
 			return nil, err
 		}
@@ -387,7 +756,7 @@ func (q *Queries) GetWaitingOrderByCustomer(ctx context.Context, customerID int6
 }
 
 const getWaitingOrderBySupplier = `-- name: GetWaitingOrderBySupplier :many
-SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at FROM "order"
+SELECT id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee FROM "order"
 WHERE "supplier_id" = $1 AND "status" = 'waiting'
 `
 
@@ -409,6 +778,23 @@ func (q *Queries) GetWaitingOrderBySupplier(ctx context.Context, supplierID int6
 			&i.Status,
 			&i.AddressID,
 			&i.CreatedAt,
+			&i.Price,
+			&i.PaymentStatus,
+			&i.PaymentID,
+			&i.Discount,
+			&i.CouponRedemptionID,
+			&i.CancelReason,
+			&i.UpdatedAt,
+			&i.HandledAt,
+			&i.CancelledAt,
+			&i.OrderNumber,
+			&i.ProductName,
+			&i.HeldAt,
+			&i.HoldReason,
+			&i.RestockPending,
+			&i.RestockRetryAt,
+			&i.RestockAttempts,
+			&i.ShippingFee,
 		); err != nil {
 			return nil, err
 		}
@@ -423,29 +809,105 @@ func (q *Queries) GetWaitingOrderBySupplier(ctx context.Context, supplierID int6
 	return items, nil
 }
 
-const handleOrder = `-- name: HandleOrder :exec
+const handleOrder = `-- name: HandleOrder :one
 UPDATE "order"
 SET "status" = 'handled'
+WHERE "id" = $1 AND "status" = 'waiting' AND "held_at" IS NULL
+RETURNING id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee
+`
+
+func (q *Queries) HandleOrder(ctx context.Context, id int64) (Order, error) {
+	row := q.db.QueryRowContext(ctx, handleOrder, id)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.Status,
+		&i.AddressID,
+		&i.CreatedAt,
+		&i.Price,
+		&i.PaymentStatus,
+		&i.PaymentID,
+		&i.Discount,
+		&i.CouponRedemptionID,
+		&i.CancelReason,
+		&i.UpdatedAt,
+		&i.HandledAt,
+		&i.CancelledAt,
+		&i.OrderNumber,
+		&i.ProductName,
+		&i.HeldAt,
+		&i.HoldReason,
+		&i.RestockPending,
+		&i.RestockRetryAt,
+		&i.RestockAttempts,
+		&i.ShippingFee,
+	)
+	return i, err
+}
+
+const updateOrderPayment = `-- name: UpdateOrderPayment :exec
+UPDATE "order"
+SET "payment_status" = $2, "payment_id" = $3
 WHERE "id" = $1
 `
 
-func (q *Queries) HandleOrder(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, handleOrder, id)
+type UpdateOrderPaymentParams struct {
+	ID            int64
+	PaymentStatus PaymentStatusEnum
+	PaymentID     string
+}
+
+func (q *Queries) UpdateOrderPayment(ctx context.Context, arg UpdateOrderPaymentParams) error {
+	_, err := q.db.ExecContext(ctx, updateOrderPayment, arg.ID, arg.PaymentStatus, arg.PaymentID)
 	return err
 }
 
-const updateOrderStatus = `-- name: UpdateOrderStatus :exec
+const updateOrderStatus = `-- name: UpdateOrderStatus :one
 UPDATE "order"
 SET "status" = $1
-WHERE "id" = $2
+WHERE "id" = $2 AND "status" = $3
+RETURNING id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee
 `
 
 type UpdateOrderStatusParams struct {
-	Status NullOrderStatusEnum
-	ID     int64
+	NewStatus NullOrderStatusEnum
+	ID        int64
+	OldStatus NullOrderStatusEnum
 }
 
-func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) error {
-	_, err := q.db.ExecContext(ctx, updateOrderStatus, arg.Status, arg.ID)
-	return err
+func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, updateOrderStatus, arg.NewStatus, arg.ID, arg.OldStatus)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.Status,
+		&i.AddressID,
+		&i.CreatedAt,
+		&i.Price,
+		&i.PaymentStatus,
+		&i.PaymentID,
+		&i.Discount,
+		&i.CouponRedemptionID,
+		&i.CancelReason,
+		&i.UpdatedAt,
+		&i.HandledAt,
+		&i.CancelledAt,
+		&i.OrderNumber,
+		&i.ProductName,
+		&i.HeldAt,
+		&i.HoldReason,
+		&i.RestockPending,
+		&i.RestockRetryAt,
+		&i.RestockAttempts,
+		&i.ShippingFee,
+	)
+	return i, err
 }
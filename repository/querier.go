@@ -0,0 +1,138 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.0
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type Querier interface {
+	CancelOrder(ctx context.Context, id int64) (Order, error)
+	CancelWaitingOrder(ctx context.Context, arg CancelWaitingOrderParams) (Order, error)
+	CheckOrderIsHandled(ctx context.Context, arg CheckOrderIsHandledParams) (int64, error)
+	ClaimDueOrderRestock(ctx context.Context, arg ClaimDueOrderRestockParams) ([]Order, error)
+	ClaimDueRefund(ctx context.Context, arg ClaimDueRefundParams) ([]Refund, error)
+	// the claim keeps the restock of the order until its next retry, another
+	// replica tries it again when the claimer died before FinishOrderRestock.
+	// Every attempt doubles the wait, up to 64 times retry_after_seconds
+	ClaimOrderRestock(ctx context.Context, arg ClaimOrderRestockParams) (Order, error)
+	// the claim keeps the refund until its next retry, another replica tries it
+	// again when the claimer died before FinishRefund. Every attempt doubles the
+	// wait, up to 64 times retry_after_seconds
+	ClaimRefund(ctx context.Context, arg ClaimRefundParams) (Refund, error)
+	ClearDefaultCustomerAddress(ctx context.Context, customerID int64) error
+	CountActiveOrderByCouponRedemption(ctx context.Context, couponRedemptionID sql.NullInt64) (int64, error)
+	CountCouponRedemptionByCustomer(ctx context.Context, arg CountCouponRedemptionByCustomerParams) (int64, error)
+	CountCustomerAddress(ctx context.Context, customerID int64) (int64, error)
+	CountOrderByProductId(ctx context.Context, productID int64) (int64, error)
+	CountOrderHandledByProductId(ctx context.Context, productID int64) ([]int32, error)
+	CreateAddress(ctx context.Context, arg CreateAddressParams) (Address, error)
+	CreateCheckoutOutcome(ctx context.Context, arg CreateCheckoutOutcomeParams) error
+	CreateCoupon(ctx context.Context, arg CreateCouponParams) (Coupon, error)
+	CreateCouponRedemption(ctx context.Context, arg CreateCouponRedemptionParams) (CouponRedemption, error)
+	CreateCustomerAddress(ctx context.Context, arg CreateCustomerAddressParams) (CustomerAddress, error)
+	CreateInvoice(ctx context.Context, arg CreateInvoiceParams) (Invoice, error)
+	CreateInvoiceLine(ctx context.Context, arg CreateInvoiceLineParams) (InvoiceLine, error)
+	CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error)
+	CreateOrderAudit(ctx context.Context, arg CreateOrderAuditParams) (OrderAudit, error)
+	CreateRefund(ctx context.Context, arg CreateRefundParams) (Refund, error)
+	CreateReturnItem(ctx context.Context, arg CreateReturnItemParams) (ReturnItem, error)
+	CreateReturnRequest(ctx context.Context, arg CreateReturnRequestParams) (ReturnRequest, error)
+	CreateShipment(ctx context.Context, arg CreateShipmentParams) (Shipment, error)
+	CreateShipmentItem(ctx context.Context, arg CreateShipmentItemParams) (ShipmentItem, error)
+	DeleteAddress(ctx context.Context, id int64) error
+	DeleteCustomerAddress(ctx context.Context, arg DeleteCustomerAddressParams) (CustomerAddress, error)
+	DeleteOrder(ctx context.Context, id int64) (Order, error)
+	DeliverShipment(ctx context.Context, id int64) (Shipment, error)
+	FinishOrderRestock(ctx context.Context, id int64) error
+	FinishRefund(ctx context.Context, id int64) error
+	// an admin can cancel a held order, shipped goods come back through a return
+	ForceCancelOrder(ctx context.Context, arg ForceCancelOrderParams) (Order, error)
+	GetAddressById(ctx context.Context, id int64) (Address, error)
+	GetAllCoupon(ctx context.Context) ([]Coupon, error)
+	GetCancelOrderByCustomer(ctx context.Context, customerID int64) ([]Order, error)
+	GetCancelOrderBySupplier(ctx context.Context, supplierID int64) ([]Order, error)
+	GetCheckoutFailures(ctx context.Context, arg GetCheckoutFailuresParams) ([]GetCheckoutFailuresRow, error)
+	GetCouponByCode(ctx context.Context, code string) (Coupon, error)
+	GetCouponByID(ctx context.Context, id int64) (Coupon, error)
+	GetCouponBySupplier(ctx context.Context, supplierID int64) ([]Coupon, error)
+	GetCouponRedemptionForUpdate(ctx context.Context, id int64) (CouponRedemption, error)
+	GetCustomerAddressByCustomer(ctx context.Context, customerID int64) ([]CustomerAddress, error)
+	GetCustomerAddressByID(ctx context.Context, id int64) (CustomerAddress, error)
+	GetDashboardRefreshedAt(ctx context.Context) (time.Time, error)
+	GetGmvSeries(ctx context.Context, arg GetGmvSeriesParams) ([]GetGmvSeriesRow, error)
+	GetHandledOrderByCustomer(ctx context.Context, customerID int64) ([]Order, error)
+	GetHandledOrderBySupplier(ctx context.Context, supplierID int64) ([]Order, error)
+	GetInvoiceByID(ctx context.Context, id int64) (Invoice, error)
+	GetInvoiceByOrder(ctx context.Context, orderID int64) (Invoice, error)
+	GetInvoiceLineByInvoice(ctx context.Context, invoiceID int64) ([]InvoiceLine, error)
+	GetOrderAuditByOrder(ctx context.Context, orderID int64) ([]OrderAudit, error)
+	GetOrderByAddressId(ctx context.Context, addressID int64) ([]Order, error)
+	GetOrderByID(ctx context.Context, id int64) (Order, error)
+	GetOrderByIDForUpdate(ctx context.Context, id int64) (Order, error)
+	GetOrderByNumber(ctx context.Context, orderNumber string) (Order, error)
+	GetOrderFunnel(ctx context.Context, arg GetOrderFunnelParams) (GetOrderFunnelRow, error)
+	GetOrderNumberByIDs(ctx context.Context, ids []int64) ([]GetOrderNumberByIDsRow, error)
+	GetOrderStatusHistoryByOrder(ctx context.Context, orderID int64) ([]OrderStatusHistory, error)
+	// the orders are read in batches of row_limit after the last id, the
+	// export never holds more than one batch
+	GetOrdersForExport(ctx context.Context, arg GetOrdersForExportParams) ([]GetOrdersForExportRow, error)
+	GetRefundByReturn(ctx context.Context, returnID int64) ([]Refund, error)
+	// only the refunds the payment provider confirmed
+	GetRefundedAmountByOrder(ctx context.Context, orderID int64) (int64, error)
+	GetReturnItemByReturn(ctx context.Context, returnID int64) ([]ReturnItem, error)
+	GetReturnRequestByCustomer(ctx context.Context, customerID int64) ([]ReturnRequest, error)
+	GetReturnRequestByID(ctx context.Context, id int64) (ReturnRequest, error)
+	GetReturnRequestBySupplier(ctx context.Context, supplierID int64) ([]ReturnRequest, error)
+	GetReturnedQuantityByOrder(ctx context.Context, orderID int64) (int32, error)
+	GetShipmentByID(ctx context.Context, id int64) (Shipment, error)
+	GetShipmentByOrder(ctx context.Context, orderID int64) ([]Shipment, error)
+	GetShipmentItemByShipment(ctx context.Context, shipmentID int64) ([]ShipmentItem, error)
+	GetShipmentQuantityByOrder(ctx context.Context, orderID int64) (GetShipmentQuantityByOrderRow, error)
+	GetSlowestSuppliers(ctx context.Context, limit int32) ([]SupplierHandlingSummary, error)
+	GetSupplierInvoiceSetting(ctx context.Context, supplierID int64) (SupplierInvoiceSetting, error)
+	GetSupplierOrderSetting(ctx context.Context, supplierID int64) (SupplierOrderSetting, error)
+	// the sales are the orders handled, shipped or delivered, before the refunds
+	// of returns, bucketed by the day, week or month of their creation in the
+	// time zone of the supplier
+	GetSupplierSalesSeries(ctx context.Context, arg GetSupplierSalesSeriesParams) ([]GetSupplierSalesSeriesRow, error)
+	GetSupplierSalesSummary(ctx context.Context, arg GetSupplierSalesSummaryParams) (GetSupplierSalesSummaryRow, error)
+	GetSupplierTopProducts(ctx context.Context, arg GetSupplierTopProductsParams) ([]GetSupplierTopProductsRow, error)
+	GetWaitingOrderByCustomer(ctx context.Context, customerID int64) ([]Order, error)
+	GetWaitingOrderBySupplier(ctx context.Context, supplierID int64) ([]Order, error)
+	HandleOrder(ctx context.Context, id int64) (Order, error)
+	// goods on their way can't be held, a held order gets no new shipment
+	HoldOrder(ctx context.Context, arg HoldOrderParams) (Order, error)
+	LockStaleWaitingOrder(ctx context.Context, arg LockStaleWaitingOrderParams) ([]Order, error)
+	// the row stays locked until the invoice is committed, the invoices of a
+	// supplier in a year are numbered one at a time
+	NextInvoiceNumber(ctx context.Context, arg NextInvoiceNumberParams) (int32, error)
+	RedeemCoupon(ctx context.Context, id int64) (Coupon, error)
+	RefreshDashboardSummary(ctx context.Context) error
+	ReleaseCouponRedemption(ctx context.Context, id int64) (CouponRedemption, error)
+	ReleaseOrder(ctx context.Context, id int64) (Order, error)
+	// puts back a restock finished before product-service failed it, it is
+	// tried again at the retry time of its claim
+	RetryOrderRestock(ctx context.Context, arg RetryOrderRestockParams) error
+	SearchOrders(ctx context.Context, arg SearchOrdersParams) ([]Order, error)
+	SetCouponActive(ctx context.Context, arg SetCouponActiveParams) (Coupon, error)
+	// the carrier is booked after the shipment is saved, a tracking number the
+	// supplier entered meanwhile is kept
+	SetShipmentTrackingNumber(ctx context.Context, arg SetShipmentTrackingNumberParams) (Shipment, error)
+	ShipShipment(ctx context.Context, arg ShipShipmentParams) (Shipment, error)
+	// only one replica refreshes the summaries, the others skip their turn
+	TryLockDashboardRefresh(ctx context.Context) (bool, error)
+	UnredeemCoupon(ctx context.Context, id int64) error
+	UpdateCustomerAddress(ctx context.Context, arg UpdateCustomerAddressParams) (CustomerAddress, error)
+	UpdateOrderPayment(ctx context.Context, arg UpdateOrderPaymentParams) error
+	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (Order, error)
+	UpdateReturnRequestStatus(ctx context.Context, arg UpdateReturnRequestStatusParams) (ReturnRequest, error)
+	UpsertSupplierInvoiceSetting(ctx context.Context, arg UpsertSupplierInvoiceSettingParams) (SupplierInvoiceSetting, error)
+	UpsertSupplierOrderSetting(ctx context.Context, arg UpsertSupplierOrderSettingParams) (SupplierOrderSetting, error)
+}
+
+var _ Querier = (*Queries)(nil)
@@ -0,0 +1,161 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.0
+// source: restock.sql
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+const claimDueOrderRestock = `-- name: ClaimDueOrderRestock :many
+UPDATE "order"
+SET "restock_attempts" = "restock_attempts" + 1,
+    "restock_retry_at" = now() + make_interval(secs => $1::int * power(2, LEAST("restock_attempts", 6)))
+WHERE "id" IN (
+    SELECT "id" FROM "order"
+    WHERE "restock_pending" AND ("restock_retry_at" IS NULL OR "restock_retry_at" <= now())
+        AND "restock_attempts" < $2::int
+    ORDER BY "restock_retry_at" NULLS FIRST, "id"
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee
+`
+
+type ClaimDueOrderRestockParams struct {
+	RetryAfterSeconds int32
+	MaxAttempts       int32
+	BatchSize         int32
+}
+
+func (q *Queries) ClaimDueOrderRestock(ctx context.Context, arg ClaimDueOrderRestockParams) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueOrderRestock, arg.RetryAfterSeconds, arg.MaxAttempts, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomerID,
+			&i.SupplierID,
+			&i.ProductID,
+			&i.Quantity,
+			&i.Status,
+			&i.AddressID,
+			&i.CreatedAt,
+			&i.Price,
+			&i.PaymentStatus,
+			&i.PaymentID,
+			&i.Discount,
+			&i.CouponRedemptionID,
+			&i.CancelReason,
+			&i.UpdatedAt,
+			&i.HandledAt,
+			&i.CancelledAt,
+			&i.OrderNumber,
+			&i.ProductName,
+			&i.HeldAt,
+			&i.HoldReason,
+			&i.RestockPending,
+			&i.RestockRetryAt,
+			&i.RestockAttempts,
+			&i.ShippingFee,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimOrderRestock = `-- name: ClaimOrderRestock :one
+UPDATE "order"
+SET "restock_attempts" = "restock_attempts" + 1,
+    "restock_retry_at" = now() + make_interval(secs => $1::int * power(2, LEAST("restock_attempts", 6)))
+WHERE "id" = $2 AND "restock_pending" AND ("restock_retry_at" IS NULL OR "restock_retry_at" <= now())
+    AND "restock_attempts" < $3::int
+RETURNING id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee
+`
+
+type ClaimOrderRestockParams struct {
+	RetryAfterSeconds int32
+	ID                int64
+	MaxAttempts       int32
+}
+
+// the claim keeps the restock of the order until its next retry, another
+// replica tries it again when the claimer died before FinishOrderRestock.
+// Every attempt doubles the wait, up to 64 times retry_after_seconds
+func (q *Queries) ClaimOrderRestock(ctx context.Context, arg ClaimOrderRestockParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, claimOrderRestock, arg.RetryAfterSeconds, arg.ID, arg.MaxAttempts)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.Status,
+		&i.AddressID,
+		&i.CreatedAt,
+		&i.Price,
+		&i.PaymentStatus,
+		&i.PaymentID,
+		&i.Discount,
+		&i.CouponRedemptionID,
+		&i.CancelReason,
+		&i.UpdatedAt,
+		&i.HandledAt,
+		&i.CancelledAt,
+		&i.OrderNumber,
+		&i.ProductName,
+		&i.HeldAt,
+		&i.HoldReason,
+		&i.RestockPending,
+		&i.RestockRetryAt,
+		&i.RestockAttempts,
+		&i.ShippingFee,
+	)
+	return i, err
+}
+
+const finishOrderRestock = `-- name: FinishOrderRestock :exec
+UPDATE "order"
+SET "restock_pending" = false, "restock_retry_at" = NULL
+WHERE "id" = $1
+`
+
+func (q *Queries) FinishOrderRestock(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, finishOrderRestock, id)
+	return err
+}
+
+const retryOrderRestock = `-- name: RetryOrderRestock :exec
+UPDATE "order"
+SET "restock_pending" = true, "restock_retry_at" = $2
+WHERE "id" = $1
+`
+
+type RetryOrderRestockParams struct {
+	ID             int64
+	RestockRetryAt sql.NullTime
+}
+
+// puts back a restock finished before product-service failed it, it is
+// tried again at the retry time of its claim
+func (q *Queries) RetryOrderRestock(ctx context.Context, arg RetryOrderRestockParams) error {
+	_, err := q.db.ExecContext(ctx, retryOrderRestock, arg.ID, arg.RestockRetryAt)
+	return err
+}
@@ -0,0 +1,436 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.0
+// source: return.sql
+
+package repository
+
+import (
+	"context"
+)
+
+const claimDueRefund = `-- name: ClaimDueRefund :many
+UPDATE "refund"
+SET "attempts" = "attempts" + 1,
+    "retry_at" = now() + make_interval(secs => $1::int * power(2, LEAST("attempts", 6)))
+WHERE "id" IN (
+    SELECT "id" FROM "refund"
+    WHERE "status" = 'pending' AND ("retry_at" IS NULL OR "retry_at" <= now())
+        AND "attempts" < $2::int
+    ORDER BY "retry_at" NULLS FIRST, "id"
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, return_id, order_id, amount, payment_id, created_at, status, attempts, retry_at
+`
+
+type ClaimDueRefundParams struct {
+	RetryAfterSeconds int32
+	MaxAttempts       int32
+	BatchSize         int32
+}
+
+func (q *Queries) ClaimDueRefund(ctx context.Context, arg ClaimDueRefundParams) ([]Refund, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueRefund, arg.RetryAfterSeconds, arg.MaxAttempts, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Refund
+	for rows.Next() {
+		var i Refund
+		if err := rows.Scan(
+			&i.ID,
+			&i.ReturnID,
+			&i.OrderID,
+			&i.Amount,
+			&i.PaymentID,
+			&i.CreatedAt,
+			&i.Status,
+			&i.Attempts,
+			&i.RetryAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimRefund = `-- name: ClaimRefund :one
+UPDATE "refund"
+SET "attempts" = "attempts" + 1,
+    "retry_at" = now() + make_interval(secs => $1::int * power(2, LEAST("attempts", 6)))
+WHERE "id" = $2 AND "status" = 'pending' AND ("retry_at" IS NULL OR "retry_at" <= now())
+    AND "attempts" < $3::int
+RETURNING id, return_id, order_id, amount, payment_id, created_at, status, attempts, retry_at
+`
+
+type ClaimRefundParams struct {
+	RetryAfterSeconds int32
+	ID                int64
+	MaxAttempts       int32
+}
+
+// the claim keeps the refund until its next retry, another replica tries it
+// again when the claimer died before FinishRefund. Every attempt doubles the
+// wait, up to 64 times retry_after_seconds
+func (q *Queries) ClaimRefund(ctx context.Context, arg ClaimRefundParams) (Refund, error) {
+	row := q.db.QueryRowContext(ctx, claimRefund, arg.RetryAfterSeconds, arg.ID, arg.MaxAttempts)
+	var i Refund
+	err := row.Scan(
+		&i.ID,
+		&i.ReturnID,
+		&i.OrderID,
+		&i.Amount,
+		&i.PaymentID,
+		&i.CreatedAt,
+		&i.Status,
+		&i.Attempts,
+		&i.RetryAt,
+	)
+	return i, err
+}
+
+const createRefund = `-- name: CreateRefund :one
+INSERT INTO "refund" (
+    "return_id", "order_id", "amount", "payment_id", "status"
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING id, return_id, order_id, amount, payment_id, created_at, status, attempts, retry_at
+`
+
+type CreateRefundParams struct {
+	ReturnID  int64
+	OrderID   int64
+	Amount    int64
+	PaymentID string
+	Status    RefundStatusEnum
+}
+
+func (q *Queries) CreateRefund(ctx context.Context, arg CreateRefundParams) (Refund, error) {
+	row := q.db.QueryRowContext(ctx, createRefund,
+		arg.ReturnID,
+		arg.OrderID,
+		arg.Amount,
+		arg.PaymentID,
+		arg.Status,
+	)
+	var i Refund
+	err := row.Scan(
+		&i.ID,
+		&i.ReturnID,
+		&i.OrderID,
+		&i.Amount,
+		&i.PaymentID,
+		&i.CreatedAt,
+		&i.Status,
+		&i.Attempts,
+		&i.RetryAt,
+	)
+	return i, err
+}
+
+const createReturnItem = `-- name: CreateReturnItem :one
+INSERT INTO "return_item" (
+    "return_id", "order_id", "quantity"
+) VALUES (
+    $1, $2, $3
+) RETURNING id, return_id, order_id, quantity
+`
+
+type CreateReturnItemParams struct {
+	ReturnID int64
+	OrderID  int64
+	Quantity int32
+}
+
+func (q *Queries) CreateReturnItem(ctx context.Context, arg CreateReturnItemParams) (ReturnItem, error) {
+	row := q.db.QueryRowContext(ctx, createReturnItem, arg.ReturnID, arg.OrderID, arg.Quantity)
+	var i ReturnItem
+	err := row.Scan(
+		&i.ID,
+		&i.ReturnID,
+		&i.OrderID,
+		&i.Quantity,
+	)
+	return i, err
+}
+
+const createReturnRequest = `-- name: CreateReturnRequest :one
+INSERT INTO "return_request" (
+    "customer_id", "supplier_id", "reason"
+) VALUES (
+    $1, $2, $3
+) RETURNING id, customer_id, supplier_id, reason, status, note, created_at, updated_at
+`
+
+type CreateReturnRequestParams struct {
+	CustomerID int64
+	SupplierID int64
+	Reason     string
+}
+
+func (q *Queries) CreateReturnRequest(ctx context.Context, arg CreateReturnRequestParams) (ReturnRequest, error) {
+	row := q.db.QueryRowContext(ctx, createReturnRequest, arg.CustomerID, arg.SupplierID, arg.Reason)
+	var i ReturnRequest
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.Reason,
+		&i.Status,
+		&i.Note,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const finishRefund = `-- name: FinishRefund :exec
+UPDATE "refund"
+SET "status" = 'refunded', "retry_at" = NULL
+WHERE "id" = $1 AND "status" = 'pending'
+`
+
+func (q *Queries) FinishRefund(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, finishRefund, id)
+	return err
+}
+
+const getRefundByReturn = `-- name: GetRefundByReturn :many
+SELECT id, return_id, order_id, amount, payment_id, created_at, status, attempts, retry_at FROM "refund"
+WHERE "return_id" = $1
+ORDER BY "id"
+`
+
+func (q *Queries) GetRefundByReturn(ctx context.Context, returnID int64) ([]Refund, error) {
+	rows, err := q.db.QueryContext(ctx, getRefundByReturn, returnID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Refund
+	for rows.Next() {
+		var i Refund
+		if err := rows.Scan(
+			&i.ID,
+			&i.ReturnID,
+			&i.OrderID,
+			&i.Amount,
+			&i.PaymentID,
+			&i.CreatedAt,
+			&i.Status,
+			&i.Attempts,
+			&i.RetryAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRefundedAmountByOrder = `-- name: GetRefundedAmountByOrder :one
+SELECT COALESCE(SUM("amount"), 0)::bigint FROM "refund"
+WHERE "order_id" = $1 AND "status" = 'refunded'
+`
+
+// only the refunds the payment provider confirmed
+func (q *Queries) GetRefundedAmountByOrder(ctx context.Context, orderID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getRefundedAmountByOrder, orderID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const getReturnItemByReturn = `-- name: GetReturnItemByReturn :many
+SELECT id, return_id, order_id, quantity FROM "return_item"
+WHERE "return_id" = $1
+ORDER BY "id"
+`
+
+func (q *Queries) GetReturnItemByReturn(ctx context.Context, returnID int64) ([]ReturnItem, error) {
+	rows, err := q.db.QueryContext(ctx, getReturnItemByReturn, returnID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReturnItem
+	for rows.Next() {
+		var i ReturnItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.ReturnID,
+			&i.OrderID,
+			&i.Quantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReturnRequestByCustomer = `-- name: GetReturnRequestByCustomer :many
+SELECT id, customer_id, supplier_id, reason, status, note, created_at, updated_at FROM "return_request"
+WHERE "customer_id" = $1
+ORDER BY "id" DESC
+`
+
+func (q *Queries) GetReturnRequestByCustomer(ctx context.Context, customerID int64) ([]ReturnRequest, error) {
+	rows, err := q.db.QueryContext(ctx, getReturnRequestByCustomer, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReturnRequest
+	for rows.Next() {
+		var i ReturnRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomerID,
+			&i.SupplierID,
+			&i.Reason,
+			&i.Status,
+			&i.Note,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReturnRequestByID = `-- name: GetReturnRequestByID :one
+SELECT id, customer_id, supplier_id, reason, status, note, created_at, updated_at FROM "return_request"
+WHERE "id" = $1 LIMIT 1
+`
+
+func (q *Queries) GetReturnRequestByID(ctx context.Context, id int64) (ReturnRequest, error) {
+	row := q.db.QueryRowContext(ctx, getReturnRequestByID, id)
+	var i ReturnRequest
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.Reason,
+		&i.Status,
+		&i.Note,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getReturnRequestBySupplier = `-- name: GetReturnRequestBySupplier :many
+SELECT id, customer_id, supplier_id, reason, status, note, created_at, updated_at FROM "return_request"
+WHERE "supplier_id" = $1
+ORDER BY "id" DESC
+`
+
+func (q *Queries) GetReturnRequestBySupplier(ctx context.Context, supplierID int64) ([]ReturnRequest, error) {
+	rows, err := q.db.QueryContext(ctx, getReturnRequestBySupplier, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReturnRequest
+	for rows.Next() {
+		var i ReturnRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomerID,
+			&i.SupplierID,
+			&i.Reason,
+			&i.Status,
+			&i.Note,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReturnedQuantityByOrder = `-- name: GetReturnedQuantityByOrder :one
+SELECT COALESCE(SUM("return_item"."quantity"), 0)::int FROM "return_item"
+JOIN "return_request" ON "return_request"."id" = "return_item"."return_id"
+WHERE "return_item"."order_id" = $1 AND "return_request"."status" <> 'rejected'
+`
+
+func (q *Queries) GetReturnedQuantityByOrder(ctx context.Context, orderID int64) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getReturnedQuantityByOrder, orderID)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const updateReturnRequestStatus = `-- name: UpdateReturnRequestStatus :one
+UPDATE "return_request"
+SET "status" = $1, "note" = $2, "updated_at" = now()
+WHERE "id" = $3 AND "status" = $4
+RETURNING id, customer_id, supplier_id, reason, status, note, created_at, updated_at
+`
+
+type UpdateReturnRequestStatusParams struct {
+	NewStatus ReturnStatusEnum
+	Note      string
+	ID        int64
+	OldStatus ReturnStatusEnum
+}
+
+func (q *Queries) UpdateReturnRequestStatus(ctx context.Context, arg UpdateReturnRequestStatusParams) (ReturnRequest, error) {
+	row := q.db.QueryRowContext(ctx, updateReturnRequestStatus,
+		arg.NewStatus,
+		arg.Note,
+		arg.ID,
+		arg.OldStatus,
+	)
+	var i ReturnRequest
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.Reason,
+		&i.Status,
+		&i.Note,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,178 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.0
+// source: sales_report.sql
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const getSupplierSalesSeries = `-- name: GetSupplierSalesSeries :many
+SELECT (date_trunc($2::text, "created_at" AT TIME ZONE $1::text) AT TIME ZONE $1::text)::timestamptz AS "bucket_start",
+    count(*)::bigint AS "order_count",
+    coalesce(sum("quantity"), 0)::bigint AS "unit_count",
+    coalesce(sum("price" * "quantity" - "discount"), 0)::bigint AS "revenue"
+FROM "order"
+WHERE "supplier_id" = $3
+    AND "status" IN ('handled', 'shipped', 'delivered')
+    AND "created_at" >= $4 AND "created_at" < $5
+GROUP BY 1
+ORDER BY 1
+`
+
+type GetSupplierSalesSeriesParams struct {
+	TimeZone    string
+	Bucket      string
+	SupplierID  int64
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+}
+
+type GetSupplierSalesSeriesRow struct {
+	BucketStart time.Time
+	OrderCount  int64
+	UnitCount   int64
+	Revenue     int64
+}
+
+// the sales are the orders handled, shipped or delivered, before the refunds
+// of returns, bucketed by the day, week or month of their creation in the
+// time zone of the supplier
+func (q *Queries) GetSupplierSalesSeries(ctx context.Context, arg GetSupplierSalesSeriesParams) ([]GetSupplierSalesSeriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSupplierSalesSeries,
+		arg.TimeZone,
+		arg.Bucket,
+		arg.SupplierID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSupplierSalesSeriesRow
+	for rows.Next() {
+		var i GetSupplierSalesSeriesRow
+		if err := rows.Scan(
+			&i.BucketStart,
+			&i.OrderCount,
+			&i.UnitCount,
+			&i.Revenue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSupplierSalesSummary = `-- name: GetSupplierSalesSummary :one
+SELECT count(*)::bigint AS "order_count",
+    count(*) FILTER (WHERE "status" = 'cancel')::bigint AS "cancelled_count",
+    coalesce(sum("quantity") FILTER (WHERE "status" IN ('handled', 'shipped', 'delivered')), 0)::bigint AS "unit_count",
+    coalesce(sum("price" * "quantity" - "discount") FILTER (WHERE "status" IN ('handled', 'shipped', 'delivered')), 0)::bigint AS "revenue",
+    coalesce(extract(epoch FROM avg("handled_at" - "created_at")), 0)::float8 AS "average_handling_seconds"
+FROM "order"
+WHERE "supplier_id" = $1
+    AND "created_at" >= $2 AND "created_at" < $3
+`
+
+type GetSupplierSalesSummaryParams struct {
+	SupplierID  int64
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+}
+
+type GetSupplierSalesSummaryRow struct {
+	OrderCount             int64
+	CancelledCount         int64
+	UnitCount              int64
+	Revenue                int64
+	AverageHandlingSeconds float64
+}
+
+func (q *Queries) GetSupplierSalesSummary(ctx context.Context, arg GetSupplierSalesSummaryParams) (GetSupplierSalesSummaryRow, error) {
+	row := q.db.QueryRowContext(ctx, getSupplierSalesSummary, arg.SupplierID, arg.CreatedFrom, arg.CreatedTo)
+	var i GetSupplierSalesSummaryRow
+	err := row.Scan(
+		&i.OrderCount,
+		&i.CancelledCount,
+		&i.UnitCount,
+		&i.Revenue,
+		&i.AverageHandlingSeconds,
+	)
+	return i, err
+}
+
+const getSupplierTopProducts = `-- name: GetSupplierTopProducts :many
+SELECT "product_id",
+    max("product_name")::text AS "product_name",
+    count(*)::bigint AS "order_count",
+    coalesce(sum("quantity"), 0)::bigint AS "unit_count",
+    coalesce(sum("price" * "quantity" - "discount"), 0)::bigint AS "revenue"
+FROM "order"
+WHERE "supplier_id" = $1
+    AND "status" IN ('handled', 'shipped', 'delivered')
+    AND "created_at" >= $2 AND "created_at" < $3
+GROUP BY "product_id"
+ORDER BY "revenue" DESC, "unit_count" DESC, "product_id"
+LIMIT $4
+`
+
+type GetSupplierTopProductsParams struct {
+	SupplierID  int64
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	RowLimit    int32
+}
+
+type GetSupplierTopProductsRow struct {
+	ProductID   int64
+	ProductName string
+	OrderCount  int64
+	UnitCount   int64
+	Revenue     int64
+}
+
+func (q *Queries) GetSupplierTopProducts(ctx context.Context, arg GetSupplierTopProductsParams) ([]GetSupplierTopProductsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSupplierTopProducts,
+		arg.SupplierID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSupplierTopProductsRow
+	for rows.Next() {
+		var i GetSupplierTopProductsRow
+		if err := rows.Scan(
+			&i.ProductID,
+			&i.ProductName,
+			&i.OrderCount,
+			&i.UnitCount,
+			&i.Revenue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
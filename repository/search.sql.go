@@ -0,0 +1,98 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.0
+// source: search.sql
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+const searchOrders = `-- name: SearchOrders :many
+SELECT "order".id, "order".customer_id, "order".supplier_id, "order".product_id, "order".quantity, "order".status, "order".address_id, "order".created_at, "order".price, "order".payment_status, "order".payment_id, "order".discount, "order".coupon_redemption_id, "order".cancel_reason, "order".updated_at, "order".handled_at, "order".cancelled_at, "order".order_number, "order".product_name, "order".held_at, "order".hold_reason, "order".restock_pending, "order".restock_retry_at, "order".restock_attempts, "order".shipping_fee FROM "order"
+JOIN "order_search" ON "order_search"."order_id" = "order"."id"
+WHERE ($1::text IS NULL OR "order_search"."document" @@ to_tsquery('simple', order_search_normalize($1::text)))
+    AND ($2::text IS NULL OR "order"."order_number" = $2::text)
+    AND ($3::bigint IS NULL OR "order"."supplier_id" = $3::bigint)
+    AND ($4::bigint IS NULL OR "order"."customer_id" = $4::bigint)
+    AND (cardinality($5::text[]) = 0 OR "order"."status"::text = ANY($5::text[]))
+    AND ($6::timestamptz IS NULL OR "order"."created_at" >= $6::timestamptz)
+    AND ($7::timestamptz IS NULL OR "order"."created_at" < $7::timestamptz)
+ORDER BY "order"."created_at" DESC, "order"."id" DESC
+LIMIT $9 OFFSET $8
+`
+
+type SearchOrdersParams struct {
+	Query       sql.NullString
+	OrderNumber sql.NullString
+	SupplierID  sql.NullInt64
+	CustomerID  sql.NullInt64
+	ListStatus  []string
+	CreatedFrom sql.NullTime
+	CreatedTo   sql.NullTime
+	RowOffset   int32
+	RowLimit    int32
+}
+
+func (q *Queries) SearchOrders(ctx context.Context, arg SearchOrdersParams) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, searchOrders,
+		arg.Query,
+		arg.OrderNumber,
+		arg.SupplierID,
+		arg.CustomerID,
+		pq.Array(arg.ListStatus),
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomerID,
+			&i.SupplierID,
+			&i.ProductID,
+			&i.Quantity,
+			&i.Status,
+			&i.AddressID,
+			&i.CreatedAt,
+			&i.Price,
+			&i.PaymentStatus,
+			&i.PaymentID,
+			&i.Discount,
+			&i.CouponRedemptionID,
+			&i.CancelReason,
+			&i.UpdatedAt,
+			&i.HandledAt,
+			&i.CancelledAt,
+			&i.OrderNumber,
+			&i.ProductName,
+			&i.HeldAt,
+			&i.HoldReason,
+			&i.RestockPending,
+			&i.RestockRetryAt,
+			&i.RestockAttempts,
+			&i.ShippingFee,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,264 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.0
+// source: shipment.sql
+
+package repository
+
+import (
+	"context"
+)
+
+const createShipment = `-- name: CreateShipment :one
+INSERT INTO "shipment" (
+    "supplier_id", "carrier", "tracking_number"
+) VALUES (
+    $1, $2, $3
+) RETURNING id, supplier_id, carrier, tracking_number, status, shipped_at, delivered_at, created_at
+`
+
+type CreateShipmentParams struct {
+	SupplierID     int64
+	Carrier        string
+	TrackingNumber string
+}
+
+func (q *Queries) CreateShipment(ctx context.Context, arg CreateShipmentParams) (Shipment, error) {
+	row := q.db.QueryRowContext(ctx, createShipment, arg.SupplierID, arg.Carrier, arg.TrackingNumber)
+	var i Shipment
+	err := row.Scan(
+		&i.ID,
+		&i.SupplierID,
+		&i.Carrier,
+		&i.TrackingNumber,
+		&i.Status,
+		&i.ShippedAt,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createShipmentItem = `-- name: CreateShipmentItem :one
+INSERT INTO "shipment_item" (
+    "shipment_id", "order_id", "quantity"
+) VALUES (
+    $1, $2, $3
+) RETURNING id, shipment_id, order_id, quantity
+`
+
+type CreateShipmentItemParams struct {
+	ShipmentID int64
+	OrderID    int64
+	Quantity   int32
+}
+
+func (q *Queries) CreateShipmentItem(ctx context.Context, arg CreateShipmentItemParams) (ShipmentItem, error) {
+	row := q.db.QueryRowContext(ctx, createShipmentItem, arg.ShipmentID, arg.OrderID, arg.Quantity)
+	var i ShipmentItem
+	err := row.Scan(
+		&i.ID,
+		&i.ShipmentID,
+		&i.OrderID,
+		&i.Quantity,
+	)
+	return i, err
+}
+
+const deliverShipment = `-- name: DeliverShipment :one
+UPDATE "shipment"
+SET "status" = 'delivered', "delivered_at" = now()
+WHERE "id" = $1 AND "status" = 'shipped'
+RETURNING id, supplier_id, carrier, tracking_number, status, shipped_at, delivered_at, created_at
+`
+
+func (q *Queries) DeliverShipment(ctx context.Context, id int64) (Shipment, error) {
+	row := q.db.QueryRowContext(ctx, deliverShipment, id)
+	var i Shipment
+	err := row.Scan(
+		&i.ID,
+		&i.SupplierID,
+		&i.Carrier,
+		&i.TrackingNumber,
+		&i.Status,
+		&i.ShippedAt,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getShipmentByID = `-- name: GetShipmentByID :one
+SELECT id, supplier_id, carrier, tracking_number, status, shipped_at, delivered_at, created_at FROM "shipment"
+WHERE "id" = $1 LIMIT 1
+`
+
+func (q *Queries) GetShipmentByID(ctx context.Context, id int64) (Shipment, error) {
+	row := q.db.QueryRowContext(ctx, getShipmentByID, id)
+	var i Shipment
+	err := row.Scan(
+		&i.ID,
+		&i.SupplierID,
+		&i.Carrier,
+		&i.TrackingNumber,
+		&i.Status,
+		&i.ShippedAt,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getShipmentByOrder = `-- name: GetShipmentByOrder :many
+SELECT DISTINCT shipment.id, shipment.supplier_id, shipment.carrier, shipment.tracking_number, shipment.status, shipment.shipped_at, shipment.delivered_at, shipment.created_at.* FROM "shipment"
+JOIN "shipment_item" ON "shipment_item"."shipment_id" = "shipment"."id"
+WHERE "shipment_item"."order_id" = $1
+ORDER BY "shipment"."id"
+`
+
+func (q *Queries) GetShipmentByOrder(ctx context.Context, orderID int64) ([]Shipment, error) {
+	rows, err := q.db.QueryContext(ctx, getShipmentByOrder, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Shipment
+	for rows.Next() {
+		var i Shipment
+		if err := rows.Scan(
+			&i.ID,
+			&i.SupplierID,
+			&i.Carrier,
+			&i.TrackingNumber,
+			&i.Status,
+			&i.ShippedAt,
+			&i.DeliveredAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getShipmentItemByShipment = `-- name: GetShipmentItemByShipment :many
+SELECT id, shipment_id, order_id, quantity FROM "shipment_item"
+WHERE "shipment_id" = $1
+`
+
+func (q *Queries) GetShipmentItemByShipment(ctx context.Context, shipmentID int64) ([]ShipmentItem, error) {
+	rows, err := q.db.QueryContext(ctx, getShipmentItemByShipment, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShipmentItem
+	for rows.Next() {
+		var i ShipmentItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.ShipmentID,
+			&i.OrderID,
+			&i.Quantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getShipmentQuantityByOrder = `-- name: GetShipmentQuantityByOrder :one
+SELECT
+    COALESCE(SUM("shipment_item"."quantity"), 0)::int AS allocated,
+    COALESCE(SUM("shipment_item"."quantity") FILTER (WHERE "shipment"."status" IN ('shipped', 'delivered')), 0)::int AS shipped,
+    COALESCE(SUM("shipment_item"."quantity") FILTER (WHERE "shipment"."status" = 'delivered'), 0)::int AS delivered
+FROM "shipment_item"
+JOIN "shipment" ON "shipment"."id" = "shipment_item"."shipment_id"
+WHERE "shipment_item"."order_id" = $1
+`
+
+type GetShipmentQuantityByOrderRow struct {
+	Allocated int32
+	Shipped   int32
+	Delivered int32
+}
+
+func (q *Queries) GetShipmentQuantityByOrder(ctx context.Context, orderID int64) (GetShipmentQuantityByOrderRow, error) {
+	row := q.db.QueryRowContext(ctx, getShipmentQuantityByOrder, orderID)
+	var i GetShipmentQuantityByOrderRow
+	err := row.Scan(&i.Allocated, &i.Shipped, &i.Delivered)
+	return i, err
+}
+
+const setShipmentTrackingNumber = `-- name: SetShipmentTrackingNumber :one
+UPDATE "shipment"
+SET "tracking_number" = $2
+WHERE "id" = $1 AND "tracking_number" = ''
+RETURNING id, supplier_id, carrier, tracking_number, status, shipped_at, delivered_at, created_at
+`
+
+type SetShipmentTrackingNumberParams struct {
+	ID             int64
+	TrackingNumber string
+}
+
+// the carrier is booked after the shipment is saved, a tracking number the
+// supplier entered meanwhile is kept
+func (q *Queries) SetShipmentTrackingNumber(ctx context.Context, arg SetShipmentTrackingNumberParams) (Shipment, error) {
+	row := q.db.QueryRowContext(ctx, setShipmentTrackingNumber, arg.ID, arg.TrackingNumber)
+	var i Shipment
+	err := row.Scan(
+		&i.ID,
+		&i.SupplierID,
+		&i.Carrier,
+		&i.TrackingNumber,
+		&i.Status,
+		&i.ShippedAt,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const shipShipment = `-- name: ShipShipment :one
+UPDATE "shipment"
+SET "carrier" = $2, "tracking_number" = $3, "status" = 'shipped', "shipped_at" = COALESCE("shipped_at", now())
+WHERE "id" = $1 AND "status" IN ('pending', 'shipped')
+RETURNING id, supplier_id, carrier, tracking_number, status, shipped_at, delivered_at, created_at
+`
+
+type ShipShipmentParams struct {
+	ID             int64
+	Carrier        string
+	TrackingNumber string
+}
+
+func (q *Queries) ShipShipment(ctx context.Context, arg ShipShipmentParams) (Shipment, error) {
+	row := q.db.QueryRowContext(ctx, shipShipment, arg.ID, arg.Carrier, arg.TrackingNumber)
+	var i Shipment
+	err := row.Scan(
+		&i.ID,
+		&i.SupplierID,
+		&i.Carrier,
+		&i.TrackingNumber,
+		&i.Status,
+		&i.ShippedAt,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
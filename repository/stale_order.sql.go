@@ -0,0 +1,154 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.0
+// source: stale_order.sql
+
+package repository
+
+import (
+	"context"
+)
+
+const cancelWaitingOrder = `-- name: CancelWaitingOrder :one
+UPDATE "order"
+SET "status" = 'cancel', "cancel_reason" = $2, "restock_pending" = true
+WHERE "id" = $1 AND "status" = 'waiting'
+RETURNING id, customer_id, supplier_id, product_id, quantity, status, address_id, created_at, price, payment_status, payment_id, discount, coupon_redemption_id, cancel_reason, updated_at, handled_at, cancelled_at, order_number, product_name, held_at, hold_reason, restock_pending, restock_retry_at, restock_attempts, shipping_fee
+`
+
+type CancelWaitingOrderParams struct {
+	ID           int64
+	CancelReason string
+}
+
+func (q *Queries) CancelWaitingOrder(ctx context.Context, arg CancelWaitingOrderParams) (Order, error) {
+	row := q.db.QueryRowContext(ctx, cancelWaitingOrder, arg.ID, arg.CancelReason)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerID,
+		&i.SupplierID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.Status,
+		&i.AddressID,
+		&i.CreatedAt,
+		&i.Price,
+		&i.PaymentStatus,
+		&i.PaymentID,
+		&i.Discount,
+		&i.CouponRedemptionID,
+		&i.CancelReason,
+		&i.UpdatedAt,
+		&i.HandledAt,
+		&i.CancelledAt,
+		&i.OrderNumber,
+		&i.ProductName,
+		&i.HeldAt,
+		&i.HoldReason,
+		&i.RestockPending,
+		&i.RestockRetryAt,
+		&i.RestockAttempts,
+		&i.ShippingFee,
+	)
+	return i, err
+}
+
+const getSupplierOrderSetting = `-- name: GetSupplierOrderSetting :one
+SELECT supplier_id, handle_deadline_hours, updated_at FROM "supplier_order_setting"
+WHERE "supplier_id" = $1 LIMIT 1
+`
+
+func (q *Queries) GetSupplierOrderSetting(ctx context.Context, supplierID int64) (SupplierOrderSetting, error) {
+	row := q.db.QueryRowContext(ctx, getSupplierOrderSetting, supplierID)
+	var i SupplierOrderSetting
+	err := row.Scan(&i.SupplierID, &i.HandleDeadlineHours, &i.UpdatedAt)
+	return i, err
+}
+
+const lockStaleWaitingOrder = `-- name: LockStaleWaitingOrder :many
+SELECT "order".id, "order".customer_id, "order".supplier_id, "order".product_id, "order".quantity, "order".status, "order".address_id, "order".created_at, "order".price, "order".payment_status, "order".payment_id, "order".discount, "order".coupon_redemption_id, "order".cancel_reason, "order".updated_at, "order".handled_at, "order".cancelled_at, "order".order_number, "order".product_name, "order".held_at, "order".hold_reason, "order".restock_pending, "order".restock_retry_at, "order".restock_attempts, "order".shipping_fee FROM "order"
+LEFT JOIN "supplier_order_setting" ON "supplier_order_setting"."supplier_id" = "order"."supplier_id"
+WHERE "order"."status" = 'waiting' AND "order"."held_at" IS NULL AND "order"."id" > $1
+AND "order"."created_at" < now() - make_interval(hours => COALESCE("supplier_order_setting"."handle_deadline_hours", $2::int))
+ORDER BY "order"."id"
+LIMIT $3
+FOR UPDATE OF "order" SKIP LOCKED
+`
+
+type LockStaleWaitingOrderParams struct {
+	AfterID              int64
+	DefaultDeadlineHours int32
+	BatchSize            int32
+}
+
+func (q *Queries) LockStaleWaitingOrder(ctx context.Context, arg LockStaleWaitingOrderParams) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, lockStaleWaitingOrder, arg.AfterID, arg.DefaultDeadlineHours, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomerID,
+			&i.SupplierID,
+			&i.ProductID,
+			&i.Quantity,
+			&i.Status,
+			&i.AddressID,
+			&i.CreatedAt,
+			&i.Price,
+			&i.PaymentStatus,
+			&i.PaymentID,
+			&i.Discount,
+			&i.CouponRedemptionID,
+			&i.CancelReason,
+			&i.UpdatedAt,
+			&i.HandledAt,
+			&i.CancelledAt,
+			&i.OrderNumber,
+			&i.ProductName,
+			&i.HeldAt,
+			&i.HoldReason,
+			&i.RestockPending,
+			&i.RestockRetryAt,
+			&i.RestockAttempts,
+			&i.ShippingFee,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertSupplierOrderSetting = `-- name: UpsertSupplierOrderSetting :one
+INSERT INTO "supplier_order_setting" (
+    "supplier_id", "handle_deadline_hours"
+) VALUES (
+    $1, $2
+) ON CONFLICT ("supplier_id") DO UPDATE
+SET "handle_deadline_hours" = EXCLUDED."handle_deadline_hours", "updated_at" = now()
+RETURNING supplier_id, handle_deadline_hours, updated_at
+`
+
+type UpsertSupplierOrderSettingParams struct {
+	SupplierID          int64
+	HandleDeadlineHours int32
+}
+
+func (q *Queries) UpsertSupplierOrderSetting(ctx context.Context, arg UpsertSupplierOrderSettingParams) (SupplierOrderSetting, error) {
+	row := q.db.QueryRowContext(ctx, upsertSupplierOrderSetting, arg.SupplierID, arg.HandleDeadlineHours)
+	var i SupplierOrderSetting
+	err := row.Scan(&i.SupplierID, &i.HandleDeadlineHours, &i.UpdatedAt)
+	return i, err
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+const (
+	// how long a claimed restock is kept before it is tried again, the wait
+	// doubles with every failed attempt
+	restockRetryAfter = 5 * time.Minute
+	// a restock still failing after that many attempts is left to an admin
+	restockMaxAttempts = 10
+	restockBatchSize   = 50
+)
+
+// restockOrder gives the inventory of a cancelled order back to
+// product-service. It is only called once the cancel is committed, so the
+// inventory comes back once, for an order that really is cancelled. When
+// product-service fails the order keeps owing it and restockPendingOrders
+// tries again later.
+func (srv orderService) restockOrder(ctx context.Context, order repository.Order) error {
+	order, err := srv.orderStore.ClaimOrderRestock(ctx, repository.ClaimOrderRestockParams{
+		ID:                order.ID,
+		RetryAfterSeconds: int32(restockRetryAfter / time.Second),
+		MaxAttempts:       restockMaxAttempts,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// restocked already, or being restocked by someone else
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return srv.incOrderInventory(ctx, order)
+}
+
+// restockPendingOrders retries one batch of the restocks that failed, or
+// that were left by a replica that stopped, and returns how many were done.
+// The restocks that failed the longest ago come first, one that keeps failing
+// waits longer each time so it can't hold back the others.
+func (srv orderService) restockPendingOrders(ctx context.Context) (int, error) {
+	listOrder, err := srv.orderStore.ClaimDueOrderRestock(ctx, repository.ClaimDueOrderRestockParams{
+		RetryAfterSeconds: int32(restockRetryAfter / time.Second),
+		MaxAttempts:       restockMaxAttempts,
+		BatchSize:         restockBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, order := range listOrder {
+		if err := srv.incOrderInventory(ctx, order); err != nil {
+			if order.RestockAttempts >= restockMaxAttempts {
+				log.Println("can't restock order, given up after attempts: ", order.ID, order.RestockAttempts, err)
+			} else {
+				log.Println("can't restock order, it is tried again later: ", order.ID, err)
+			}
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+// incOrderInventory finishes the claimed restock before product-service is
+// called, so an inventory given back is never given back again because the
+// order couldn't be saved after it. When product-service fails the restock is
+// put back and tried again at the retry time of the claim.
+func (srv orderService) incOrderInventory(ctx context.Context, order repository.Order) error {
+	if err := srv.orderStore.FinishOrderRestock(ctx, order.ID); err != nil {
+		return err
+	}
+	_, err := srv.productClient.IncInventory(ctx, &pb.IncInventoryRequest{
+		ProductId: order.ProductID,
+		Count:     order.Quantity,
+	})
+	if err == nil {
+		return nil
+	}
+	retryErr := srv.orderStore.RetryOrderRestock(ctx, repository.RetryOrderRestockParams{
+		ID:             order.ID,
+		RestockRetryAt: order.RestockRetryAt,
+	})
+	if retryErr != nil {
+		log.Println("can't put back the restock of order, it is lost: ", order.ID, retryErr)
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+func TestRestockRetry(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryOrderStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	srv := newTestService(t, store)
+
+	// product-service doesn't know product 10, its inventory can't come back
+	order := testOrder(t, store, nil)
+	if _, err := srv.DeleteOrder(userContext("customer"), &pb.DeleteOrderRequest{OrderId: order.ID}); err != nil {
+		t.Fatalf("the cancel must not wait for the inventory: %v", err)
+	}
+	found, err := store.GetOrderByID(ctx, order.ID)
+	if err != nil || !found.RestockPending || !hasStatus(found.Status, repository.OrderStatusEnumCancel) {
+		t.Fatalf("the cancelled order is %+v, %v", found, err)
+	}
+
+	// it isn't tried again before its retry time
+	if n, err := srv.restockPendingOrders(ctx); err != nil || n != 0 {
+		t.Fatalf("restockPendingOrders = %d, %v", n, err)
+	}
+
+	restocked := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.ProductID = 1 })
+	if _, err := store.CancelOrder(ctx, restocked.ID); err != nil {
+		t.Fatal(err)
+	}
+	before := inventoryOf(t, srv, 1)
+	now = now.Add(restockRetryAfter)
+	if n, err := srv.restockPendingOrders(ctx); err != nil || n != 1 {
+		t.Fatalf("restockPendingOrders = %d, %v, want 1", n, err)
+	}
+	if got := inventoryOf(t, srv, 1) - before; got != int64(restocked.Quantity) {
+		t.Fatalf("inventory came back %d, want %d", got, restocked.Quantity)
+	}
+	found, err = store.GetOrderByID(ctx, restocked.ID)
+	if err != nil || found.RestockPending {
+		t.Fatalf("the restocked order is %+v, %v", found, err)
+	}
+	if n, err := srv.restockPendingOrders(ctx); err != nil || n != 0 {
+		t.Fatalf("restockPendingOrders twice = %d, %v", n, err)
+	}
+}
+
+func TestRestockBackoff(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryOrderStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	srv := newTestService(t, store)
+
+	// product-service doesn't know product 10, its restock keeps failing
+	failing := testOrder(t, store, nil)
+	if _, err := srv.DeleteOrder(userContext("customer"), &pb.DeleteOrderRequest{OrderId: failing.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	for attempt := 2; attempt <= restockMaxAttempts; attempt++ {
+		// a restock cancelled later is not held back by the failing one
+		order := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.ProductID = 1 })
+		if _, err := store.CancelOrder(ctx, order.ID); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := srv.restockPendingOrders(ctx); err != nil || n != 1 {
+			t.Fatalf("attempt %d: restockPendingOrders = %d, %v, want 1", attempt, n, err)
+		}
+
+		found, err := store.GetOrderByID(ctx, failing.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wait := found.RestockRetryAt.Time.Sub(now)
+		if found.RestockAttempts != int32(attempt-1) {
+			t.Fatalf("attempt %d: %d attempts recorded", attempt, found.RestockAttempts)
+		}
+		if wantWait := restockRetryAfter << minInt(attempt-2, 6); wait != wantWait {
+			t.Fatalf("attempt %d: retried after %v, want %v", attempt, wait, wantWait)
+		}
+		now = found.RestockRetryAt.Time
+	}
+
+	// the last attempt fails too, the restock is given up
+	if _, err := srv.restockPendingOrders(ctx); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(100 * restockRetryAfter << 6)
+	listDue, err := store.ClaimDueOrderRestock(ctx, repository.ClaimDueOrderRestockParams{
+		RetryAfterSeconds: 1,
+		MaxAttempts:       restockMaxAttempts,
+		BatchSize:         10,
+	})
+	if err != nil || len(listDue) != 0 {
+		t.Fatalf("a given up restock is claimed: %v, %v", listDue, err)
+	}
+	found, err := store.GetOrderByID(ctx, failing.ID)
+	if err != nil || !found.RestockPending || found.RestockAttempts != restockMaxAttempts {
+		t.Fatalf("the given up order is %+v, %v", found, err)
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// failingFinishStore can't save the end of a restock while failFinish is set.
+type failingFinishStore struct {
+	OrderStore
+	failFinish bool
+}
+
+func (s *failingFinishStore) FinishOrderRestock(ctx context.Context, id int64) error {
+	if s.failFinish {
+		return errors.New("connection reset")
+	}
+	return s.OrderStore.FinishOrderRestock(ctx, id)
+}
+
+// the inventory comes back once even when the order can't be saved
+func TestRestockFinishFailure(t *testing.T) {
+	ctx := context.Background()
+	memory := newMemoryOrderStore()
+	now := time.Now()
+	memory.now = func() time.Time { return now }
+	store := &failingFinishStore{OrderStore: memory, failFinish: true}
+	srv := newTestService(t, store)
+
+	order := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.ProductID = 1 })
+	before := inventoryOf(t, srv, 1)
+	if _, err := srv.DeleteOrder(userContext("customer"), &pb.DeleteOrderRequest{OrderId: order.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if got := inventoryOf(t, srv, 1) - before; got != 0 {
+		t.Fatalf("inventory came back %d before the restock was saved", got)
+	}
+
+	store.failFinish = false
+	now = now.Add(restockRetryAfter)
+	if n, err := srv.restockPendingOrders(ctx); err != nil || n != 1 {
+		t.Fatalf("restockPendingOrders = %d, %v, want 1", n, err)
+	}
+	now = now.Add(100 * restockRetryAfter)
+	if n, err := srv.restockPendingOrders(ctx); err != nil || n != 0 {
+		t.Fatalf("restockPendingOrders twice = %d, %v", n, err)
+	}
+	if got := inventoryOf(t, srv, 1) - before; got != int64(order.Quantity) {
+		t.Fatalf("inventory came back %d, want %d", got, order.Quantity)
+	}
+}
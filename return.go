@@ -0,0 +1,446 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/itimofeev/go-saga"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (srv orderService) RequestReturn(ctx context.Context, req *pb.RequestReturnRequest) (*pb.OrderReturn, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	customerID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	if req.GetReason() == "" || utf8.RuneCountInString(req.GetReason()) > 512 {
+		return nil, errors.New("Vui lòng nhập lý do trả hàng")
+	}
+	if len(req.GetListItem()) == 0 {
+		return nil, errors.New("Vui lòng chọn sản phẩm cần trả")
+	}
+
+	requested := make(map[int64]int32)
+	for _, item := range req.GetListItem() {
+		if item.GetQuantity() <= 0 {
+			return nil, errors.New("Số lượng trả không hợp lệ")
+		}
+		requested[item.GetOrderId()] += item.GetQuantity()
+	}
+
+	var orderReturn repository.ReturnRequest
+	err = srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		// the lines must be bought by the caller from a single supplier, and
+		// can't be returned more than what was ordered. The orders stay locked
+		// until the return is saved so two requests can't both take the last
+		// items, they are locked in id order so they can't deadlock
+		listOrderID := make([]int64, 0, len(requested))
+		for orderID := range requested {
+			listOrderID = append(listOrderID, orderID)
+		}
+		sort.Slice(listOrderID, func(i, j int) bool { return listOrderID[i] < listOrderID[j] })
+		var supplierID int64
+		for _, orderID := range listOrderID {
+			quantity := requested[orderID]
+			order, err := qtx.GetOrderByIDForUpdate(ctx, orderID)
+			if err != nil {
+				log.Println(err)
+				return errors.New("Không tìm thấy đơn hàng")
+			}
+			if order.CustomerID != customerID {
+				return errors.New("Yêu cầu trả hàng không thành công, unauthorization")
+			}
+			switch order.Status.OrderStatusEnum {
+			// an order which isn't shipped yet is cancelled instead
+			case repository.OrderStatusEnumShipped, repository.OrderStatusEnumDelivered:
+			default:
+				return errors.New("Chỉ có thể trả đơn hàng đã được giao")
+			}
+			if supplierID != 0 && supplierID != order.SupplierID {
+				return errors.New("Chỉ có thể trả hàng của cùng một cửa hàng trong một yêu cầu")
+			}
+			supplierID = order.SupplierID
+
+			returned, err := qtx.GetReturnedQuantityByOrder(ctx, orderID)
+			if err != nil {
+				log.Println(err)
+				return errors.New("Yêu cầu trả hàng không thành công")
+			}
+			if returned+quantity > order.Quantity {
+				return errors.New("Số lượng trả vượt quá số lượng đã mua")
+			}
+		}
+
+		var err error
+		orderReturn, err = qtx.CreateReturnRequest(ctx, repository.CreateReturnRequestParams{
+			CustomerID: customerID,
+			SupplierID: supplierID,
+			Reason:     req.GetReason(),
+		})
+		if err != nil {
+			log.Println(err)
+			return errors.New("Yêu cầu trả hàng không thành công")
+		}
+		for _, item := range req.GetListItem() {
+			_, err := qtx.CreateReturnItem(ctx, repository.CreateReturnItemParams{
+				ReturnID: orderReturn.ID,
+				OrderID:  item.GetOrderId(),
+				Quantity: item.GetQuantity(),
+			})
+			if err != nil {
+				log.Println(err)
+				return errors.New("Yêu cầu trả hàng không thành công")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return srv.getPbReturn(ctx, orderReturn)
+}
+
+func (srv orderService) ApproveReturn(ctx context.Context, req *pb.UpdateReturnRequest) (*pb.OrderReturn, error) {
+	orderReturn, err := srv.getSupplierReturn(ctx, req.GetReturnId())
+	if err != nil {
+		return nil, err
+	}
+
+	orderReturn, err = srv.orderStore.UpdateReturnRequestStatus(ctx, repository.UpdateReturnRequestStatusParams{
+		ID:        orderReturn.ID,
+		OldStatus: repository.ReturnStatusEnumRequested,
+		NewStatus: repository.ReturnStatusEnumApproved,
+		Note:      req.GetNote(),
+	})
+	if err != nil {
+		return nil, returnTransitionError(err)
+	}
+
+	return srv.getPbReturn(ctx, orderReturn)
+}
+
+func (srv orderService) RejectReturn(ctx context.Context, req *pb.UpdateReturnRequest) (*pb.OrderReturn, error) {
+	if req.GetNote() == "" {
+		return nil, errors.New("Vui lòng nhập lý do từ chối")
+	}
+	orderReturn, err := srv.getSupplierReturn(ctx, req.GetReturnId())
+	if err != nil {
+		return nil, err
+	}
+
+	orderReturn, err = srv.orderStore.UpdateReturnRequestStatus(ctx, repository.UpdateReturnRequestStatusParams{
+		ID:        orderReturn.ID,
+		OldStatus: repository.ReturnStatusEnumRequested,
+		NewStatus: repository.ReturnStatusEnumRejected,
+		Note:      req.GetNote(),
+	})
+	if err != nil {
+		return nil, returnTransitionError(err)
+	}
+
+	return srv.getPbReturn(ctx, orderReturn)
+}
+
+func (srv orderService) ReceiveReturn(ctx context.Context, req *pb.UpdateReturnRequest) (*pb.OrderReturn, error) {
+	orderReturn, err := srv.getSupplierReturn(ctx, req.GetReturnId())
+	if err != nil {
+		return nil, err
+	}
+	listItem, err := srv.orderStore.GetReturnItemByReturn(ctx, orderReturn.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	prevNote, note := orderReturn.Note, orderReturn.Note
+	if req.GetNote() != "" {
+		note = req.GetNote()
+	}
+
+	returnSaga := saga.NewSaga("return-saga")
+	var sagaStore = saga.New()
+
+	returnSaga.AddStep(&saga.Step{
+		Name: "mark return received",
+		Func: func(ctx context.Context) error {
+			orderReturn, err = srv.orderStore.UpdateReturnRequestStatus(ctx, repository.UpdateReturnRequestStatusParams{
+				ID:        orderReturn.ID,
+				OldStatus: repository.ReturnStatusEnumApproved,
+				NewStatus: repository.ReturnStatusEnumReceived,
+				Note:      note,
+			})
+			return err
+		},
+		CompensateFunc: func(ctx context.Context) error {
+			_, err := srv.orderStore.UpdateReturnRequestStatus(ctx, repository.UpdateReturnRequestStatusParams{
+				ID:        orderReturn.ID,
+				OldStatus: repository.ReturnStatusEnumReceived,
+				NewStatus: repository.ReturnStatusEnumApproved,
+				Note:      prevNote,
+			})
+			return err
+		},
+	})
+
+	// the goods are back in the warehouse, put them on sale again
+	for i, item := range listItem {
+		order, err := srv.orderStore.GetOrderByID(ctx, item.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		quantity := item.Quantity
+		returnSaga.AddStep(&saga.Step{
+			Name: fmt.Sprintf("Restock inventory: %d", i),
+			Func: func(ctx context.Context) error {
+				_, err := srv.productClient.IncInventory(ctx, &pb.IncInventoryRequest{
+					ProductId: order.ProductID,
+					Count:     quantity,
+				})
+				return err
+			},
+			CompensateFunc: func(ctx context.Context) error {
+				_, err := srv.productClient.DescInventory(ctx, &pb.DescInventoryRequest{
+					ProductId: order.ProductID,
+					Count:     quantity,
+				})
+				return err
+			},
+		})
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+	coordinator := saga.NewCoordinator(ctx, ctx, returnSaga, sagaStore)
+	result := coordinator.Play()
+	if result.ExecutionError != nil {
+		log.Println("saga error: ", result.ExecutionError)
+		return nil, returnTransitionError(result.ExecutionError)
+	}
+
+	return srv.getPbReturn(ctx, orderReturn)
+}
+
+func (srv orderService) RefundReturn(ctx context.Context, req *pb.UpdateReturnRequest) (*pb.OrderReturn, error) {
+	orderReturn, err := srv.getSupplierReturn(ctx, req.GetReturnId())
+	if err != nil {
+		return nil, err
+	}
+
+	note := orderReturn.Note
+	if req.GetNote() != "" {
+		note = req.GetNote()
+	}
+
+	var listRefund []repository.Refund
+	err = srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		orderReturn, err = qtx.UpdateReturnRequestStatus(ctx, repository.UpdateReturnRequestStatusParams{
+			ID:        orderReturn.ID,
+			OldStatus: repository.ReturnStatusEnumReceived,
+			NewStatus: repository.ReturnStatusEnumRefunded,
+			Note:      note,
+		})
+		if err != nil {
+			return returnTransitionError(err)
+		}
+		listItem, err := qtx.GetReturnItemByReturn(ctx, orderReturn.ID)
+		if err != nil {
+			return err
+		}
+		listRefund = make([]repository.Refund, 0, len(listItem))
+		for _, item := range listItem {
+			order, err := qtx.GetOrderByID(ctx, item.OrderID)
+			if err != nil {
+				return err
+			}
+			// only a payment captured online is refunded through the provider
+			status := repository.RefundStatusEnumManual
+			if srv.payment != nil && order.PaymentStatus == repository.PaymentStatusEnumCaptured {
+				status = repository.RefundStatusEnumPending
+			}
+			refund, err := qtx.CreateRefund(ctx, repository.CreateRefundParams{
+				ReturnID:  orderReturn.ID,
+				OrderID:   order.ID,
+				Amount:    refundAmount(order, item.Quantity),
+				PaymentID: order.PaymentID,
+				Status:    status,
+			})
+			if err != nil {
+				log.Println(err)
+				return errors.New("Hoàn tiền không thành công")
+			}
+			listRefund = append(listRefund, refund)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// a refund the provider doesn't confirm stays pending and is retried
+	for _, refund := range listRefund {
+		if refund.Status != repository.RefundStatusEnumPending {
+			continue
+		}
+		if err := srv.refundPayment(ctx, refund); err != nil {
+			log.Println("can't refund payment, it is tried again later: ", refund.ID, err)
+		}
+	}
+
+	return srv.getPbReturn(ctx, orderReturn)
+}
+
+func (srv orderService) GetReturn(ctx context.Context, req *pb.GetReturnRequest) (*pb.OrderReturn, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	orderReturn, err := srv.orderStore.GetReturnRequestByID(ctx, req.GetReturnId())
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không tìm thấy yêu cầu trả hàng")
+	}
+	if !(userID == orderReturn.CustomerID || userID == orderReturn.SupplierID || claims.GetUserRole() == pb.UserRole_admin) {
+		return nil, errors.New("Unauthorization")
+	}
+
+	return srv.getPbReturn(ctx, orderReturn)
+}
+
+func (srv orderService) ListReturn(ctx context.Context, _ *empty.Empty) (*pb.ListReturnResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	var listReturn []repository.ReturnRequest
+	if claims.GetUserRole() == pb.UserRole_supplier {
+		listReturn, err = srv.orderStore.GetReturnRequestBySupplier(ctx, userID)
+	} else {
+		listReturn, err = srv.orderStore.GetReturnRequestByCustomer(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*pb.OrderReturn, 0, len(listReturn))
+	for _, orderReturn := range listReturn {
+		v, err := srv.getPbReturn(ctx, orderReturn)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+
+	return &pb.ListReturnResponse{
+		ListReturn: result,
+	}, nil
+}
+
+// getSupplierReturn loads a return and checks the caller is its supplier.
+func (srv orderService) getSupplierReturn(ctx context.Context, returnID int64) (repository.ReturnRequest, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return repository.ReturnRequest{}, err
+	}
+	supplierID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	orderReturn, err := srv.orderStore.GetReturnRequestByID(ctx, returnID)
+	if err != nil {
+		log.Println(err)
+		return repository.ReturnRequest{}, errors.New("Không tìm thấy yêu cầu trả hàng")
+	}
+	if orderReturn.SupplierID != supplierID {
+		return repository.ReturnRequest{}, errors.New("Cập nhật yêu cầu trả hàng không thành công, unauthorization")
+	}
+	return orderReturn, nil
+}
+
+func (srv orderService) getPbReturn(ctx context.Context, orderReturn repository.ReturnRequest) (*pb.OrderReturn, error) {
+	listItem, err := srv.orderStore.GetReturnItemByReturn(ctx, orderReturn.ID)
+	if err != nil {
+		return nil, err
+	}
+	listRefund, err := srv.orderStore.GetRefundByReturn(ctx, orderReturn.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	listOrderID := make([]int64, 0, len(listItem))
+	for _, item := range listItem {
+		listOrderID = append(listOrderID, item.OrderID)
+	}
+	orderNumbers, err := srv.orderNumbers(ctx, listOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &pb.OrderReturn{
+		ReturnId:   orderReturn.ID,
+		CustomerId: orderReturn.CustomerID,
+		SupplierId: orderReturn.SupplierID,
+		Reason:     orderReturn.Reason,
+		Status:     pb.ReturnStatus(pb.ReturnStatus_value["return_"+string(orderReturn.Status)]),
+		Note:       orderReturn.Note,
+		CreatedAt:  timestamppb.New(orderReturn.CreatedAt),
+		UpdatedAt:  timestamppb.New(orderReturn.UpdatedAt),
+	}
+	for _, item := range listItem {
+		result.ListItem = append(result.ListItem, &pb.ReturnItem{
+			OrderId:     item.OrderID,
+			Quantity:    item.Quantity,
+			OrderNumber: orderNumbers[item.OrderID],
+		})
+	}
+	for _, refund := range listRefund {
+		result.ListRefund = append(result.ListRefund, &pb.Refund{
+			RefundId:    refund.ID,
+			OrderId:     refund.OrderID,
+			Amount:      refund.Amount,
+			CreatedAt:   timestamppb.New(refund.CreatedAt),
+			OrderNumber: orderNumbers[refund.OrderID],
+			Status:      toPbRefundStatus(refund.Status),
+		})
+	}
+	return result, nil
+}
+
+// returnTransitionError turns the no-row result of a conditional status
+// update into a message the user understands.
+func returnTransitionError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("Trạng thái yêu cầu trả hàng không hợp lệ")
+	}
+	log.Println(err)
+	return errors.New("Cập nhật yêu cầu trả hàng không thành công")
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+func TestRequestReturnRace(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+		order := testOrder(t, store, func(arg *repository.CreateOrderParams) {
+			arg.ProductID = 1
+			arg.Quantity = 5
+		})
+		shipOrder(t, store, order.ID)
+
+		// 2 + 2 fit in the 5 items bought, a third request doesn't
+		n := hammer(10, func() error {
+			_, err := srv.RequestReturn(userContext("customer"), &pb.RequestReturnRequest{
+				Reason:   "lỗi",
+				ListItem: []*pb.ReturnItem{{OrderId: order.ID, Quantity: 2}},
+			})
+			return err
+		})
+		if n != 2 {
+			t.Fatalf("%d return requests succeeded, want 2", n)
+		}
+		returned, err := store.GetReturnedQuantityByOrder(ctx, order.ID)
+		if err != nil || returned != 4 {
+			t.Fatalf("returned quantity = %d, %v, want 4", returned, err)
+		}
+	})
+}
+
+// shipOrder moves a waiting order to shipped.
+func shipOrder(t *testing.T, store OrderStore, id int64) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := store.HandleOrder(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	_, err := store.UpdateOrderStatus(ctx, repository.UpdateOrderStatusParams{
+		ID:        id,
+		OldStatus: orderStatus(repository.OrderStatusEnumHandled),
+		NewStatus: orderStatus(repository.OrderStatusEnumShipped),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequestReturnStatus(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+		req := func(order repository.Order) *pb.RequestReturnRequest {
+			return &pb.RequestReturnRequest{
+				Reason:   "lỗi",
+				ListItem: []*pb.ReturnItem{{OrderId: order.ID, Quantity: 1}},
+			}
+		}
+
+		// a handled order was never sent, it is cancelled instead
+		handled := testOrder(t, store, nil)
+		if _, err := store.HandleOrder(ctx, handled.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.RequestReturn(userContext("customer"), req(handled)); err == nil {
+			t.Error("a handled order was returned")
+		}
+
+		shipped := testOrder(t, store, nil)
+		shipOrder(t, store, shipped.ID)
+		if _, err := srv.RequestReturn(userContext("customer"), req(shipped)); err != nil {
+			t.Errorf("a shipped order can't be returned: %v", err)
+		}
+	})
+}
+
+// lostRefundProvider refunds the payments but loses the answer of the
+// provider while lost is set.
+type lostRefundProvider struct {
+	*fakePaymentProvider
+	lost bool
+}
+
+func (p *lostRefundProvider) Refund(ctx context.Context, paymentID string, amount int64, key string) error {
+	if err := p.fakePaymentProvider.Refund(ctx, paymentID, amount, key); err != nil {
+		return err
+	}
+	if p.lost {
+		return errors.New("lost refund: connection reset")
+	}
+	return nil
+}
+
+func TestRefundReturnRetry(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryOrderStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	srv := newTestService(t, store)
+	payment := &lostRefundProvider{fakePaymentProvider: newFakePaymentProvider(0), lost: true}
+	srv.payment = payment
+
+	paymentID, err := payment.Authorize(ctx, 1, 200000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := testOrder(t, store, func(arg *repository.CreateOrderParams) {
+		arg.ProductID = 1
+		arg.PaymentStatus = repository.PaymentStatusEnumAuthorized
+		arg.PaymentID = paymentID
+	})
+	if _, err := srv.HandleOrder(userContext("supplier"), &pb.HandleOrderRequest{OrderId: order.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.UpdateOrderStatus(ctx, repository.UpdateOrderStatusParams{
+		ID:        order.ID,
+		OldStatus: orderStatus(repository.OrderStatusEnumHandled),
+		NewStatus: orderStatus(repository.OrderStatusEnumShipped),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	orderReturn, err := srv.RequestReturn(userContext("customer"), &pb.RequestReturnRequest{
+		Reason:   "lỗi",
+		ListItem: []*pb.ReturnItem{{OrderId: order.ID, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := &pb.UpdateReturnRequest{ReturnId: orderReturn.GetReturnId()}
+	for _, step := range []func(context.Context, *pb.UpdateReturnRequest) (*pb.OrderReturn, error){
+		srv.ApproveReturn, srv.ReceiveReturn, srv.RefundReturn,
+	} {
+		if orderReturn, err = step(userContext("supplier"), update); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the answer of the provider is lost, the refund stays pending
+	if len(orderReturn.GetListRefund()) != 1 || orderReturn.GetListRefund()[0].GetStatus() != pb.RefundStatus_refund_pending {
+		t.Fatalf("the refunds are %v, want one pending", orderReturn.GetListRefund())
+	}
+	found, err := store.GetOrderByID(ctx, order.ID)
+	if err != nil || found.PaymentStatus != repository.PaymentStatusEnumCaptured {
+		t.Fatalf("the order is %+v, %v, want it captured until the refund is confirmed", found, err)
+	}
+	if n, err := srv.refundPendingReturns(ctx); err != nil || n != 0 {
+		t.Fatalf("refundPendingReturns before the retry time = %d, %v", n, err)
+	}
+
+	// the retry is confirmed without paying the customer twice
+	payment.lost = false
+	now = now.Add(refundRetryAfter)
+	if n, err := srv.refundPendingReturns(ctx); err != nil || n != 1 {
+		t.Fatalf("refundPendingReturns = %d, %v, want 1", n, err)
+	}
+	payment.mu.Lock()
+	refunded := payment.payments[paymentID].refunded
+	payment.mu.Unlock()
+	if refunded != 200000 {
+		t.Errorf("the provider refunded %d, want 200000", refunded)
+	}
+	found, err = store.GetOrderByID(ctx, order.ID)
+	if err != nil || found.PaymentStatus != repository.PaymentStatusEnumRefunded {
+		t.Errorf("the order is %+v, %v, want it refunded", found, err)
+	}
+	listRefund, err := store.GetRefundByReturn(ctx, orderReturn.GetReturnId())
+	if err != nil || len(listRefund) != 1 || listRefund[0].Status != repository.RefundStatusEnumRefunded {
+		t.Errorf("the refunds are %+v, %v", listRefund, err)
+	}
+	if n, err := srv.refundPendingReturns(ctx); err != nil || n != 0 {
+		t.Errorf("refundPendingReturns twice = %d, %v", n, err)
+	}
+}
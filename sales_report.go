@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	// the alpine image has no zoneinfo, the time zones of the reports are
+	// embedded in the binary
+	_ "time/tzdata"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultReportTimeZone  = "Asia/Ho_Chi_Minh"
+	defaultReportDays      = 30
+	maxReportBuckets       = 400
+	defaultTopProductLimit = 5
+	maxTopProductLimit     = 50
+)
+
+// GetSupplierSalesReport sums up the sales of the calling supplier between
+// created_from and created_to, by default the last 30 days: the orders, units
+// and revenue of every day, week or month, the best selling products, the
+// share of cancelled orders and how long orders wait to be handled.
+func (srv orderService) GetSupplierSalesReport(ctx context.Context, req *pb.GetSupplierSalesReportRequest) (*pb.GetSupplierSalesReportResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	if claims.GetUserRole() != pb.UserRole_supplier {
+		return nil, errors.New("Unauthorization")
+	}
+	supplierID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	interval, err := reportInterval(req.GetInterval())
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Khoảng thời gian báo cáo không hợp lệ")
+	}
+
+	// Postgres and Go both know the IANA names, "Local" is only known to Go
+	timeZone := req.GetTimeZone()
+	if timeZone == "" {
+		timeZone = defaultReportTimeZone
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil || timeZone == "Local" {
+		return nil, errors.New("Múi giờ không hợp lệ")
+	}
+
+	createdTo := time.Now()
+	if req.GetCreatedTo() != nil {
+		createdTo = req.GetCreatedTo().AsTime()
+	}
+	createdFrom := createdTo.AddDate(0, 0, -defaultReportDays)
+	if req.GetCreatedFrom() != nil {
+		createdFrom = req.GetCreatedFrom().AsTime()
+	}
+	if !createdFrom.Before(createdTo) {
+		return nil, errors.New("Khoảng thời gian báo cáo không hợp lệ")
+	}
+	listStart := reportBuckets(createdFrom, createdTo, interval, loc)
+	if len(listStart) > maxReportBuckets {
+		return nil, errors.New("Khoảng thời gian báo cáo quá dài")
+	}
+
+	limit := int32(defaultTopProductLimit)
+	if req.GetTopProductLimit() > 0 {
+		limit = req.GetTopProductLimit()
+	}
+	if limit > maxTopProductLimit {
+		limit = maxTopProductLimit
+	}
+
+	listRow, err := srv.orderStore.GetSupplierSalesSeries(ctx, repository.GetSupplierSalesSeriesParams{
+		TimeZone:    timeZone,
+		Bucket:      interval,
+		SupplierID:  supplierID,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy báo cáo doanh thu")
+	}
+	listProduct, err := srv.orderStore.GetSupplierTopProducts(ctx, repository.GetSupplierTopProductsParams{
+		SupplierID:  supplierID,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+		RowLimit:    limit,
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy báo cáo doanh thu")
+	}
+	summary, err := srv.orderStore.GetSupplierSalesSummary(ctx, repository.GetSupplierSalesSummaryParams{
+		SupplierID:  supplierID,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không thể lấy báo cáo doanh thu")
+	}
+
+	// the days without sales have no row, they are in the series with zeros
+	m := make(map[int64]repository.GetSupplierSalesSeriesRow, len(listRow))
+	for _, row := range listRow {
+		m[row.BucketStart.Unix()] = row
+	}
+	listBucket := make([]*pb.SalesBucket, 0, len(listStart))
+	for _, start := range listStart {
+		row := m[start.Unix()]
+		listBucket = append(listBucket, &pb.SalesBucket{
+			Start:      timestamppb.New(start),
+			OrderCount: row.OrderCount,
+			UnitCount:  row.UnitCount,
+			Revenue:    row.Revenue,
+		})
+	}
+
+	listTopProduct := make([]*pb.ProductSales, 0, len(listProduct))
+	for _, product := range listProduct {
+		listTopProduct = append(listTopProduct, &pb.ProductSales{
+			ProductId:   product.ProductID,
+			ProductName: product.ProductName,
+			OrderCount:  product.OrderCount,
+			UnitCount:   product.UnitCount,
+			Revenue:     product.Revenue,
+		})
+	}
+
+	var cancellationRate float64
+	if summary.OrderCount > 0 {
+		cancellationRate = float64(summary.CancelledCount) / float64(summary.OrderCount)
+	}
+
+	return &pb.GetSupplierSalesReportResponse{
+		ListBucket:             listBucket,
+		ListTopProduct:         listTopProduct,
+		OrderCount:             summary.OrderCount,
+		CancelledCount:         summary.CancelledCount,
+		CancellationRate:       cancellationRate,
+		UnitCount:              summary.UnitCount,
+		Revenue:                summary.Revenue,
+		AverageHandlingSeconds: int64(summary.AverageHandlingSeconds),
+		TimeZone:               timeZone,
+	}, nil
+}
+
+// reportInterval is the date_trunc field of an interval.
+func reportInterval(interval pb.ReportInterval) (string, error) {
+	switch interval {
+	case pb.ReportInterval_report_day:
+		return "day", nil
+	case pb.ReportInterval_report_week:
+		return "week", nil
+	case pb.ReportInterval_report_month:
+		return "month", nil
+	}
+	return "", fmt.Errorf("unknown report interval: %v", interval)
+}
+
+// reportBuckets returns the start of every bucket between from and to.
+func reportBuckets(from, to time.Time, interval string, loc *time.Location) []time.Time {
+	var listStart []time.Time
+	for start := reportBucketStart(from, interval, loc); start.Before(to); start = nextReportBucket(start, interval) {
+		listStart = append(listStart, start)
+		// a long range is refused, there is no need to go on
+		if len(listStart) > maxReportBuckets {
+			break
+		}
+	}
+	return listStart
+}
+
+// reportBucketStart returns the start of the bucket of t, the way date_trunc
+// cuts it in loc: weeks start on Monday.
+func reportBucketStart(t time.Time, interval string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	switch interval {
+	case "week":
+		start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+	case "month":
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	}
+	return start
+}
+
+func nextReportBucket(start time.Time, interval string) time.Time {
+	switch interval {
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "month":
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
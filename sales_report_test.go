@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestReportBucketStart(t *testing.T) {
+	vietnam := mustLoadLocation(t, "Asia/Ho_Chi_Minh")
+	newYork := mustLoadLocation(t, "America/New_York")
+	for _, tc := range []struct {
+		t        time.Time
+		interval string
+		loc      *time.Location
+		want     time.Time
+	}{
+		// 18:00 UTC is already the next day in Vietnam
+		{time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC), "day", vietnam, time.Date(2026, 3, 2, 0, 0, 0, 0, vietnam)},
+		{time.Date(2026, 3, 1, 16, 59, 0, 0, time.UTC), "day", vietnam, time.Date(2026, 3, 1, 0, 0, 0, 0, vietnam)},
+		// 2026-03-02 is a Monday, the Sunday before belongs to the week before
+		{time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC), "week", vietnam, time.Date(2026, 3, 2, 0, 0, 0, 0, vietnam)},
+		{time.Date(2026, 3, 1, 16, 59, 0, 0, time.UTC), "week", vietnam, time.Date(2026, 2, 23, 0, 0, 0, 0, vietnam)},
+		{time.Date(2026, 2, 28, 17, 0, 0, 0, time.UTC), "month", vietnam, time.Date(2026, 3, 1, 0, 0, 0, 0, vietnam)},
+		{time.Date(2026, 2, 28, 16, 0, 0, 0, time.UTC), "month", vietnam, time.Date(2026, 2, 1, 0, 0, 0, 0, vietnam)},
+		// the day the clocks go forward in New York has 23 hours
+		{time.Date(2026, 3, 8, 23, 0, 0, 0, newYork), "day", newYork, time.Date(2026, 3, 8, 0, 0, 0, 0, newYork)},
+		{time.Date(2026, 3, 9, 3, 30, 0, 0, time.UTC), "day", newYork, time.Date(2026, 3, 8, 0, 0, 0, 0, newYork)},
+	} {
+		got := reportBucketStart(tc.t, tc.interval, tc.loc)
+		if !got.Equal(tc.want) {
+			t.Errorf("reportBucketStart(%s, %s, %s) = %s, want %s", tc.t, tc.interval, tc.loc, got, tc.want)
+		}
+	}
+}
+
+func TestReportBuckets(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	// every day starts at midnight, on both sides of the change of the clocks
+	listStart := reportBuckets(
+		time.Date(2026, 3, 7, 12, 0, 0, 0, newYork),
+		time.Date(2026, 3, 10, 0, 0, 0, 0, newYork),
+		"day", newYork)
+	if len(listStart) != 3 {
+		t.Fatalf("reportBuckets = %v, want 3 days", listStart)
+	}
+	for i, start := range listStart {
+		if start.Day() != 7+i || start.Hour() != 0 {
+			t.Errorf("bucket %d starts at %s", i, start)
+		}
+	}
+
+	// months of different lengths
+	listStart = reportBuckets(
+		time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+		"month", time.UTC)
+	if len(listStart) != 3 || listStart[1].Month() != time.February || listStart[2].Month() != time.March || listStart[2].Day() != 1 {
+		t.Errorf("reportBuckets = %v, want January to March", listStart)
+	}
+
+	// the end is excluded
+	listStart = reportBuckets(
+		time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+		"week", time.UTC)
+	if len(listStart) != 1 {
+		t.Errorf("reportBuckets = %v, want one week", listStart)
+	}
+
+	// a long range stops past the limit
+	listStart = reportBuckets(
+		time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		"day", time.UTC)
+	if len(listStart) != maxReportBuckets+1 {
+		t.Errorf("reportBuckets returned %d days, want it to stop at %d", len(listStart), maxReportBuckets+1)
+	}
+}
+
+func TestSupplierSalesReportBuckets(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+		now := time.Now()
+
+		// a sale now and one two days ago, and a cancelled order that isn't one
+		var listOrder []repository.Order
+		for _, age := range []time.Duration{0, 48 * time.Hour} {
+			order := testOrder(t, store, nil)
+			if _, err := store.HandleOrder(ctx, order.ID); err != nil {
+				t.Fatal(err)
+			}
+			backdateOrder(t, store, order.ID, age)
+			order, err := store.GetOrderByID(ctx, order.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			listOrder = append(listOrder, order)
+		}
+		cancelled := testOrder(t, store, nil)
+		if _, err := store.CancelOrder(ctx, cancelled.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, timeZone := range []string{"Asia/Ho_Chi_Minh", "America/New_York"} {
+			loc := mustLoadLocation(t, timeZone)
+			resp, err := srv.GetSupplierSalesReport(userContext("supplier"), &pb.GetSupplierSalesReportRequest{
+				Interval:    pb.ReportInterval_report_day,
+				TimeZone:    timeZone,
+				CreatedFrom: timestamppb.New(now.Add(-72 * time.Hour)),
+				CreatedTo:   timestamppb.New(now.Add(time.Hour)),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// every day of the range is there, the days without sales with zeros
+			wantDays := reportBuckets(now.Add(-72*time.Hour), now.Add(time.Hour), "day", loc)
+			if len(resp.GetListBucket()) != len(wantDays) {
+				t.Fatalf("%s: %d buckets, want %d", timeZone, len(resp.GetListBucket()), len(wantDays))
+			}
+			want := make(map[string]int64)
+			for _, order := range listOrder {
+				want[order.CreatedAt.In(loc).Format("2006-01-02")] += order.Price * int64(order.Quantity)
+			}
+			var revenue int64
+			for _, bucket := range resp.GetListBucket() {
+				start := bucket.GetStart().AsTime().In(loc)
+				if start.Hour() != 0 || start.Minute() != 0 {
+					t.Errorf("%s: a bucket starts at %s, not at midnight", timeZone, start)
+				}
+				day := start.Format("2006-01-02")
+				if bucket.GetRevenue() != want[day] {
+					t.Errorf("%s: %s has revenue %d, want %d", timeZone, day, bucket.GetRevenue(), want[day])
+				}
+				revenue += bucket.GetRevenue()
+			}
+			if revenue != resp.GetRevenue() || resp.GetOrderCount() != 3 || resp.GetCancelledCount() != 1 {
+				t.Errorf("%s: the report is %+v", timeZone, resp)
+			}
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/e-commerce-microservices/order-service/orderstatus"
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchOrders finds orders by the name, phone and address of the customer
+// and the product name, diacritics don't matter. A query that is an order
+// number finds that order. A supplier only searches its own orders, an admin
+// searches all of them and can narrow them to a supplier or a customer.
+func (srv orderService) SearchOrders(ctx context.Context, req *pb.SearchOrdersRequest) (*pb.SearchOrdersResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	arg := repository.SearchOrdersParams{
+		ListStatus: []string{},
+		RowLimit:   defaultSearchLimit,
+		RowOffset:  req.GetOffset(),
+	}
+	switch claims.GetUserRole() {
+	case pb.UserRole_admin:
+		// an admin searches across every customer and supplier
+		if req.GetSupplierId() != 0 {
+			arg.SupplierID = sql.NullInt64{Int64: req.GetSupplierId(), Valid: true}
+		}
+		if req.GetCustomerId() != 0 {
+			arg.CustomerID = sql.NullInt64{Int64: req.GetCustomerId(), Valid: true}
+		}
+	case pb.UserRole_supplier:
+		arg.SupplierID = sql.NullInt64{Int64: userID, Valid: true}
+		if req.GetCustomerId() != 0 {
+			arg.CustomerID = sql.NullInt64{Int64: req.GetCustomerId(), Valid: true}
+		}
+	default:
+		return nil, errors.New("Unauthorization")
+	}
+
+	if orderNumber, ok := parseOrderNumber(req.GetQuery()); ok {
+		arg.OrderNumber = sql.NullString{String: orderNumber, Valid: true}
+	} else if query := searchQuery(req.GetQuery()); query != "" {
+		arg.Query = sql.NullString{String: query, Valid: true}
+	}
+	for _, pbStatus := range req.GetListStatus() {
+		status, err := orderstatus.FromPb(pbStatus)
+		if err != nil {
+			log.Println(err)
+			return nil, errors.New("Trạng thái đơn hàng không hợp lệ")
+		}
+		arg.ListStatus = append(arg.ListStatus, string(status))
+	}
+	if req.GetCreatedFrom() != nil {
+		arg.CreatedFrom = sql.NullTime{Time: req.GetCreatedFrom().AsTime(), Valid: true}
+	}
+	if req.GetCreatedTo() != nil {
+		arg.CreatedTo = sql.NullTime{Time: req.GetCreatedTo().AsTime(), Valid: true}
+	}
+	if req.GetLimit() > 0 {
+		arg.RowLimit = req.GetLimit()
+	}
+	if arg.RowLimit > maxSearchLimit {
+		arg.RowLimit = maxSearchLimit
+	}
+	if arg.RowOffset < 0 {
+		arg.RowOffset = 0
+	}
+
+	listOrder, err := srv.orderStore.SearchOrders(ctx, arg)
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Tìm kiếm đơn hàng không thành công")
+	}
+	// the query holds names and phone numbers, it is not logged
+	if claims.GetUserRole() == pb.UserRole_admin {
+		log.Printf("admin %d searched orders: %d found", userID, len(listOrder))
+	}
+
+	// a deleted product must not hide the order, see toPbOrder
+	m := make(map[int64]*pb.Product)
+	if len(listOrder) > 0 {
+		listID := make([]int64, 0, len(listOrder))
+		for _, order := range listOrder {
+			listID = append(listID, order.ProductID)
+		}
+		resp, err := srv.productClient.GetListProductByIDs(ctx, &pb.GetListProductByIDsRequest{
+			ListId: listID,
+		})
+		if err != nil {
+			log.Println("can't get list product: ", err)
+		}
+		for _, v := range resp.GetListProduct() {
+			m[v.ProductId] = v
+		}
+	}
+
+	result := make([]*pb.Order, 0, len(listOrder))
+	for _, order := range listOrder {
+		product := m[order.ProductID]
+		addr, _ := srv.orderStore.GetAddressById(ctx, order.AddressID)
+
+		result = append(result, toPbOrder(order, product, addr))
+	}
+
+	return &pb.SearchOrdersResponse{
+		ListOrder: result,
+	}, nil
+}
+
+// searchQuery turns what a person typed into a tsquery where every word
+// matches as a prefix, "Nguyễn 0912" finds Nguyễn Văn A with 0912345678.
+// Only letters and digits are kept so the query can't be malformed, the
+// accents are removed by order_search_normalize in the database.
+func searchQuery(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
@@ -2,24 +2,36 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 
+	"github.com/e-commerce-microservices/order-service/orderstatus"
 	"github.com/e-commerce-microservices/order-service/pb"
 	"github.com/e-commerce-microservices/order-service/repository"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/itimofeev/go-saga"
 	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type orderService struct {
 	authClient    pb.AuthServiceClient
 	productClient pb.ProductServiceClient
 	cartClient    pb.CartServiceClient
-	orderRepo     repository.Queries
+	orderStore    OrderStore
+	carrier       CarrierAdapter
+	payment       PaymentProvider
+	shippingFee   int64
+	// largest price difference accepted between the cart and the checkout
+	priceTolerance int64
 	pb.UnimplementedOrderServiceServer
 }
 
@@ -28,10 +40,38 @@ var _empty = &empty.Empty{}
 var tracer = otel.Tracer("order-service")
 
 func (srv orderService) GetAddressOrder(ctx context.Context, req *pb.GetAddressOrderRequest) (*pb.GetAddressOrderResponse, error) {
-	address, err := srv.orderRepo.GetAddressById(ctx, req.GetAddressId())
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	address, err := srv.orderStore.GetAddressById(ctx, req.GetAddressId())
 	if err != nil {
 		return nil, errors.New("có lỗi xảy ra, không thể tìm thấy địa chỉ")
 	}
+
+	// the address is visible to the customer and the suppliers of its orders
+	authorized := claims.GetUserRole() == pb.UserRole_admin || (address.CustomerID.Valid && address.CustomerID.Int64 == userID)
+	if !authorized {
+		listOrder, err := srv.orderStore.GetOrderByAddressId(ctx, address.ID)
+		if err != nil {
+			return nil, errors.New("có lỗi xảy ra, không thể tìm thấy địa chỉ")
+		}
+		for _, order := range listOrder {
+			if userID == order.CustomerID || userID == order.SupplierID {
+				authorized = true
+				break
+			}
+		}
+	}
+	if !authorized {
+		return nil, errors.New("Unauthorization")
+	}
 	return &pb.GetAddressOrderResponse{
 		Name:   address.Name,
 		Phone:  address.Phone,
@@ -39,6 +79,93 @@ func (srv orderService) GetAddressOrder(ctx context.Context, req *pb.GetAddressO
 	}, nil
 }
 
+func (srv orderService) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	var order repository.Order
+	if req.GetOrderId() == 0 && req.GetOrderNumber() != "" {
+		orderNumber, ok := parseOrderNumber(req.GetOrderNumber())
+		if !ok {
+			return nil, errors.New("Mã đơn hàng không hợp lệ")
+		}
+		order, err = srv.orderStore.GetOrderByNumber(ctx, orderNumber)
+	} else {
+		order, err = srv.orderStore.GetOrderByID(ctx, req.GetOrderId())
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không tìm thấy đơn hàng")
+	}
+
+	// only the customer, the supplier of the order or an admin can see it
+	if !(userID == order.CustomerID || userID == order.SupplierID || claims.GetUserRole() == pb.UserRole_admin) {
+		return nil, errors.New("Unauthorization")
+	}
+
+	return srv.getPbOrder(ctx, order), nil
+}
+
+// getPbOrder returns the order with its product and address.
+func (srv orderService) getPbOrder(ctx context.Context, order repository.Order) *pb.Order {
+	// a deleted product must not hide the order, return it without product info
+	product, err := srv.productClient.GetProduct(ctx, &pb.GetProductRequest{
+		ProductId: order.ProductID,
+	})
+	if err != nil {
+		log.Println("can't get product: ", err)
+	}
+	addr, _ := srv.orderStore.GetAddressById(ctx, order.AddressID)
+
+	return toPbOrder(order, product, addr)
+}
+
+// toPbOrder is the order as the API shows it. The name and price are the
+// snapshots of the checkout, product only fills them for orders older than
+// the snapshots and gives the image. product is nil when it was deleted.
+func toPbOrder(order repository.Order, product *pb.Product, addr repository.Address) *pb.Order {
+	productName, productPrice := order.ProductName, order.Price
+	if productName == "" {
+		productName = product.GetName()
+	}
+	if productPrice == 0 {
+		productPrice = product.GetPrice()
+	}
+
+	return &pb.Order{
+		ProductPrice:  productPrice,
+		ProductName:   productName,
+		ProductImage:  product.GetThumbnail(),
+		OrderId:       order.ID,
+		OrderNumber:   order.OrderNumber,
+		ProductId:     order.ProductID,
+		OrderQuantity: order.Quantity,
+		CustomerId:    order.CustomerID,
+		SupplierId:    order.SupplierID,
+		AddressName:   addr.Name,
+		AddressPhone:  addr.Phone,
+		AddressDetail: addr.Detail,
+		PaymentStatus: toPbPaymentStatus(order.PaymentStatus),
+		Discount:      order.Discount,
+		CancelReason:  order.CancelReason,
+		Status:        toPbOrderStatus(order.Status),
+		CreatedAt:     timestamppb.New(order.CreatedAt),
+		UpdatedAt:     timestamppb.New(order.UpdatedAt),
+		HandledAt:     toPbNullTime(order.HandledAt),
+		CancelledAt:   toPbNullTime(order.CancelledAt),
+		HeldAt:        toPbNullTime(order.HeldAt),
+		HoldReason:    order.HoldReason,
+		ShippingFee:   order.ShippingFee,
+	}
+}
+
 func (srv orderService) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error) {
 	var err error
 	orderSaga := saga.NewSaga("order-saga")
@@ -57,86 +184,78 @@ func (srv orderService) CreateOrder(ctx context.Context, req *pb.CreateOrderRequ
 		return nil, err
 	}
 
-	log.Println("addr: ", req.Addr)
-	if req.Addr == nil || req.Addr.GetName() == "" || req.Addr.GetPhone() == "" || req.Addr.GetDetail() == "" {
-		return nil, errors.New("Địa chỉ không hợp lệ")
+	customerID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	log.Println("addr: ", req.GetAddressId(), req.Addr)
+	addr, err := srv.resolveOrderAddress(ctx, customerID, req)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Println("Create address")
-	var address repository.Address
+	if len(req.GetListOrder()) == 0 {
+		return nil, errors.New("Vui lòng chọn sản phẩm cần mua")
+	}
+
+	// per-line outcome, filled in by the saga steps
+	listLine := newOrderLines(req.GetListOrder())
+	// the stage the saga is at, for the dashboard when it fails
+	var stage string
+
+	// supplier and price come from product-service, never from the client,
+	// the customer must confirm again when a price moved since the cart
+	var priceChanged *pb.PriceChanged
 	orderSaga.AddStep(&saga.Step{
-		Name: "create address",
+		Name: "resolve order lines",
 		Func: func(ctx context.Context) error {
-			address, err = srv.orderRepo.CreateAddress(ctx, repository.CreateAddressParams{
-				Name:   req.Addr.GetName(),
-				Phone:  req.Addr.GetPhone(),
-				Detail: req.Addr.GetDetail(),
-			})
+			stage = checkoutStageResolveLines
+			err := srv.resolveOrderLines(ctx, customerID, req.GetListOrder(), listLine)
 			if err != nil {
-				return errors.New("Địa chỉ không hợp lệ")
+				return err
 			}
-			return nil
+			checkoutTotals(listLine, srv.shippingFee)
+			priceChanged, err = srv.checkPriceChanged(req.GetListOrder(), listLine)
+			return err
 		},
 		CompensateFunc: func(ctx context.Context) error {
-			log.Println("delete address", address)
-			err := srv.orderRepo.DeleteAddress(ctx, address.ID)
-			if err != nil {
-				return errors.New("Địa chỉ không hợp lệ")
-			}
 			return nil
 		},
 	})
 
-	fmt.Println("get list order from request", req.GetListOrder())
-	if len(req.GetListOrder()) == 0 {
-		return nil, errors.New("Vui lòng chọn sản phẩm cần mua")
-	}
-
-	// check product inventory + other order (waiting status)
-	for i := 0; i < len(req.GetListOrder()); i++ {
-		v := req.GetListOrder()[i]
+	// the coupon is only checked here, its use is counted with the orders
+	var coupon repository.Coupon
+	var discount int64
+	if req.GetCouponCode() != "" {
 		orderSaga.AddStep(&saga.Step{
-			Name: fmt.Sprintf("Check inventory %d", i),
+			Name: "apply coupon",
 			Func: func(ctx context.Context) error {
-				fmt.Println("order: ", v)
-				inventory, err := srv.productClient.GetListProductInventory(ctx, &pb.GetInventoryRequest{
-					ProductId: v.GetProductId(),
-				})
-				if err != nil {
-					return err
-				}
-				if inventory.GetCount() < int64(v.GetOrderQuantity()) {
-					return errors.New("Sản phẩm trong kho không đủ")
-				}
-				return nil
+				stage = checkoutStageApplyCoupon
+				coupon, discount, err = srv.applyCoupon(ctx, customerID, req.GetCouponCode(), listLine)
+				return err
 			},
 			CompensateFunc: func(ctx context.Context) error {
 				return nil
 			},
 		})
-		log.Println("create order")
-		customerID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+	}
 
-		var order repository.Order
+	// check product inventory + other order (waiting status)
+	listPaymentID := make([]string, len(listLine))
+	for i := 0; i < len(req.GetListOrder()); i++ {
+		i := i
+		v := req.GetListOrder()[i]
+		line := listLine[i]
 		orderSaga.AddStep(&saga.Step{
-			Name: fmt.Sprintf("createOrder: %d", i),
+			Name: fmt.Sprintf("Check inventory %d", i),
 			Func: func(ctx context.Context) error {
-				_, span := tracer.Start(ctx, "OrderService.Database.Insert")
-				defer span.End()
-				order, err = srv.orderRepo.CreateOrder(ctx, repository.CreateOrderParams{
-					CustomerID: customerID,
-					SupplierID: v.GetSupplierId(),
-					ProductID:  v.GetProductId(),
-					Quantity:   v.GetOrderQuantity(),
-					AddressID:  address.ID,
-				})
+				stage = checkoutStageCheckInventory
+				_, err := srv.checkInventory(ctx, v.GetProductId(), v.GetOrderQuantity())
 				if err != nil {
-					return err
+					line.Error = err.Error()
 				}
-				return nil
+				return err
 			},
 			CompensateFunc: func(ctx context.Context) error {
-				return srv.orderRepo.DeleteOrder(ctx, order.ID)
+				return nil
 			},
 		})
 
@@ -144,15 +263,14 @@ func (srv orderService) CreateOrder(ctx context.Context, req *pb.CreateOrderRequ
 		orderSaga.AddStep(&saga.Step{
 			Name: fmt.Sprintf("Update inventory: %d", i),
 			Func: func(ctx context.Context) error {
+				stage = checkoutStageUpdateInventory
 				_, err := srv.productClient.DescInventory(ctx, &pb.DescInventoryRequest{
 					ProductId: v.GetProductId(),
 					Count:     v.GetOrderQuantity(),
-				}) // tp, tpErr := jaegerTraceProvider()
-				// if tpErr != nil {
-				// 	log.Fatal(tpErr)
-				// }
-				// otel.SetTracerProvider(tp)
-				// otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+				})
+				if err != nil {
+					line.Error = err.Error()
+				}
 				return err
 			},
 			CompensateFunc: func(ctx context.Context) error {
@@ -164,6 +282,30 @@ func (srv orderService) CreateOrder(ctx context.Context, req *pb.CreateOrderRequ
 			},
 		})
 
+		if srv.payment != nil {
+			orderSaga.AddStep(&saga.Step{
+				Name: fmt.Sprintf("Authorize payment: %d", i),
+				Func: func(ctx context.Context) error {
+					stage = checkoutStageAuthorizePayment
+					paymentID, err := srv.payment.Authorize(ctx, customerID, line.GetTotalPrice()-line.GetDiscount()+line.GetShippingFee())
+					if err != nil {
+						line.Error = err.Error()
+						line.PaymentStatus = pb.PaymentStatus_payment_failed
+						return err
+					}
+					line.PaymentStatus = pb.PaymentStatus_payment_authorized
+					listPaymentID[i] = paymentID
+					return nil
+				},
+				CompensateFunc: func(ctx context.Context) error {
+					if listPaymentID[i] == "" {
+						return nil
+					}
+					return srv.payment.Void(ctx, listPaymentID[i])
+				},
+			})
+		}
+
 		// delete cart
 		// orderSaga.AddStep(&saga.Step{
 		// 	Name: fmt.Sprintf("Delete cart: %d", i),
@@ -188,20 +330,81 @@ func (srv orderService) CreateOrder(ctx context.Context, req *pb.CreateOrderRequ
 
 	}
 
+	// all the local writes of the checkout are committed at once as the last
+	// step, a failed commit leaves nothing behind and undoes the remote steps
+	var address repository.Address
+	orderSaga.AddStep(&saga.Step{
+		Name: "save orders",
+		Func: func(ctx context.Context) error {
+			stage = checkoutStageSaveOrders
+			_, span := tracer.Start(ctx, "OrderService.Database.Insert")
+			defer span.End()
+			address, err = srv.saveCheckout(ctx, customerID, addr, listLine, listPaymentID, coupon, discount)
+			return err
+		},
+		CompensateFunc: func(ctx context.Context) error {
+			return nil
+		},
+	})
+
 	coordinator := saga.NewCoordinator(ctx, ctx, orderSaga, sagaStore)
 	result := coordinator.Play()
 	if result.ExecutionError != nil {
 		log.Println("saga error: ", result.ExecutionError)
-		return nil, fmt.Errorf("Tạo đơn hàng không thành công, %s", result.ExecutionError.Error())
+		srv.recordCheckoutOutcome(customerID, checkoutFailureReason(result.ExecutionError, stage), len(listLine))
+		listFailedLine := make([]*pb.OrderLineResult, 0, 1)
+		for _, line := range listLine {
+			if line.GetError() != "" {
+				listFailedLine = append(listFailedLine, line)
+			}
+		}
+		code := codes.Aborted
+		details := []proto.Message{&pb.CreateOrderResponse{ListFailedLine: listFailedLine}}
+		switch {
+		case errors.Is(result.ExecutionError, errInvalidOrderLine), errors.Is(result.ExecutionError, errInvalidCoupon):
+			code = codes.InvalidArgument
+		case errors.Is(result.ExecutionError, errPriceChanged):
+			code = codes.FailedPrecondition
+			details = append(details, priceChanged)
+		}
+		st, err := status.New(code, fmt.Sprintf("Tạo đơn hàng không thành công, %s", result.ExecutionError.Error())).
+			WithDetails(details...)
+		if err != nil {
+			return nil, fmt.Errorf("Tạo đơn hàng không thành công, %s", result.ExecutionError.Error())
+		}
+		return nil, st.Err()
 	}
 
-	return &pb.CreateOrderResponse{
-		Message: "Tạo đơn hàng thành công",
-	}, nil
+	srv.recordCheckoutOutcome(customerID, "", len(listLine))
+
+	// saving the address is a convenience, the order is already placed
+	if req.GetSaveAddress() && req.GetAddressId() == 0 {
+		if _, err := srv.createCustomerAddress(ctx, customerID, addr, false); err != nil {
+			log.Println("can't save address: ", err)
+		}
+	}
+
+	response := &pb.CreateOrderResponse{
+		Message:   "Tạo đơn hàng thành công",
+		AddressId: address.ID,
+		ListLine:  listLine,
+	}
+	for _, line := range listLine {
+		response.ListOrderId = append(response.ListOrderId, line.GetOrderId())
+		response.ListOrderNumber = append(response.ListOrderNumber, line.GetOrderNumber())
+	}
+	totals := checkoutTotals(listLine, srv.shippingFee)
+	response.TotalQuantity = totals.quantity
+	response.TotalPrice = totals.subtotal
+	response.TotalDiscount = totals.discount
+	response.TotalShippingFee = totals.shippingFee
+	response.GrandTotal = totals.grandTotal
+
+	return response, nil
 }
 
 func (srv orderService) GetSoldProduct(ctx context.Context, req *pb.GetSoldProductRequest) (*pb.GetSoldProductResponse, error) {
-	listQuantity, _ := srv.orderRepo.CountOrderHandledByProductId(ctx, req.GetProductId())
+	listQuantity, _ := srv.orderStore.CountOrderHandledByProductId(ctx, req.GetProductId())
 	var cnt int64 = 0
 	for _, v := range listQuantity {
 		cnt += int64(v)
@@ -224,7 +427,7 @@ func (srv orderService) DeleteOrder(ctx context.Context, req *pb.DeleteOrderRequ
 
 	customerID, err := strconv.ParseInt(claims.GetId(), 10, 64)
 	// get supplier_id from order_id
-	order, err := srv.orderRepo.GetOrderByID(ctx, req.GetOrderId())
+	order, err := srv.orderStore.GetOrderByID(ctx, req.GetOrderId())
 	if err != nil {
 		log.Println(err)
 		return nil, errors.New("Hủy đơn hàng không thành công")
@@ -234,27 +437,84 @@ func (srv orderService) DeleteOrder(ctx context.Context, req *pb.DeleteOrderRequ
 		return nil, errors.New("Hủy đơn hàng không thành công, unauthorization")
 	}
 
-	// update product count
-	_, err = srv.productClient.IncInventory(ctx, &pb.IncInventoryRequest{
-		ProductId: order.ProductID,
-		Count:     order.Quantity,
+	bySupplier := customerID == order.SupplierID
+	err = srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		// the conditional update locks the order until the commit, a concurrent
+		// cancel or handle finds it in its new status and does nothing. The
+		// customer can only cancel an order the supplier hasn't handled yet
+		if bySupplier {
+			order, err = qtx.DeleteOrder(ctx, order.ID)
+		} else {
+			order, err = qtx.CancelOrder(ctx, order.ID)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			if bySupplier {
+				return errors.New("Hủy đơn hàng không thành công, đơn hàng đã được giao, đã bị hủy hoặc đang bị tạm giữ")
+			}
+			return errors.New("Hủy đơn hàng không thành công, chỉ có thể hủy đơn hàng đang chờ xử lý")
+		}
+		if err != nil {
+			log.Println(err)
+			return errors.New("Hủy đơn hàng không thành công")
+		}
+		if err := checkOrderCancellable(ctx, qtx, order); err != nil {
+			return err
+		}
+
+		if err := srv.givePaymentBack(ctx, qtx, order); err != nil {
+			log.Println(err)
+			return errors.New("Hủy đơn hàng không thành công, không thể hoàn tiền")
+		}
+		if err := releaseOrderCoupon(ctx, qtx, order); err != nil {
+			log.Println(err)
+			return errors.New("Hủy đơn hàng không thành công")
+		}
+		return nil
 	})
 	if err != nil {
-		log.Println(err)
-		return nil, errors.New("Hủy đơn hàng không thành công")
+		return nil, err
 	}
 
-	// delete order
-	err = srv.orderRepo.DeleteOrder(ctx, req.GetOrderId())
-	if err != nil {
-		log.Println(err)
-		return nil, errors.New("Hủy đơn hàng không thành công")
-	}
+	srv.finishCancel(ctx, order)
 
 	return &pb.DeleteOrderResponse{
 		Message: "Hủy đơn hàng thành công",
 	}, nil
 }
+
+// finishCancel gives back the inventory of a cancelled order once the cancel
+// is committed, the payment and the coupon are given back in its transaction.
+// The order is cancelled already, an inventory that can't be given back now
+// is retried.
+func (srv orderService) finishCancel(ctx context.Context, order repository.Order) {
+	if err := srv.restockOrder(ctx, order); err != nil {
+		log.Println("can't restock order, it is tried again later: ", order.ID, err)
+	}
+}
+
+// checkOrderCancellable refuses to cancel an order that has a shipment or a
+// return, its goods come back through a return which restocks what is
+// actually received. q must have locked the order.
+func checkOrderCancellable(ctx context.Context, q repository.Querier, order repository.Order) error {
+	shipped, err := q.GetShipmentQuantityByOrder(ctx, order.ID)
+	if err != nil {
+		log.Println(err)
+		return errors.New("Hủy đơn hàng không thành công")
+	}
+	if shipped.Allocated > 0 {
+		return errors.New("Hủy đơn hàng không thành công, đơn hàng đã có vận đơn")
+	}
+	returned, err := q.GetReturnedQuantityByOrder(ctx, order.ID)
+	if err != nil {
+		log.Println(err)
+		return errors.New("Hủy đơn hàng không thành công")
+	}
+	if returned > 0 {
+		return errors.New("Hủy đơn hàng không thành công, đơn hàng đang được trả hàng")
+	}
+	return nil
+}
+
 func (srv orderService) CheckOrderIsHandled(ctx context.Context, req *pb.CheckOrderIsHandledRequest) (*pb.CheckOrderIsHandledResponse, error) {
 	// auth
 	md, _ := metadata.FromIncomingContext(ctx)
@@ -267,7 +527,7 @@ func (srv orderService) CheckOrderIsHandled(ctx context.Context, req *pb.CheckOr
 	}
 
 	customerID, err := strconv.ParseInt(claims.GetId(), 10, 64)
-	n, err := srv.orderRepo.CheckOrderIsHandled(ctx, repository.CheckOrderIsHandledParams{
+	n, err := srv.orderStore.CheckOrderIsHandled(ctx, repository.CheckOrderIsHandledParams{
 		ProductID:  req.GetProductId(),
 		CustomerID: customerID,
 	})
@@ -288,25 +548,119 @@ func (srv orderService) CheckOrderIsHandled(ctx context.Context, req *pb.CheckOr
 	}, nil
 }
 
+// UpdateOrder sets the status of an order, it is how an admin corrects an
+// order stuck in the wrong status, the reason is kept in the audit. The
+// order goes through the same transition as when its supplier or an admin
+// moves it: a handled order has its payment captured, a cancelled one gives
+// back its payment, inventory and coupon. An order only moves forward, and
+// a held one only to cancel.
 func (srv orderService) UpdateOrder(ctx context.Context, req *pb.UpdateOrderStatusRequest) (*pb.UpdateOrderStatusResponse, error) {
-	var err error
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
 
-	err = srv.orderRepo.UpdateOrderStatus(ctx, repository.UpdateOrderStatusParams{
-		Status: repository.NullOrderStatusEnum{
-			OrderStatusEnum: repository.OrderStatusEnum(req.GetStatus().String()),
-			Valid:           false,
-		},
-		ID: req.GetOrderId(),
+	// auth
+	adminID, err := srv.adminID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reason := strings.TrimSpace(req.GetReason())
+	if reason == "" {
+		return nil, errors.New("Vui lòng nhập lý do")
+	}
+
+	status, err := orderstatus.FromPb(req.GetStatus())
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Trạng thái đơn hàng không hợp lệ")
+	}
+
+	var order repository.Order
+	err = srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		if status == repository.OrderStatusEnumCancel {
+			order, err = srv.forceCancelOrder(ctx, qtx, adminID, req.GetOrderId(), reason, auditActionSetStatus)
+			return err
+		}
+
+		current, err := qtx.GetOrderByIDForUpdate(ctx, req.GetOrderId())
+		if err != nil {
+			log.Println(err)
+			return errors.New("Không tìm thấy đơn hàng")
+		}
+		order = current
+		if !canSetOrderStatus(current.Status, status) {
+			return errors.New("Cập nhật đơn hàng không thành công, không thể chuyển sang trạng thái này")
+		}
+		if current.HeldAt.Valid {
+			return errors.New("Cập nhật đơn hàng không thành công, đơn hàng đang bị tạm giữ")
+		}
+
+		var updated repository.Order
+		if status == repository.OrderStatusEnumHandled {
+			updated, err = srv.handleOrder(ctx, qtx, current.ID)
+		} else {
+			updated, err = qtx.UpdateOrderStatus(ctx, repository.UpdateOrderStatusParams{
+				NewStatus: repository.NullOrderStatusEnum{
+					OrderStatusEnum: status,
+					Valid:           true,
+				},
+				ID:        current.ID,
+				OldStatus: current.Status,
+			})
+		}
+		if err != nil {
+			return err
+		}
+		_, err = qtx.CreateOrderAudit(ctx, repository.CreateOrderAuditParams{
+			OrderID:   current.ID,
+			AdminID:   adminID,
+			Action:    auditActionSetStatus,
+			Reason:    reason,
+			OldStatus: current.Status,
+			NewStatus: updated.Status,
+		})
+		if err != nil {
+			log.Println(err)
+			return errors.New("Cập nhật đơn hàng không thành công")
+		}
+		if hasStatus(updated.Status, repository.OrderStatusEnumDelivered) {
+			if err := issueInvoice(ctx, qtx, updated); err != nil {
+				log.Println(err)
+				return errors.New("Cập nhật đơn hàng không thành công, không thể xuất hóa đơn")
+			}
+		}
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	if status == repository.OrderStatusEnumCancel {
+		srv.finishCancel(ctx, order)
+	}
+
 	return &pb.UpdateOrderStatusResponse{
 		Message: "Cập nhật đơn hàng thành công",
 	}, nil
 }
 
+// canSetOrderStatus tells if an admin can move an order from one status to
+// the other with UpdateOrder, other than cancel. A waiting order must be
+// handled before it ships, what is shipped or delivered stays so.
+func canSetOrderStatus(from repository.NullOrderStatusEnum, to repository.OrderStatusEnum) bool {
+	if !from.Valid {
+		return false
+	}
+	switch from.OrderStatusEnum {
+	case repository.OrderStatusEnumWaiting:
+		return to == repository.OrderStatusEnumHandled
+	case repository.OrderStatusEnumHandled:
+		return to == repository.OrderStatusEnumShipped || to == repository.OrderStatusEnumDelivered
+	case repository.OrderStatusEnumShipped:
+		return to == repository.OrderStatusEnumDelivered
+	}
+	return false
+}
+
 func (srv orderService) HandleOrder(ctx context.Context, req *pb.HandleOrderRequest) (*pb.HandleOrderResponse, error) {
 	var err error
 
@@ -322,7 +676,7 @@ func (srv orderService) HandleOrder(ctx context.Context, req *pb.HandleOrderRequ
 
 	customerID, err := strconv.ParseInt(claims.GetId(), 10, 64)
 	// get supplier_id from order_id
-	order, err := srv.orderRepo.GetOrderByID(ctx, req.GetOrderId())
+	order, err := srv.orderStore.GetOrderByID(ctx, req.GetOrderId())
 	if err != nil {
 		log.Println(err)
 		return nil, errors.New("Xử lý đơn hàng không thành công")
@@ -332,7 +686,10 @@ func (srv orderService) HandleOrder(ctx context.Context, req *pb.HandleOrderRequ
 		return nil, errors.New("Xử lý đơn hàng không thành công, unauthorization")
 	}
 
-	err = srv.orderRepo.HandleOrder(ctx, req.GetOrderId())
+	err = srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		_, err := srv.handleOrder(ctx, qtx, order.ID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -341,6 +698,41 @@ func (srv orderService) HandleOrder(ctx context.Context, req *pb.HandleOrderRequ
 		Message: "Xử lý đơn hàng thành công",
 	}, nil
 }
+
+// handleOrder moves a waiting order to handled in q's transaction and
+// captures its authorized payment, the order stays locked until the payment
+// is captured. When the capture fails the caller rolls back, the order stays
+// waiting with its payment authorized and the capture is tried again the
+// next time the order is handled.
+func (srv orderService) handleOrder(ctx context.Context, q repository.Querier, id int64) (repository.Order, error) {
+	order, err := q.HandleOrder(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return order, errors.New("Xử lý đơn hàng không thành công, đơn hàng đã được xử lý, đã bị hủy hoặc đang bị tạm giữ")
+	}
+	if err != nil {
+		return order, err
+	}
+
+	// an order is never handled unpaid after its payment failed
+	if order.PaymentStatus == repository.PaymentStatusEnumFailed {
+		return order, errors.New("Xử lý đơn hàng không thành công, thanh toán của đơn hàng đã thất bại")
+	}
+	// the supplier accepts the order, charge the authorized payment
+	if srv.payment != nil && order.PaymentStatus == repository.PaymentStatusEnumAuthorized {
+		if err := srv.payment.Capture(ctx, order.PaymentID, orderAmount(order)); err != nil {
+			log.Println("can't capture payment of order: ", order.ID, order.PaymentID, err)
+			return order, errors.New("Xử lý đơn hàng không thành công, không thể thanh toán")
+		}
+		order.PaymentStatus = repository.PaymentStatusEnumCaptured
+		return order, q.UpdateOrderPayment(ctx, repository.UpdateOrderPaymentParams{
+			ID:            order.ID,
+			PaymentStatus: order.PaymentStatus,
+			PaymentID:     order.PaymentID,
+		})
+	}
+	return order, nil
+}
+
 func (srv orderService) GetWaitingOrderBySupplier(ctx context.Context, req *pb.GetWaitingOrderBySupplierRequest) (*pb.GetWaitingOrderBySupplierResponse, error) {
 	var err error
 	// auth
@@ -357,7 +749,7 @@ func (srv orderService) GetWaitingOrderBySupplier(ctx context.Context, req *pb.G
 	supplierID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
 	log.Println("get product by supplierID: ", supplierID)
 
-	listOrder, err := srv.orderRepo.GetWaitingOrderBySupplier(ctx, supplierID)
+	listOrder, err := srv.orderStore.GetWaitingOrderBySupplier(ctx, supplierID)
 	if err != nil {
 		return nil, err
 	}
@@ -386,21 +778,9 @@ func (srv orderService) GetWaitingOrderBySupplier(ctx context.Context, req *pb.G
 		if !ok {
 			continue
 		}
-		addr, _ := srv.orderRepo.GetAddressById(ctx, order.AddressID)
+		addr, _ := srv.orderStore.GetAddressById(ctx, order.AddressID)
 		// get product info
-		result = append(result, &pb.Order{
-			ProductPrice:  product.Price,
-			ProductName:   product.Name,
-			ProductImage:  product.Thumbnail,
-			OrderId:       order.ID,
-			ProductId:     order.ProductID,
-			OrderQuantity: order.Quantity,
-			CustomerId:    order.CustomerID,
-			SupplierId:    order.SupplierID,
-			AddressName:   addr.Name,
-			AddressPhone:  addr.Phone,
-			AddressDetail: addr.Detail,
-		})
+		result = append(result, toPbOrder(order, product, addr))
 	}
 
 	return &pb.GetWaitingOrderBySupplierResponse{
@@ -424,7 +804,7 @@ func (srv orderService) GetWaitingOrderByCustomer(ctx context.Context, req *pb.G
 	customerID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
 
 	log.Println("get list order")
-	listOrder, err := srv.orderRepo.GetWaitingOrderByCustomer(ctx, customerID)
+	listOrder, err := srv.orderStore.GetWaitingOrderByCustomer(ctx, customerID)
 	if err != nil {
 		return nil, err
 	}
@@ -458,21 +838,9 @@ func (srv orderService) GetWaitingOrderByCustomer(ctx context.Context, req *pb.G
 		if !ok {
 			continue
 		}
-		addr, _ := srv.orderRepo.GetAddressById(ctx, order.AddressID)
-
-		result = append(result, &pb.Order{
-			ProductPrice:  product.Price,
-			ProductName:   product.Name,
-			ProductImage:  product.Thumbnail,
-			OrderId:       order.ID,
-			ProductId:     order.ProductID,
-			OrderQuantity: order.Quantity,
-			CustomerId:    order.CustomerID,
-			SupplierId:    order.SupplierID,
-			AddressName:   addr.Name,
-			AddressPhone:  addr.Phone,
-			AddressDetail: addr.Detail,
-		})
+		addr, _ := srv.orderStore.GetAddressById(ctx, order.AddressID)
+
+		result = append(result, toPbOrder(order, product, addr))
 	}
 
 	return &pb.GetWaitingOrderByCustomerResponse{
@@ -496,7 +864,7 @@ func (srv orderService) GetHandledOrderByCustomer(ctx context.Context, req *pb.G
 	customerID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
 
 	log.Println("get list order")
-	listOrder, err := srv.orderRepo.GetHandledOrderByCustomer(ctx, customerID)
+	listOrder, err := srv.orderStore.GetHandledOrderByCustomer(ctx, customerID)
 	if err != nil {
 		return nil, err
 	}
@@ -532,20 +900,8 @@ func (srv orderService) GetHandledOrderByCustomer(ctx context.Context, req *pb.G
 			continue
 		}
 
-		addr, _ := srv.orderRepo.GetAddressById(ctx, order.AddressID)
-		result = append(result, &pb.Order{
-			ProductPrice:  product.Price,
-			ProductName:   product.Name,
-			ProductImage:  product.Thumbnail,
-			OrderId:       order.ID,
-			ProductId:     order.ProductID,
-			OrderQuantity: order.Quantity,
-			CustomerId:    order.CustomerID,
-			SupplierId:    order.SupplierID,
-			AddressName:   addr.Name,
-			AddressPhone:  addr.Phone,
-			AddressDetail: addr.Detail,
-		})
+		addr, _ := srv.orderStore.GetAddressById(ctx, order.AddressID)
+		result = append(result, toPbOrder(order, product, addr))
 	}
 
 	return &pb.GetHandledOrderByCustomerResponse{
@@ -570,7 +926,7 @@ func (srv orderService) GetHandledOrderBySupllier(ctx context.Context, _ *empty.
 	customerID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
 
 	log.Println("get list order")
-	listOrder, err := srv.orderRepo.GetHandledOrderBySupplier(ctx, customerID)
+	listOrder, err := srv.orderStore.GetHandledOrderBySupplier(ctx, customerID)
 	if err != nil {
 		return nil, err
 	}
@@ -606,20 +962,8 @@ func (srv orderService) GetHandledOrderBySupllier(ctx context.Context, _ *empty.
 			continue
 		}
 
-		addr, _ := srv.orderRepo.GetAddressById(ctx, order.AddressID)
-		result = append(result, &pb.Order{
-			ProductPrice:  product.Price,
-			ProductName:   product.Name,
-			ProductImage:  product.Thumbnail,
-			OrderId:       order.ID,
-			ProductId:     order.ProductID,
-			OrderQuantity: order.Quantity,
-			CustomerId:    order.CustomerID,
-			SupplierId:    order.SupplierID,
-			AddressName:   addr.Name,
-			AddressPhone:  addr.Phone,
-			AddressDetail: addr.Detail,
-		})
+		addr, _ := srv.orderStore.GetAddressById(ctx, order.AddressID)
+		result = append(result, toPbOrder(order, product, addr))
 	}
 
 	return &pb.GetHandledOrderBySupplierResponse{
@@ -646,7 +990,7 @@ func (srv orderService) GetCancelOrderByCustomer(ctx context.Context, _ *empty.E
 	}
 
 	customerID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
-	listOrder, err := srv.orderRepo.GetCancelOrderByCustomer(ctx, customerID)
+	listOrder, err := srv.orderStore.GetCancelOrderByCustomer(ctx, customerID)
 	if err != nil {
 		return nil, err
 	}
@@ -682,20 +1026,8 @@ func (srv orderService) GetCancelOrderByCustomer(ctx context.Context, _ *empty.E
 			continue
 		}
 
-		addr, _ := srv.orderRepo.GetAddressById(ctx, order.AddressID)
-		result = append(result, &pb.Order{
-			ProductPrice:  product.Price,
-			ProductName:   product.Name,
-			ProductImage:  product.Thumbnail,
-			OrderId:       order.ID,
-			ProductId:     order.ProductID,
-			OrderQuantity: order.Quantity,
-			CustomerId:    order.CustomerID,
-			SupplierId:    order.SupplierID,
-			AddressName:   addr.Name,
-			AddressPhone:  addr.Phone,
-			AddressDetail: addr.Detail,
-		})
+		addr, _ := srv.orderStore.GetAddressById(ctx, order.AddressID)
+		result = append(result, toPbOrder(order, product, addr))
 	}
 
 	return &pb.GetHandledOrderByCustomerResponse{
@@ -717,7 +1049,7 @@ func (srv orderService) GetCancelOrderBySupplier(ctx context.Context, _ *empty.E
 	}
 
 	supplierID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
-	listOrder, err := srv.orderRepo.GetCancelOrderBySupplier(ctx, supplierID)
+	listOrder, err := srv.orderStore.GetCancelOrderBySupplier(ctx, supplierID)
 	if err != nil {
 		return nil, err
 	}
@@ -752,24 +1084,30 @@ func (srv orderService) GetCancelOrderBySupplier(ctx context.Context, _ *empty.E
 		if !ok {
 			continue
 		}
-		addr, _ := srv.orderRepo.GetAddressById(ctx, order.AddressID)
-
-		result = append(result, &pb.Order{
-			ProductPrice:  product.Price,
-			ProductName:   product.Name,
-			ProductImage:  product.Thumbnail,
-			OrderId:       order.ID,
-			ProductId:     order.ProductID,
-			OrderQuantity: order.Quantity,
-			CustomerId:    order.CustomerID,
-			SupplierId:    order.SupplierID,
-			AddressName:   addr.Name,
-			AddressPhone:  addr.Phone,
-			AddressDetail: addr.Detail,
-		})
+		addr, _ := srv.orderStore.GetAddressById(ctx, order.AddressID)
+
+		result = append(result, toPbOrder(order, product, addr))
 	}
 
 	return &pb.GetHandledOrderBySupplierResponse{
 		ListOrder: result,
 	}, nil
 }
+
+// toPbOrderStatus reports an order without a known status as unknown_status,
+// it must not look like an order the supplier can still handle.
+func toPbOrderStatus(status repository.NullOrderStatusEnum) pb.OrderStatus {
+	pbStatus, err := orderstatus.NullToPb(status)
+	if err != nil {
+		log.Println(err)
+	}
+	return pbStatus
+}
+
+// toPbNullTime leaves the timestamp unset when the time is NULL.
+func toPbNullTime(t sql.NullTime) *timestamppb.Timestamp {
+	if !t.Valid {
+		return nil
+	}
+	return timestamppb.New(t.Time)
+}
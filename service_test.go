@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"google.golang.org/grpc/metadata"
+)
+
+// newTestService is the service on store with the fake services of --dev,
+// seeded with defaultDevSeed.
+func newTestService(t *testing.T, store OrderStore) orderService {
+	t.Helper()
+	conn, stop, err := startDevServices(defaultDevSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(stop)
+	return orderService{
+		authClient:    pb.NewAuthServiceClient(conn),
+		productClient: pb.NewProductServiceClient(conn),
+		cartClient:    pb.NewCartServiceClient(conn),
+		orderStore:    store,
+		carrier:       newFakeCarrier("ECOM"),
+		payment:       newFakePaymentProvider(0),
+	}
+}
+
+// userContext is the context of a request of the dev user with token.
+func userContext(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", token))
+}
+
+func inventoryOf(t *testing.T, srv orderService, productID int64) int64 {
+	t.Helper()
+	resp, err := srv.productClient.GetListProductInventory(context.Background(), &pb.GetInventoryRequest{ProductId: productID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.GetCount()
+}
+
+// hammer runs every call n times at once and returns how many succeeded.
+func hammer(n int, calls ...func() error) int {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		for _, call := range calls {
+			wg.Add(1)
+			go func(call func() error) {
+				defer wg.Done()
+				if call() == nil {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				}
+			}(call)
+		}
+	}
+	wg.Wait()
+	return succeeded
+}
+
+func TestDeleteOrderRace(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		srv := newTestService(t, store)
+		before := inventoryOf(t, srv, 1)
+
+		var cancelled int64
+		for i := 0; i < 10; i++ {
+			order := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.ProductID = 1 })
+			cancel := func(token string) func() error {
+				return func() error {
+					_, err := srv.DeleteOrder(userContext(token), &pb.DeleteOrderRequest{OrderId: order.ID})
+					return err
+				}
+			}
+			handle := func() error {
+				_, err := srv.HandleOrder(userContext("supplier"), &pb.HandleOrderRequest{OrderId: order.ID})
+				return err
+			}
+			adminCancel := func() error {
+				_, err := srv.AdminCancelOrder(userContext("admin"), &pb.AdminOrderActionRequest{OrderId: order.ID, Reason: "gian lận"})
+				return err
+			}
+
+			if n := hammer(5, cancel("customer"), handle, adminCancel); n != 1 && n != 2 {
+				t.Fatalf("order %d: %d calls succeeded", order.ID, n)
+			}
+			found, err := store.GetOrderByID(context.Background(), order.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hasStatus(found.Status, repository.OrderStatusEnumCancel) {
+				cancelled += int64(order.Quantity)
+			}
+			if found.RestockPending {
+				t.Errorf("order %d still owes its inventory", order.ID)
+			}
+		}
+
+		// every cancelled order gave its inventory back once
+		if got := inventoryOf(t, srv, 1) - before; got != cancelled {
+			t.Fatalf("inventory came back %d, want %d", got, cancelled)
+		}
+	})
+}
+
+func TestDeleteOrderByCustomer(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+		before := inventoryOf(t, srv, 1)
+
+		handled := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.ProductID = 1 })
+		if _, err := store.HandleOrder(ctx, handled.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.DeleteOrder(userContext("customer"), &pb.DeleteOrderRequest{OrderId: handled.ID}); err == nil {
+			t.Fatal("the customer cancelled a handled order")
+		}
+
+		held := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.ProductID = 1 })
+		if _, err := store.HoldOrder(ctx, repository.HoldOrderParams{ID: held.ID}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.DeleteOrder(userContext("customer"), &pb.DeleteOrderRequest{OrderId: held.ID}); err == nil {
+			t.Fatal("the customer cancelled a held order")
+		}
+
+		// the supplier can cancel a handled order until part of it is shipped
+		shipped := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.ProductID = 1 })
+		if _, err := store.HandleOrder(ctx, shipped.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.CreateShipment(userContext("supplier"), &pb.CreateShipmentRequest{
+			Carrier:  "ECOM",
+			ListItem: []*pb.ShipmentItem{{OrderId: shipped.ID, Quantity: 1}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.DeleteOrder(userContext("supplier"), &pb.DeleteOrderRequest{OrderId: shipped.ID}); err == nil {
+			t.Fatal("the supplier cancelled a partially shipped order")
+		}
+		if _, err := srv.AdminCancelOrder(userContext("admin"), &pb.AdminOrderActionRequest{OrderId: shipped.ID, Reason: "lỗi"}); err == nil {
+			t.Fatal("an admin cancelled a partially shipped order")
+		}
+		if _, err := srv.DeleteOrder(userContext("supplier"), &pb.DeleteOrderRequest{OrderId: handled.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := inventoryOf(t, srv, 1) - before; got != int64(handled.Quantity) {
+			t.Fatalf("inventory came back %d, want %d", got, handled.Quantity)
+		}
+	})
+}
+
+func TestToPbOrder(t *testing.T) {
+	order := repository.Order{
+		ID:          7,
+		OrderNumber: "ORD-20260101-00007",
+		ProductID:   10,
+		Quantity:    2,
+		Price:       100000,
+		ProductName: "Áo thun",
+		Status:      orderStatus(repository.OrderStatusEnumWaiting),
+		CreatedAt:   time.Now(),
+	}
+	product := &pb.Product{ProductId: 10, Name: "Áo thun mới", Price: 120000, Thumbnail: "ao.jpg"}
+	addr := repository.Address{Name: "An", Phone: "0912345678", Detail: "Hà Nội"}
+
+	got := toPbOrder(order, product, addr)
+	if got.ProductName != "Áo thun" || got.ProductPrice != 100000 || got.ProductImage != "ao.jpg" {
+		t.Errorf("the checkout snapshot is not kept: %v", got)
+	}
+	if got.OrderId != 7 || got.OrderNumber != order.OrderNumber || got.AddressPhone != "0912345678" || got.HandledAt != nil {
+		t.Errorf("toPbOrder = %v", got)
+	}
+
+	// an order older than the snapshots
+	order.ProductName, order.Price = "", 0
+	if got := toPbOrder(order, product, addr); got.ProductName != "Áo thun mới" || got.ProductPrice != 120000 {
+		t.Errorf("the product does not fill the snapshot: %v", got)
+	}
+	// a deleted product
+	if got := toPbOrder(order, nil, addr); got.ProductName != "" || got.ProductImage != "" || got.OrderId != 7 {
+		t.Errorf("toPbOrder without product = %v", got)
+	}
+}
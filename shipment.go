@@ -0,0 +1,411 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (srv orderService) CreateShipment(ctx context.Context, req *pb.CreateShipmentRequest) (*pb.Shipment, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	supplierID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	if req.GetCarrier() == "" {
+		return nil, errors.New("Vui lòng chọn đơn vị vận chuyển")
+	}
+	if len(req.GetListItem()) == 0 {
+		return nil, errors.New("Vui lòng chọn đơn hàng cần giao")
+	}
+
+	// every line must belong to the supplier and the quantities must not
+	// exceed what is left to ship, a line can be split across shipments
+	requested := make(map[int64]int32)
+	for _, item := range req.GetListItem() {
+		if item.GetQuantity() <= 0 {
+			return nil, errors.New("Số lượng giao không hợp lệ")
+		}
+		requested[item.GetOrderId()] += item.GetQuantity()
+	}
+
+	var shipment repository.Shipment
+	var listItem []repository.ShipmentItem
+	err = srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		// the orders stay locked until the shipment is saved, so they can't be
+		// cancelled or over-shipped by a concurrent request. They are locked in id
+		// order so two shipments of the same orders can't deadlock
+		listOrderID := make([]int64, 0, len(requested))
+		for orderID := range requested {
+			listOrderID = append(listOrderID, orderID)
+		}
+		sort.Slice(listOrderID, func(i, j int) bool { return listOrderID[i] < listOrderID[j] })
+		for _, orderID := range listOrderID {
+			quantity := requested[orderID]
+			order, err := qtx.GetOrderByIDForUpdate(ctx, orderID)
+			if err != nil {
+				log.Println(err)
+				return errors.New("Không tìm thấy đơn hàng")
+			}
+			if order.SupplierID != supplierID {
+				return errors.New("Tạo vận đơn không thành công, unauthorization")
+			}
+			if order.Status.OrderStatusEnum != repository.OrderStatusEnumHandled {
+				return errors.New("Chỉ có thể giao đơn hàng đã được xử lý")
+			}
+			if order.HeldAt.Valid {
+				return errors.New("Đơn hàng đang bị tạm giữ, không thể giao")
+			}
+			shipped, err := qtx.GetShipmentQuantityByOrder(ctx, orderID)
+			if err != nil {
+				log.Println(err)
+				return errors.New("Tạo vận đơn không thành công")
+			}
+			if shipped.Allocated+quantity > order.Quantity {
+				return errors.New("Số lượng giao vượt quá số lượng đặt hàng")
+			}
+		}
+
+		var err error
+		shipment, err = qtx.CreateShipment(ctx, repository.CreateShipmentParams{
+			SupplierID:     supplierID,
+			Carrier:        req.GetCarrier(),
+			TrackingNumber: req.GetTrackingNumber(),
+		})
+		if err != nil {
+			log.Println(err)
+			return errors.New("Tạo vận đơn không thành công")
+		}
+		listItem = make([]repository.ShipmentItem, 0, len(req.GetListItem()))
+		for _, item := range req.GetListItem() {
+			shipmentItem, err := qtx.CreateShipmentItem(ctx, repository.CreateShipmentItemParams{
+				ShipmentID: shipment.ID,
+				OrderID:    item.GetOrderId(),
+				Quantity:   item.GetQuantity(),
+			})
+			if err != nil {
+				log.Println(err)
+				return errors.New("Tạo vận đơn không thành công")
+			}
+			listItem = append(listItem, shipmentItem)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the parcel is booked with the carrier once the shipment is saved, the
+	// orders aren't kept locked while the carrier answers. When the booking
+	// fails the shipment stays without a tracking number, the supplier enters
+	// one with UpdateShipmentTracking
+	if shipment.TrackingNumber == "" && srv.carrier != nil {
+		booked, err := srv.bookShipment(ctx, shipment, listItem)
+		if err != nil {
+			log.Println("can't book shipment with the carrier: ", shipment.ID, err)
+		} else {
+			shipment = booked
+		}
+	}
+
+	return srv.toPbShipment(ctx, shipment, listItem)
+}
+
+func (srv orderService) UpdateShipmentTracking(ctx context.Context, req *pb.UpdateShipmentTrackingRequest) (*pb.Shipment, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	supplierID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	shipment, err := srv.orderStore.GetShipmentByID(ctx, req.GetShipmentId())
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không tìm thấy vận đơn")
+	}
+	if shipment.SupplierID != supplierID {
+		return nil, errors.New("Cập nhật vận đơn không thành công, unauthorization")
+	}
+
+	carrier, trackingNumber := shipment.Carrier, shipment.TrackingNumber
+	if req.GetCarrier() != "" {
+		carrier = req.GetCarrier()
+	}
+	if req.GetTrackingNumber() != "" {
+		trackingNumber = req.GetTrackingNumber()
+	}
+	if trackingNumber == "" {
+		return nil, errors.New("Vui lòng nhập mã vận đơn")
+	}
+
+	var listItem []repository.ShipmentItem
+	err = srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		shipment, err = qtx.ShipShipment(ctx, repository.ShipShipmentParams{
+			ID:             shipment.ID,
+			Carrier:        carrier,
+			TrackingNumber: trackingNumber,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("Vận đơn đã được giao, không thể cập nhật")
+		}
+		if err != nil {
+			log.Println(err)
+			return errors.New("Cập nhật vận đơn không thành công")
+		}
+		listItem, err = qtx.GetShipmentItemByShipment(ctx, shipment.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkShipmentNotHeld(ctx, qtx, listItem); err != nil {
+			return err
+		}
+		if err := syncOrderShippingStatus(ctx, qtx, listItem); err != nil {
+			log.Println(err)
+			return errors.New("Cập nhật vận đơn không thành công")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return srv.toPbShipment(ctx, shipment, listItem)
+}
+
+// bookShipment books a saved shipment with the carrier and keeps its
+// tracking number.
+func (srv orderService) bookShipment(ctx context.Context, shipment repository.Shipment, listItem []repository.ShipmentItem) (repository.Shipment, error) {
+	trackingNumber, err := srv.carrier.CreateShipment(ctx, shipment, listItem)
+	if err != nil {
+		return shipment, err
+	}
+	return srv.orderStore.SetShipmentTrackingNumber(ctx, repository.SetShipmentTrackingNumberParams{
+		ID:             shipment.ID,
+		TrackingNumber: trackingNumber,
+	})
+}
+
+// checkShipmentNotHeld refuses to send a shipment with goods of a held order.
+// The orders are locked in id order, so a hold waits for the shipment and
+// the other way round.
+func checkShipmentNotHeld(ctx context.Context, q repository.Querier, listItem []repository.ShipmentItem) error {
+	listOrderID := make([]int64, 0, len(listItem))
+	for _, item := range listItem {
+		listOrderID = append(listOrderID, item.OrderID)
+	}
+	sort.Slice(listOrderID, func(i, j int) bool { return listOrderID[i] < listOrderID[j] })
+	for _, orderID := range listOrderID {
+		order, err := q.GetOrderByIDForUpdate(ctx, orderID)
+		if err != nil {
+			log.Println(err)
+			return errors.New("Cập nhật vận đơn không thành công")
+		}
+		if order.HeldAt.Valid {
+			return errors.New("Đơn hàng đang bị tạm giữ, không thể giao")
+		}
+	}
+	return nil
+}
+
+func (srv orderService) MarkShipmentDelivered(ctx context.Context, req *pb.MarkShipmentDeliveredRequest) (*pb.Shipment, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	supplierID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	shipment, err := srv.orderStore.GetShipmentByID(ctx, req.GetShipmentId())
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không tìm thấy vận đơn")
+	}
+	if shipment.SupplierID != supplierID {
+		return nil, errors.New("Cập nhật vận đơn không thành công, unauthorization")
+	}
+
+	var listItem []repository.ShipmentItem
+	err = srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		shipment, err = qtx.DeliverShipment(ctx, shipment.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("Vận đơn chưa được gửi đi hoặc đã được giao")
+		}
+		if err != nil {
+			log.Println(err)
+			return errors.New("Cập nhật vận đơn không thành công")
+		}
+		listItem, err = qtx.GetShipmentItemByShipment(ctx, shipment.ID)
+		if err != nil {
+			return err
+		}
+		if err := syncOrderShippingStatus(ctx, qtx, listItem); err != nil {
+			log.Println(err)
+			return errors.New("Cập nhật vận đơn không thành công")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return srv.toPbShipment(ctx, shipment, listItem)
+}
+
+func (srv orderService) GetShipmentByOrder(ctx context.Context, req *pb.GetShipmentByOrderRequest) (*pb.GetShipmentByOrderResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	order, err := srv.orderStore.GetOrderByID(ctx, req.GetOrderId())
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Không tìm thấy đơn hàng")
+	}
+	if !(userID == order.CustomerID || userID == order.SupplierID || claims.GetUserRole() == pb.UserRole_admin) {
+		return nil, errors.New("Unauthorization")
+	}
+
+	listShipment, err := srv.orderStore.GetShipmentByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*pb.Shipment, 0, len(listShipment))
+	for _, shipment := range listShipment {
+		listItem, err := srv.orderStore.GetShipmentItemByShipment(ctx, shipment.ID)
+		if err != nil {
+			return nil, err
+		}
+		pbShipment, err := srv.toPbShipment(ctx, shipment, listItem)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, pbShipment)
+	}
+
+	return &pb.GetShipmentByOrderResponse{
+		ListShipment: result,
+	}, nil
+}
+
+// syncOrderShippingStatus moves the orders of a shipment to shipped or
+// delivered once their whole quantity has been shipped or delivered.
+func syncOrderShippingStatus(ctx context.Context, q repository.Querier, listItem []repository.ShipmentItem) error {
+	seen := make(map[int64]bool)
+	for _, item := range listItem {
+		if seen[item.OrderID] {
+			continue
+		}
+		seen[item.OrderID] = true
+
+		order, err := q.GetOrderByID(ctx, item.OrderID)
+		if err != nil {
+			return err
+		}
+		switch order.Status.OrderStatusEnum {
+		case repository.OrderStatusEnumHandled, repository.OrderStatusEnumShipped:
+		default:
+			continue
+		}
+
+		quantity, err := q.GetShipmentQuantityByOrder(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+		var status repository.OrderStatusEnum
+		switch {
+		case quantity.Delivered >= order.Quantity:
+			status = repository.OrderStatusEnumDelivered
+		case quantity.Shipped >= order.Quantity:
+			status = repository.OrderStatusEnumShipped
+		default:
+			continue
+		}
+		if status == order.Status.OrderStatusEnum {
+			continue
+		}
+
+		// an order cancelled or moved on in the meantime is left as it is
+		updated, err := q.UpdateOrderStatus(ctx, repository.UpdateOrderStatusParams{
+			NewStatus: repository.NullOrderStatusEnum{
+				OrderStatusEnum: status,
+				Valid:           true,
+			},
+			ID:        order.ID,
+			OldStatus: order.Status,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		// a delivered order is billed
+		if status == repository.OrderStatusEnumDelivered {
+			if err := issueInvoice(ctx, q, updated); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (srv orderService) toPbShipment(ctx context.Context, shipment repository.Shipment, listItem []repository.ShipmentItem) (*pb.Shipment, error) {
+	listOrderID := make([]int64, 0, len(listItem))
+	for _, item := range listItem {
+		listOrderID = append(listOrderID, item.OrderID)
+	}
+	orderNumbers, err := srv.orderNumbers(ctx, listOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &pb.Shipment{
+		ShipmentId:     shipment.ID,
+		SupplierId:     shipment.SupplierID,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		Status:         pb.ShipmentStatus(pb.ShipmentStatus_value["shipment_"+string(shipment.Status)]),
+		CreatedAt:      timestamppb.New(shipment.CreatedAt),
+	}
+	if shipment.ShippedAt.Valid {
+		result.ShippedAt = timestamppb.New(shipment.ShippedAt.Time)
+	}
+	if shipment.DeliveredAt.Valid {
+		result.DeliveredAt = timestamppb.New(shipment.DeliveredAt.Time)
+	}
+	for _, item := range listItem {
+		result.ListItem = append(result.ListItem, &pb.ShipmentItem{
+			OrderId:     item.OrderID,
+			Quantity:    item.Quantity,
+			OrderNumber: orderNumbers[item.OrderID],
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	staleOrderCancelReason = "supplier timeout"
+	staleOrderBatchSize    = 50
+)
+
+func (srv orderService) SetSupplierOrderSetting(ctx context.Context, req *pb.SupplierOrderSetting) (*pb.SupplierOrderSetting, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	// auth
+	claims, err := srv.authClient.GetUserClaims(ctx, _empty)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := strconv.ParseInt(claims.GetId(), 10, 64)
+
+	supplierID := req.GetSupplierId()
+	switch claims.GetUserRole() {
+	case pb.UserRole_admin:
+		if supplierID == 0 {
+			return nil, errors.New("Vui lòng chọn cửa hàng")
+		}
+	case pb.UserRole_supplier:
+		if supplierID != 0 && supplierID != userID {
+			return nil, errors.New("Cập nhật không thành công, unauthorization")
+		}
+		supplierID = userID
+	default:
+		return nil, errors.New("Cập nhật không thành công, unauthorization")
+	}
+
+	// a month is long enough for any supplier
+	if req.GetHandleDeadlineHours() <= 0 || req.GetHandleDeadlineHours() > 720 {
+		return nil, errors.New("Thời hạn xử lý đơn hàng không hợp lệ")
+	}
+
+	setting, err := srv.orderStore.UpsertSupplierOrderSetting(ctx, repository.UpsertSupplierOrderSettingParams{
+		SupplierID:          supplierID,
+		HandleDeadlineHours: req.GetHandleDeadlineHours(),
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, errors.New("Cập nhật không thành công")
+	}
+
+	return &pb.SupplierOrderSetting{
+		SupplierId:          setting.SupplierID,
+		HandleDeadlineHours: setting.HandleDeadlineHours,
+	}, nil
+}
+
+// runStaleOrderCanceller cancels the waiting orders that outlived the handle
+// deadline of their supplier every interval, until ctx is done. Every replica
+// can run it, the orders are locked with SKIP LOCKED so each one is only
+// cancelled once. The restocks of cancelled orders and the refunds of returns
+// that failed are retried at the same time.
+func (srv orderService) runStaleOrderCanceller(ctx context.Context, interval time.Duration, defaultDeadlineHours int32) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		var afterID int64
+		for {
+			n, nextID, err := srv.cancelStaleOrders(ctx, defaultDeadlineHours, afterID)
+			if err != nil {
+				log.Println("can't cancel stale orders: ", err)
+				break
+			}
+			if n > 0 {
+				log.Println("cancelled stale orders: ", n)
+			}
+			if nextID == 0 {
+				break
+			}
+			afterID = nextID
+		}
+
+		n, err := srv.restockPendingOrders(ctx)
+		if err != nil {
+			log.Println("can't restock cancelled orders: ", err)
+		} else if n > 0 {
+			log.Println("restocked cancelled orders: ", n)
+		}
+
+		n, err = srv.refundPendingReturns(ctx)
+		if err != nil {
+			log.Println("can't refund returns: ", err)
+		} else if n > 0 {
+			log.Println("refunded returns: ", n)
+		}
+	}
+}
+
+// cancelStaleOrders cancels up to a batch of the stale waiting orders after
+// afterID and returns how many were cancelled and the id to go on from, 0
+// once there are none left. Every order is cancelled in its own transaction
+// which gives its payment and coupon back, an order whose payment can't be
+// given back stays waiting and is tried again on the next run, without
+// holding back the orders after it. The inventory is given back once the
+// cancel is committed and retried by restockPendingOrders when it fails.
+func (srv orderService) cancelStaleOrders(ctx context.Context, defaultDeadlineHours int32, afterID int64) (int, int64, error) {
+	n := 0
+	for i := 0; i < staleOrderBatchSize; i++ {
+		id, err := srv.cancelStaleOrder(ctx, defaultDeadlineHours, afterID)
+		if id == 0 {
+			// none left, or the stale orders can't be read
+			return n, 0, err
+		}
+		afterID = id
+		if err != nil {
+			log.Println("can't cancel stale order, it is tried again later: ", id, err)
+			continue
+		}
+		n++
+	}
+	return n, afterID, nil
+}
+
+// cancelStaleOrder cancels the first stale waiting order after afterID and
+// returns its id, 0 when there is none.
+func (srv orderService) cancelStaleOrder(ctx context.Context, defaultDeadlineHours int32, afterID int64) (int64, error) {
+	var id int64
+	var cancelled repository.Order
+	err := srv.orderStore.ExecTx(ctx, nil, func(qtx repository.Querier) error {
+		listOrder, err := qtx.LockStaleWaitingOrder(ctx, repository.LockStaleWaitingOrderParams{
+			DefaultDeadlineHours: defaultDeadlineHours,
+			AfterID:              afterID,
+			BatchSize:            1,
+		})
+		if err != nil || len(listOrder) == 0 {
+			return err
+		}
+		id = listOrder[0].ID
+
+		cancelled, err = qtx.CancelWaitingOrder(ctx, repository.CancelWaitingOrderParams{
+			ID:           id,
+			CancelReason: staleOrderCancelReason,
+		})
+		if err != nil {
+			return err
+		}
+		if err := srv.givePaymentBack(ctx, qtx, cancelled); err != nil {
+			return err
+		}
+		return releaseOrderCoupon(ctx, qtx, cancelled)
+	})
+	if err != nil || id == 0 {
+		return id, err
+	}
+
+	srv.finishCancel(ctx, cancelled)
+	return id, nil
+}
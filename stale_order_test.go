@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/pb"
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+func TestCancelStaleOrdersRace(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+		before := inventoryOf(t, srv, 1)
+
+		var listOrder []repository.Order
+		for i := 0; i < 5; i++ {
+			order := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.ProductID = 1 })
+			backdateOrder(t, store, order.ID, 100*time.Hour)
+			listOrder = append(listOrder, order)
+		}
+
+		// the customer cancels the orders while the canceller runs twice
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		cancelled := 0
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				n, _, err := srv.cancelStaleOrders(ctx, 72, 0)
+				if err != nil {
+					t.Error(err)
+				}
+				mu.Lock()
+				cancelled += n
+				mu.Unlock()
+			}()
+		}
+		for _, order := range listOrder {
+			wg.Add(1)
+			go func(order repository.Order) {
+				defer wg.Done()
+				if _, err := srv.DeleteOrder(userContext("customer"), &pb.DeleteOrderRequest{OrderId: order.ID}); err == nil {
+					mu.Lock()
+					cancelled++
+					mu.Unlock()
+				}
+			}(order)
+		}
+		wg.Wait()
+
+		if cancelled != len(listOrder) {
+			t.Fatalf("%d orders cancelled, want %d", cancelled, len(listOrder))
+		}
+		if got, want := inventoryOf(t, srv, 1)-before, int64(len(listOrder))*2; got != want {
+			t.Fatalf("inventory came back %d, want %d", got, want)
+		}
+	})
+}
+
+// an order whose inventory can't be given back must not keep the canceller
+// from the orders after it
+func TestCancelStaleOrdersFailingRestock(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryOrderStore()
+	srv := newTestService(t, store)
+
+	// product-service doesn't know product 10
+	failing := testOrder(t, store, nil)
+	backdateOrder(t, store, failing.ID, 100*time.Hour)
+	var listOrder []repository.Order
+	for i := 0; i < staleOrderBatchSize; i++ {
+		order := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.ProductID = 1 })
+		backdateOrder(t, store, order.ID, 100*time.Hour)
+		listOrder = append(listOrder, order)
+	}
+	before := inventoryOf(t, srv, 1)
+
+	cancelled := 0
+	for i := 0; i < 3; i++ {
+		n, _, err := srv.cancelStaleOrders(ctx, 72, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cancelled += n
+	}
+	if cancelled != len(listOrder)+1 {
+		t.Fatalf("%d orders cancelled, want %d", cancelled, len(listOrder)+1)
+	}
+	if got, want := inventoryOf(t, srv, 1)-before, int64(len(listOrder))*2; got != want {
+		t.Fatalf("inventory came back %d, want %d", got, want)
+	}
+	found, err := store.GetOrderByID(ctx, failing.ID)
+	if err != nil || !found.RestockPending || found.RestockAttempts != 1 {
+		t.Fatalf("the order that can't be restocked is %+v, %v", found, err)
+	}
+}
+
+func TestCancelStaleOrdersPayment(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		srv := newTestService(t, store)
+
+		// the authorization of the first order is unknown to the provider, it
+		// can't be voided
+		failing := testOrder(t, store, func(arg *repository.CreateOrderParams) {
+			arg.ProductID = 1
+			arg.PaymentStatus = repository.PaymentStatusEnumAuthorized
+			arg.PaymentID = "unknown"
+		})
+		backdateOrder(t, store, failing.ID, 100*time.Hour)
+		order := paidOrder(t, srv, store)
+		backdateOrder(t, store, order.ID, 100*time.Hour)
+
+		n, nextID, err := srv.cancelStaleOrders(ctx, 72, 0)
+		if err != nil || n != 1 || nextID != 0 {
+			t.Fatalf("cancelStaleOrders = %d, %d, %v, want 1 order and none left", n, nextID, err)
+		}
+
+		found, err := store.GetOrderByID(ctx, failing.ID)
+		if err != nil || !hasStatus(found.Status, repository.OrderStatusEnumWaiting) || found.PaymentStatus != repository.PaymentStatusEnumAuthorized {
+			t.Errorf("the order whose payment can't be voided is %+v, %v, want it waiting", found, err)
+		}
+		found, err = store.GetOrderByID(ctx, order.ID)
+		if err != nil || !hasStatus(found.Status, repository.OrderStatusEnumCancel) || found.PaymentStatus != repository.PaymentStatusEnumUnpaid {
+			t.Errorf("the cancelled order is %+v, %v, want its payment voided", found, err)
+		}
+		if status := fakePaymentStatus(srv, order.PaymentID); status != repository.PaymentStatusEnumUnpaid {
+			t.Errorf("the authorization of the cancelled order is %s, want it voided", status)
+		}
+		coupon, err := store.GetCouponByCode(ctx, "GIAM10K")
+		if err != nil || coupon.UsedCount != 0 {
+			t.Errorf("the coupon is %+v, %v, want it released", coupon, err)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/e-commerce-microservices/order-service/repository"
+)
+
+// OrderStore is the storage of the service: the queries of db/query, see
+// repository.Querier, and the transactions they run in. postgresOrderStore
+// runs them on Postgres, memoryOrderStore keeps everything in memory for the
+// tests and --dev. There is no SQLite store: the queries rely on full text
+// search, a materialized view, advisory locks and SKIP LOCKED, which SQLite
+// has no equivalent for.
+//
+// The status changes only apply to an order in the expected status, they
+// return sql.ErrNoRows otherwise. HandleOrder and DeleteOrder leave an order
+// held by an admin alone.
+type OrderStore interface {
+	repository.Querier
+	// ExecTx runs fn in a transaction, committed when fn returns nil and
+	// rolled back otherwise. fn must only use the queries it is given.
+	ExecTx(ctx context.Context, opts *sql.TxOptions, fn func(q repository.Querier) error) error
+}
+
+// postgresOrderStore is the OrderStore of production, the queries generated
+// by sqlc on a Postgres database.
+type postgresOrderStore struct {
+	*repository.Queries
+	db *sql.DB
+}
+
+var _ OrderStore = (*postgresOrderStore)(nil)
+
+func newPostgresOrderStore(db *sql.DB) *postgresOrderStore {
+	return &postgresOrderStore{
+		Queries: repository.New(db),
+		db:      db,
+	}
+}
+
+func (s *postgresOrderStore) ExecTx(ctx context.Context, opts *sql.TxOptions, fn func(q repository.Querier) error) error {
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(s.Queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,820 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/e-commerce-microservices/order-service/repository"
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// the tables wiped before every test on Postgres, the sequences start again
+const testTruncateTables = `TRUNCATE "order", "address", "order_search", "order_status_history", "order_audit",
+	"coupon", "coupon_redemption", "customer_address", "checkout_outcome", "supplier_order_setting",
+	"supplier_invoice_setting", "invoice_sequence", "invoice", "invoice_line",
+	"return_request", "return_item", "refund", "shipment", "shipment_item"
+	RESTART IDENTITY CASCADE`
+
+// forEachStore runs test on an empty memory store, and on an empty Postgres
+// database when TEST_DATABASE_URL names one the tests may wipe. The memory
+// store runs its transactions one at a time, the Race tests only race on
+// Postgres, which the CI workflow provides.
+func forEachStore(t *testing.T, test func(t *testing.T, store OrderStore)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, newMemoryOrderStore())
+	})
+	t.Run("postgres", func(t *testing.T) {
+		dsn := os.Getenv("TEST_DATABASE_URL")
+		if dsn == "" {
+			t.Skip("TEST_DATABASE_URL is not set")
+		}
+		test(t, newTestPostgresStore(t, dsn))
+	})
+}
+
+func newTestPostgresStore(t *testing.T, dsn string) *postgresOrderStore {
+	t.Helper()
+	m, err := newMigrate(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatal(err)
+	}
+	m.Close()
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(testTruncateTables); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`SELECT refresh_dashboard_summary()`); err != nil {
+		t.Fatal(err)
+	}
+	return newPostgresOrderStore(db)
+}
+
+// backdateOrder moves the creation of an order back by d, like an order
+// placed d ago.
+func backdateOrder(t *testing.T, store OrderStore, id int64, d time.Duration) {
+	t.Helper()
+	switch s := store.(type) {
+	case *memoryOrderStore:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		order := s.data.orders[id]
+		order.CreatedAt = order.CreatedAt.Add(-d)
+		s.data.orders[id] = order
+	case *postgresOrderStore:
+		_, err := s.db.Exec(`UPDATE "order" SET "created_at" = "created_at" - make_interval(secs => $2) WHERE "id" = $1`, id, d.Seconds())
+		if err != nil {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatalf("can't backdate orders of %T", store)
+	}
+}
+
+// testOrder is an order of customer 1 to supplier 2 for 2 units of product
+// 10 at 100000, with its address.
+func testOrder(t *testing.T, store OrderStore, change func(*repository.CreateOrderParams)) repository.Order {
+	t.Helper()
+	ctx := context.Background()
+	address, err := store.CreateAddress(ctx, repository.CreateAddressParams{
+		Name:       "Nguyễn Văn An",
+		Phone:      "0912345678",
+		Detail:     "12 Lê Lợi, Quận 1",
+		CustomerID: sql.NullInt64{Int64: 1, Valid: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arg := repository.CreateOrderParams{
+		CustomerID:    1,
+		SupplierID:    2,
+		ProductID:     10,
+		Quantity:      2,
+		AddressID:     address.ID,
+		Price:         100000,
+		PaymentStatus: repository.PaymentStatusEnumUnpaid,
+		OrderNumber:   fmt.Sprintf("ORD-20260101-%05d", address.ID),
+		ProductName:   "Áo thun",
+	}
+	if change != nil {
+		change(&arg)
+	}
+	order, err := store.CreateOrder(ctx, arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func listStatus(listHistory []repository.OrderStatusHistory) []string {
+	var items []string
+	for _, history := range listHistory {
+		items = append(items, fmt.Sprintf("%s>%s", history.OldStatus.OrderStatusEnum, history.NewStatus.OrderStatusEnum))
+	}
+	return items
+}
+
+func TestStoreOrderLifecycle(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		order := testOrder(t, store, nil)
+		if !hasStatus(order.Status, repository.OrderStatusEnumWaiting) || order.HandledAt.Valid || order.CancelledAt.Valid {
+			t.Fatalf("new order: %+v", order)
+		}
+
+		found, err := store.GetOrderByNumber(ctx, order.OrderNumber)
+		if err != nil || found.ID != order.ID {
+			t.Fatalf("GetOrderByNumber: %+v, %v", found, err)
+		}
+
+		handled, err := store.HandleOrder(ctx, order.ID)
+		if err != nil || !hasStatus(handled.Status, repository.OrderStatusEnumHandled) || !handled.HandledAt.Valid {
+			t.Fatalf("HandleOrder: %+v, %v", handled, err)
+		}
+		if _, err := store.HandleOrder(ctx, order.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("HandleOrder twice: %v", err)
+		}
+		if _, err := store.CancelOrder(ctx, order.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("CancelOrder of a handled order: %v", err)
+		}
+
+		cancelled, err := store.DeleteOrder(ctx, order.ID)
+		if err != nil || !hasStatus(cancelled.Status, repository.OrderStatusEnumCancel) || !cancelled.CancelledAt.Valid {
+			t.Fatalf("DeleteOrder: %+v, %v", cancelled, err)
+		}
+		if _, err := store.DeleteOrder(ctx, order.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("DeleteOrder twice: %v", err)
+		}
+
+		listHistory, err := store.GetOrderStatusHistoryByOrder(ctx, order.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := fmt.Sprint(listStatus(listHistory))
+		if want := "[>waiting waiting>handled handled>cancel]"; got != want {
+			t.Fatalf("history = %s, want %s", got, want)
+		}
+	})
+}
+
+func TestStoreHeldOrder(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		order := testOrder(t, store, nil)
+		backdateOrder(t, store, order.ID, 48*time.Hour)
+
+		held, err := store.HoldOrder(ctx, repository.HoldOrderParams{ID: order.ID, HoldReason: "kiểm tra"})
+		if err != nil || !held.HeldAt.Valid || held.HoldReason != "kiểm tra" {
+			t.Fatalf("HoldOrder: %+v, %v", held, err)
+		}
+		if _, err := store.HoldOrder(ctx, repository.HoldOrderParams{ID: order.ID}); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("HoldOrder twice: %v", err)
+		}
+		if _, err := store.HandleOrder(ctx, order.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("HandleOrder of a held order: %v", err)
+		}
+		if _, err := store.DeleteOrder(ctx, order.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("DeleteOrder of a held order: %v", err)
+		}
+		listStale, err := store.LockStaleWaitingOrder(ctx, repository.LockStaleWaitingOrderParams{
+			DefaultDeadlineHours: 24,
+			BatchSize:            10,
+		})
+		if err != nil || len(listStale) != 0 {
+			t.Fatalf("LockStaleWaitingOrder of a held order: %v, %v", listStale, err)
+		}
+
+		released, err := store.ReleaseOrder(ctx, order.ID)
+		if err != nil || released.HeldAt.Valid || released.HoldReason != "" {
+			t.Fatalf("ReleaseOrder: %+v, %v", released, err)
+		}
+		if _, err := store.ReleaseOrder(ctx, order.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("ReleaseOrder twice: %v", err)
+		}
+
+		if _, err := store.HoldOrder(ctx, repository.HoldOrderParams{ID: order.ID}); err != nil {
+			t.Fatal(err)
+		}
+		cancelled, err := store.ForceCancelOrder(ctx, repository.ForceCancelOrderParams{ID: order.ID, CancelReason: "gian lận"})
+		if err != nil || cancelled.HeldAt.Valid || cancelled.CancelReason != "gian lận" || !cancelled.CancelledAt.Valid {
+			t.Fatalf("ForceCancelOrder: %+v, %v", cancelled, err)
+		}
+
+		shipped := testOrder(t, store, nil)
+		if _, err := store.HandleOrder(ctx, shipped.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.UpdateOrderStatus(ctx, repository.UpdateOrderStatusParams{
+			ID:        shipped.ID,
+			OldStatus: orderStatus(repository.OrderStatusEnumHandled),
+			NewStatus: orderStatus(repository.OrderStatusEnumShipped),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.HoldOrder(ctx, repository.HoldOrderParams{ID: shipped.ID}); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("HoldOrder of a shipped order: %v", err)
+		}
+	})
+}
+
+func TestStoreLockStaleWaitingOrder(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		stale := testOrder(t, store, nil)
+		backdateOrder(t, store, stale.ID, 25*time.Hour)
+		fresh := testOrder(t, store, nil)
+		backdateOrder(t, store, fresh.ID, time.Hour)
+		// supplier 3 gives itself 48 hours
+		patient := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.SupplierID = 3 })
+		backdateOrder(t, store, patient.ID, 25*time.Hour)
+		if _, err := store.UpsertSupplierOrderSetting(ctx, repository.UpsertSupplierOrderSettingParams{
+			SupplierID:          3,
+			HandleDeadlineHours: 48,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		listStale, err := store.LockStaleWaitingOrder(ctx, repository.LockStaleWaitingOrderParams{
+			DefaultDeadlineHours: 24,
+			BatchSize:            10,
+		})
+		if err != nil || len(listStale) != 1 || listStale[0].ID != stale.ID {
+			t.Fatalf("LockStaleWaitingOrder: %v, %v", listStale, err)
+		}
+
+		cancelled, err := store.CancelWaitingOrder(ctx, repository.CancelWaitingOrderParams{ID: stale.ID, CancelReason: "quá hạn"})
+		if err != nil || cancelled.CancelReason != "quá hạn" {
+			t.Fatalf("CancelWaitingOrder: %+v, %v", cancelled, err)
+		}
+		if _, err := store.CancelWaitingOrder(ctx, repository.CancelWaitingOrderParams{ID: stale.ID}); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("CancelWaitingOrder twice: %v", err)
+		}
+	})
+}
+
+func TestStoreExecTx(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		order := testOrder(t, store, nil)
+
+		errRollback := errors.New("rollback")
+		err := store.ExecTx(ctx, nil, func(q repository.Querier) error {
+			if _, err := q.HandleOrder(ctx, order.ID); err != nil {
+				return err
+			}
+			if _, err := q.NextInvoiceNumber(ctx, repository.NextInvoiceNumberParams{SupplierID: 2, Year: 2026}); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if err != errRollback {
+			t.Fatalf("ExecTx = %v, want the error of fn", err)
+		}
+		found, err := store.GetOrderByID(ctx, order.ID)
+		if err != nil || !hasStatus(found.Status, repository.OrderStatusEnumWaiting) {
+			t.Fatalf("the rolled back order is %+v, %v", found, err)
+		}
+
+		err = store.ExecTx(ctx, nil, func(q repository.Querier) error {
+			if _, err := q.HandleOrder(ctx, order.ID); err != nil {
+				return err
+			}
+			number, err := q.NextInvoiceNumber(ctx, repository.NextInvoiceNumberParams{SupplierID: 2, Year: 2026})
+			if number != 1 {
+				t.Errorf("the invoice number after a rollback is %d, want 1", number)
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		found, err = store.GetOrderByID(ctx, order.ID)
+		if err != nil || !hasStatus(found.Status, repository.OrderStatusEnumHandled) {
+			t.Fatalf("the committed order is %+v, %v", found, err)
+		}
+	})
+}
+
+func TestStoreCoupon(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		coupon, err := store.CreateCoupon(ctx, repository.CreateCouponParams{
+			Code:       "SALE10",
+			Type:       repository.CouponTypeEnumPercentage,
+			Value:      10,
+			UsageLimit: 1,
+			StartsAt:   time.Now().Add(-time.Hour),
+			CreatedBy:  3,
+		})
+		if err != nil || !coupon.Active {
+			t.Fatalf("CreateCoupon: %+v, %v", coupon, err)
+		}
+		if _, err := store.CreateCoupon(ctx, repository.CreateCouponParams{Code: "SALE10", Type: repository.CouponTypeEnumFixed, Value: 1}); err == nil {
+			t.Fatal("a coupon code is taken twice")
+		}
+
+		if _, err := store.RedeemCoupon(ctx, coupon.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.RedeemCoupon(ctx, coupon.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("RedeemCoupon past its limit: %v", err)
+		}
+		redemption, err := store.CreateCouponRedemption(ctx, repository.CreateCouponRedemptionParams{
+			CouponID:   coupon.ID,
+			CustomerID: 1,
+			Discount:   20000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		order := testOrder(t, store, func(arg *repository.CreateOrderParams) {
+			arg.CouponRedemptionID = sql.NullInt64{Int64: redemption.ID, Valid: true}
+		})
+		n, err := store.CountActiveOrderByCouponRedemption(ctx, order.CouponRedemptionID)
+		if err != nil || n != 1 {
+			t.Fatalf("CountActiveOrderByCouponRedemption = %d, %v", n, err)
+		}
+		if _, err := store.CancelOrder(ctx, order.ID); err != nil {
+			t.Fatal(err)
+		}
+		n, err = store.CountActiveOrderByCouponRedemption(ctx, order.CouponRedemptionID)
+		if err != nil || n != 0 {
+			t.Fatalf("CountActiveOrderByCouponRedemption of a cancelled order = %d, %v", n, err)
+		}
+
+		if _, err := store.ReleaseCouponRedemption(ctx, redemption.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.ReleaseCouponRedemption(ctx, redemption.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("ReleaseCouponRedemption twice: %v", err)
+		}
+		if err := store.UnredeemCoupon(ctx, coupon.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.RedeemCoupon(ctx, coupon.ID); err != nil {
+			t.Fatalf("RedeemCoupon after a release: %v", err)
+		}
+	})
+}
+
+func TestStoreCustomerAddress(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		first, err := store.CreateCustomerAddress(ctx, repository.CreateCustomerAddressParams{
+			CustomerID: 1, Name: "An", Phone: "0912345678", Detail: "Hà Nội", IsDefault: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := store.CreateCustomerAddress(ctx, repository.CreateCustomerAddressParams{
+			CustomerID: 1, Name: "An", Phone: "0912345678", Detail: "Huế",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.CreateCustomerAddress(ctx, repository.CreateCustomerAddressParams{
+			CustomerID: 1, Name: "An", Phone: "0912345678", Detail: "Đà Nẵng", IsDefault: true,
+		}); err == nil {
+			t.Fatal("a customer has two default addresses")
+		}
+
+		listAddress, err := store.GetCustomerAddressByCustomer(ctx, 1)
+		if err != nil || len(listAddress) != 2 || listAddress[0].ID != first.ID || listAddress[1].ID != second.ID {
+			t.Fatalf("GetCustomerAddressByCustomer: %+v, %v", listAddress, err)
+		}
+
+		if _, err := store.UpdateCustomerAddress(ctx, repository.UpdateCustomerAddressParams{
+			ID: second.ID, CustomerID: 2, Name: "An", Phone: "0912345678", Detail: "Huế",
+		}); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("UpdateCustomerAddress of another customer: %v", err)
+		}
+		if _, err := store.DeleteCustomerAddress(ctx, repository.DeleteCustomerAddressParams{ID: first.ID, CustomerID: 2}); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("DeleteCustomerAddress of another customer: %v", err)
+		}
+	})
+}
+
+func TestStoreShipmentQuantity(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		order := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.Quantity = 5 })
+
+		var listShipment []repository.Shipment
+		for _, quantity := range []int32{1, 2, 2} {
+			shipment, err := store.CreateShipment(ctx, repository.CreateShipmentParams{SupplierID: 2, Carrier: "ECOM"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.CreateShipmentItem(ctx, repository.CreateShipmentItemParams{
+				ShipmentID: shipment.ID, OrderID: order.ID, Quantity: quantity,
+			}); err != nil {
+				t.Fatal(err)
+			}
+			listShipment = append(listShipment, shipment)
+		}
+
+		for _, shipment := range listShipment[:2] {
+			shipped, err := store.ShipShipment(ctx, repository.ShipShipmentParams{ID: shipment.ID, Carrier: "ECOM", TrackingNumber: "T1"})
+			if err != nil || !shipped.ShippedAt.Valid {
+				t.Fatalf("ShipShipment: %+v, %v", shipped, err)
+			}
+		}
+		if _, err := store.DeliverShipment(ctx, listShipment[0].ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.DeliverShipment(ctx, listShipment[2].ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("DeliverShipment of a pending shipment: %v", err)
+		}
+		if _, err := store.ShipShipment(ctx, repository.ShipShipmentParams{ID: listShipment[0].ID}); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("ShipShipment of a delivered shipment: %v", err)
+		}
+
+		quantity, err := store.GetShipmentQuantityByOrder(ctx, order.ID)
+		want := repository.GetShipmentQuantityByOrderRow{Allocated: 5, Shipped: 3, Delivered: 1}
+		if err != nil || quantity != want {
+			t.Fatalf("GetShipmentQuantityByOrder = %+v, %v, want %+v", quantity, err, want)
+		}
+		listByOrder, err := store.GetShipmentByOrder(ctx, order.ID)
+		if err != nil || len(listByOrder) != 3 {
+			t.Fatalf("GetShipmentByOrder: %v, %v", listByOrder, err)
+		}
+	})
+}
+
+func TestStoreReturnedQuantity(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		order := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.Quantity = 5 })
+
+		var listReturn []repository.ReturnRequest
+		for _, quantity := range []int32{1, 2} {
+			orderReturn, err := store.CreateReturnRequest(ctx, repository.CreateReturnRequestParams{CustomerID: 1, SupplierID: 2, Reason: "lỗi"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.CreateReturnItem(ctx, repository.CreateReturnItemParams{
+				ReturnID: orderReturn.ID, OrderID: order.ID, Quantity: quantity,
+			}); err != nil {
+				t.Fatal(err)
+			}
+			listReturn = append(listReturn, orderReturn)
+		}
+		if _, err := store.UpdateReturnRequestStatus(ctx, repository.UpdateReturnRequestStatusParams{
+			ID: listReturn[1].ID, OldStatus: repository.ReturnStatusEnumRequested, NewStatus: repository.ReturnStatusEnumRejected,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.UpdateReturnRequestStatus(ctx, repository.UpdateReturnRequestStatusParams{
+			ID: listReturn[1].ID, OldStatus: repository.ReturnStatusEnumRequested, NewStatus: repository.ReturnStatusEnumApproved,
+		}); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("UpdateReturnRequestStatus from the wrong status: %v", err)
+		}
+
+		returned, err := store.GetReturnedQuantityByOrder(ctx, order.ID)
+		if err != nil || returned != 1 {
+			t.Fatalf("GetReturnedQuantityByOrder = %d, %v, want 1", returned, err)
+		}
+
+		if _, err := store.CreateRefund(ctx, repository.CreateRefundParams{ReturnID: listReturn[0].ID, OrderID: order.ID, Amount: 100000, Status: repository.RefundStatusEnumRefunded}); err != nil {
+			t.Fatal(err)
+		}
+		refunded, err := store.GetRefundedAmountByOrder(ctx, order.ID)
+		if err != nil || refunded != 100000 {
+			t.Fatalf("GetRefundedAmountByOrder = %d, %v", refunded, err)
+		}
+	})
+}
+
+func TestStoreInvoice(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		order := testOrder(t, store, nil)
+
+		for _, want := range []int32{1, 2} {
+			number, err := store.NextInvoiceNumber(ctx, repository.NextInvoiceNumberParams{SupplierID: 2, Year: 2026})
+			if err != nil || number != want {
+				t.Fatalf("NextInvoiceNumber = %d, %v, want %d", number, err, want)
+			}
+		}
+		number, err := store.NextInvoiceNumber(ctx, repository.NextInvoiceNumberParams{SupplierID: 2, Year: 2027})
+		if err != nil || number != 1 {
+			t.Fatalf("NextInvoiceNumber of a new year = %d, %v, want 1", number, err)
+		}
+
+		arg := repository.CreateInvoiceParams{
+			OrderID: order.ID, SupplierID: 2, CustomerID: 1, Year: 2026, Number: 1,
+			IssuedAt: time.Now(), Subtotal: 181818, Tax: 18182, Total: 200000,
+		}
+		invoice, err := store.CreateInvoice(ctx, arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.CreateInvoice(ctx, arg); err == nil {
+			t.Fatal("an order has two invoices")
+		}
+		if _, err := store.CreateInvoiceLine(ctx, repository.CreateInvoiceLineParams{
+			InvoiceID: invoice.ID, Description: "Áo thun", Quantity: 2, UnitPrice: 100000, Amount: 200000, VatPercent: 10, Tax: 18182,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		found, err := store.GetInvoiceByOrder(ctx, order.ID)
+		if err != nil || found.ID != invoice.ID {
+			t.Fatalf("GetInvoiceByOrder: %+v, %v", found, err)
+		}
+		listLine, err := store.GetInvoiceLineByInvoice(ctx, invoice.ID)
+		if err != nil || len(listLine) != 1 {
+			t.Fatalf("GetInvoiceLineByInvoice: %+v, %v", listLine, err)
+		}
+	})
+}
+
+func TestStoreSearchOrders(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		order := testOrder(t, store, nil)
+		other := testOrder(t, store, func(arg *repository.CreateOrderParams) {
+			arg.SupplierID = 3
+			arg.ProductName = "Quần jean"
+		})
+
+		for _, tc := range []struct {
+			query string
+			want  []int64
+		}{
+			{"nguyen an", []int64{other.ID, order.ID}},
+			{"NGUYỄN 0912", []int64{other.ID, order.ID}},
+			{"quan jean", []int64{other.ID}},
+			{"lê lợi áo", []int64{order.ID}},
+			{"hà nội", nil},
+		} {
+			listOrder, err := store.SearchOrders(ctx, repository.SearchOrdersParams{
+				Query:      sql.NullString{String: searchQuery(tc.query), Valid: true},
+				ListStatus: []string{},
+				RowLimit:   10,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int64
+			for _, order := range listOrder {
+				got = append(got, order.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("search %q = %v, want %v", tc.query, got, tc.want)
+			}
+		}
+
+		listOrder, err := store.SearchOrders(ctx, repository.SearchOrdersParams{
+			SupplierID: sql.NullInt64{Int64: 2, Valid: true},
+			ListStatus: []string{string(repository.OrderStatusEnumWaiting)},
+			RowLimit:   10,
+		})
+		if err != nil || len(listOrder) != 1 || listOrder[0].ID != order.ID {
+			t.Fatalf("search of supplier 2: %v, %v", listOrder, err)
+		}
+	})
+}
+
+// statusOrders creates an order of supplier 2 for every status, and one
+// without status, the way a NULL left by an old row reads. The orders of
+// supplier 3 are there to be filtered out.
+func statusOrders(t *testing.T, store OrderStore) map[string]int64 {
+	t.Helper()
+	ctx := context.Background()
+	m := make(map[string]int64)
+	for _, status := range []string{"waiting", "handled", "shipped", "delivered", "cancel", "null"} {
+		order := testOrder(t, store, func(arg *repository.CreateOrderParams) { arg.ProductID = 20 })
+		if status != "waiting" {
+			newStatus := repository.NullOrderStatusEnum{OrderStatusEnum: repository.OrderStatusEnum(status), Valid: status != "null"}
+			if _, err := store.UpdateOrderStatus(ctx, repository.UpdateOrderStatusParams{
+				ID:        order.ID,
+				OldStatus: order.Status,
+				NewStatus: newStatus,
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		m[status] = order.ID
+		testOrder(t, store, func(arg *repository.CreateOrderParams) {
+			arg.CustomerID = 4
+			arg.SupplierID = 3
+		})
+	}
+	return m
+}
+
+func orderIDs(listOrder []repository.Order) []int64 {
+	var items []int64
+	for _, order := range listOrder {
+		items = append(items, order.ID)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i] < items[j] })
+	return items
+}
+
+func TestStoreStatusFilters(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		m := statusOrders(t, store)
+		ids := func(list ...string) []int64 {
+			var items []int64
+			for _, status := range list {
+				items = append(items, m[status])
+			}
+			sort.Slice(items, func(i, j int) bool { return items[i] < items[j] })
+			return items
+		}
+
+		for _, tc := range []struct {
+			name string
+			list func(context.Context, int64) ([]repository.Order, error)
+			id   int64
+			want []int64
+		}{
+			{"GetWaitingOrderByCustomer", store.GetWaitingOrderByCustomer, 1, ids("waiting")},
+			{"GetWaitingOrderBySupplier", store.GetWaitingOrderBySupplier, 2, ids("waiting")},
+			{"GetHandledOrderByCustomer", store.GetHandledOrderByCustomer, 1, ids("handled", "shipped", "delivered")},
+			{"GetHandledOrderBySupplier", store.GetHandledOrderBySupplier, 2, ids("handled", "shipped", "delivered")},
+			{"GetCancelOrderByCustomer", store.GetCancelOrderByCustomer, 1, ids("cancel")},
+			{"GetCancelOrderBySupplier", store.GetCancelOrderBySupplier, 2, ids("cancel")},
+		} {
+			listOrder, err := tc.list(ctx, tc.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := orderIDs(listOrder); fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("%s = %v, want %v", tc.name, got, tc.want)
+			}
+		}
+
+		count, err := store.CountOrderByProductId(ctx, 20)
+		if err != nil || count != 6 {
+			t.Errorf("CountOrderByProductId = %d, %v, want 6", count, err)
+		}
+		listQuantity, err := store.CountOrderHandledByProductId(ctx, 20)
+		if err != nil || len(listQuantity) != 3 {
+			t.Errorf("CountOrderHandledByProductId = %v, %v, want 3 orders", listQuantity, err)
+		}
+		handled, err := store.CheckOrderIsHandled(ctx, repository.CheckOrderIsHandledParams{ProductID: 20, CustomerID: 1})
+		if err != nil || handled != 3 {
+			t.Errorf("CheckOrderIsHandled = %d, %v, want 3", handled, err)
+		}
+
+		// NULL = NULL is not true, the order without status can't be moved
+		if _, err := store.UpdateOrderStatus(ctx, repository.UpdateOrderStatusParams{
+			ID:        m["null"],
+			NewStatus: orderStatus(repository.OrderStatusEnumWaiting),
+		}); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("UpdateOrderStatus from NULL: %v", err)
+		}
+		for _, status := range []string{"null", "shipped", "delivered", "cancel"} {
+			if _, err := store.HandleOrder(ctx, m[status]); !errors.Is(err, sql.ErrNoRows) {
+				t.Errorf("HandleOrder of a %s order: %v", status, err)
+			}
+			if _, err := store.CancelOrder(ctx, m[status]); !errors.Is(err, sql.ErrNoRows) {
+				t.Errorf("CancelOrder of a %s order: %v", status, err)
+			}
+			if _, err := store.DeleteOrder(ctx, m[status]); !errors.Is(err, sql.ErrNoRows) {
+				t.Errorf("DeleteOrder of a %s order: %v", status, err)
+			}
+		}
+
+		for _, tc := range []struct {
+			listStatus []string
+			want       []int64
+		}{
+			{[]string{}, ids("waiting", "handled", "shipped", "delivered", "cancel", "null")},
+			{[]string{"waiting"}, ids("waiting")},
+			{[]string{"shipped", "delivered"}, ids("shipped", "delivered")},
+			{[]string{"cancel", "unknown"}, ids("cancel")},
+		} {
+			listOrder, err := store.SearchOrders(ctx, repository.SearchOrdersParams{
+				SupplierID: sql.NullInt64{Int64: 2, Valid: true},
+				ListStatus: tc.listStatus,
+				RowLimit:   20,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := orderIDs(listOrder); fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("search of status %v = %v, want %v", tc.listStatus, got, tc.want)
+			}
+		}
+
+		listOrder, err := store.SearchOrders(ctx, repository.SearchOrdersParams{
+			CustomerID: sql.NullInt64{Int64: 4, Valid: true},
+			ListStatus: []string{},
+			RowLimit:   4,
+			RowOffset:  4,
+		})
+		if err != nil || len(listOrder) != 2 {
+			t.Fatalf("search of the last page: %v, %v", listOrder, err)
+		}
+		for i := 1; i < len(listOrder); i++ {
+			if listOrder[i].ID > listOrder[i-1].ID {
+				t.Errorf("search is not from the newest order: %v", orderIDs(listOrder))
+			}
+		}
+
+		now := time.Now()
+		listOrder, err = store.SearchOrders(ctx, repository.SearchOrdersParams{
+			ListStatus:  []string{},
+			CreatedFrom: sql.NullTime{Time: now.Add(time.Hour), Valid: true},
+			RowLimit:    20,
+		})
+		if err != nil || len(listOrder) != 0 {
+			t.Errorf("search of the future: %v, %v", listOrder, err)
+		}
+		listOrder, err = store.SearchOrders(ctx, repository.SearchOrdersParams{
+			OrderNumber: sql.NullString{String: fmt.Sprintf("ORD-20260101-%05d", 1), Valid: true},
+			ListStatus:  []string{},
+			RowLimit:    20,
+		})
+		if err != nil || len(listOrder) != 1 {
+			t.Errorf("search of an order number: %v, %v", listOrder, err)
+		}
+	})
+}
+
+func TestStoreRestock(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store OrderStore) {
+		ctx := context.Background()
+		waiting := testOrder(t, store, nil)
+		if _, err := store.ClaimOrderRestock(ctx, repository.ClaimOrderRestockParams{ID: waiting.ID, RetryAfterSeconds: 60, MaxAttempts: 3}); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("ClaimOrderRestock of a waiting order: %v", err)
+		}
+
+		order := testOrder(t, store, nil)
+		cancelled, err := store.CancelOrder(ctx, order.ID)
+		if err != nil || !cancelled.RestockPending {
+			t.Fatalf("CancelOrder: %+v, %v", cancelled, err)
+		}
+		claimed, err := store.ClaimOrderRestock(ctx, repository.ClaimOrderRestockParams{ID: order.ID, RetryAfterSeconds: 60, MaxAttempts: 3})
+		if err != nil || !claimed.RestockRetryAt.Valid {
+			t.Fatalf("ClaimOrderRestock: %+v, %v", claimed, err)
+		}
+		if _, err := store.ClaimOrderRestock(ctx, repository.ClaimOrderRestockParams{ID: order.ID, RetryAfterSeconds: 60, MaxAttempts: 3}); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("ClaimOrderRestock of a claimed order: %v", err)
+		}
+		listDue, err := store.ClaimDueOrderRestock(ctx, repository.ClaimDueOrderRestockParams{RetryAfterSeconds: 60, MaxAttempts: 3, BatchSize: 10})
+		if err != nil || len(listDue) != 0 {
+			t.Fatalf("ClaimDueOrderRestock of a claimed order: %v, %v", listDue, err)
+		}
+
+		if err := store.FinishOrderRestock(ctx, order.ID); err != nil {
+			t.Fatal(err)
+		}
+		found, err := store.GetOrderByID(ctx, order.ID)
+		if err != nil || found.RestockPending || found.RestockRetryAt.Valid {
+			t.Fatalf("the restocked order is %+v, %v", found, err)
+		}
+
+		// a restock put back is claimed again at its retry time
+		if err := store.RetryOrderRestock(ctx, repository.RetryOrderRestockParams{
+			ID:             order.ID,
+			RestockRetryAt: sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		claimed, err = store.ClaimOrderRestock(ctx, repository.ClaimOrderRestockParams{ID: order.ID, RetryAfterSeconds: 60, MaxAttempts: 3})
+		if err != nil || claimed.RestockAttempts != 2 {
+			t.Fatalf("ClaimOrderRestock of a restock put back: %+v, %v", claimed, err)
+		}
+		if err := store.FinishOrderRestock(ctx, order.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, cancel := range []func(int64) (repository.Order, error){
+			func(id int64) (repository.Order, error) { return store.DeleteOrder(ctx, id) },
+			func(id int64) (repository.Order, error) {
+				return store.ForceCancelOrder(ctx, repository.ForceCancelOrderParams{ID: id, CancelReason: "lỗi"})
+			},
+			func(id int64) (repository.Order, error) {
+				return store.CancelWaitingOrder(ctx, repository.CancelWaitingOrderParams{ID: id, CancelReason: "quá hạn"})
+			},
+		} {
+			order := testOrder(t, store, nil)
+			cancelled, err := cancel(order.ID)
+			if err != nil || !cancelled.RestockPending {
+				t.Fatalf("cancel: %+v, %v", cancelled, err)
+			}
+		}
+		listDue, err = store.ClaimDueOrderRestock(ctx, repository.ClaimDueOrderRestockParams{RetryAfterSeconds: 60, MaxAttempts: 3, BatchSize: 2})
+		if err != nil || len(listDue) != 2 {
+			t.Fatalf("ClaimDueOrderRestock: %v, %v", listDue, err)
+		}
+	})
+}